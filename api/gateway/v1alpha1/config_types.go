@@ -4,8 +4,12 @@ import (
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
 	"github.com/fluxcd/pkg/apis/meta"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // GatewayServiceConfigSpec defines the desired state of GatewayServiceConfig
@@ -21,6 +25,85 @@ type GatewayServiceConfigSpec struct {
 
 	// DNS configuration.
 	DNS DNSConfig `json:"dns"`
+
+	// CommonMetadata is added to all resources managed by the gateway controller.
+	// +optional
+	CommonMetadata *CommonMetadata `json:"commonMetadata,omitempty"`
+
+	// GatewayNamespace is the namespace on the target cluster in which the Gateway and EnvoyProxy resources are created.
+	// Changing the namespace of an existing installation leaves the resources in the previous namespace behind.
+	// Default: openmcp-system
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+
+	// DeploymentNamespace is the namespace on the target cluster into which Envoy Gateway is deployed.
+	// Changing the namespace of an existing installation leaves the resources in the previous namespace behind.
+	// Default: envoy-gateway-system
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	DeploymentNamespace string `json:"deploymentNamespace,omitempty"`
+
+	// CreateNamespaces controls whether the gateway and deployment namespaces are created on the target cluster.
+	// If false, the namespaces have to be provided by other means, e.g. if namespace creation is managed centrally.
+	// The controller waits until they exist. Default: true
+	// +optional
+	CreateNamespaces *bool `json:"createNamespaces,omitempty"`
+
+	// Cleanup configures how the gateway is removed from a cluster.
+	// +optional
+	Cleanup *CleanupConfig `json:"cleanup,omitempty"`
+
+	// ClusterAccess configures how access to the clusters is obtained.
+	// +optional
+	ClusterAccess *ClusterAccessConfig `json:"clusterAccess,omitempty"`
+
+	// AllowPlatformCluster allows installing the gateway into the platform cluster on which the platform service itself is running.
+	// A cluster is considered to be the platform cluster if one of its API server endpoints equals the one of the platform cluster.
+	// Default: false, the platform cluster is refused even if it matches the selected clusters.
+	// +optional
+	AllowPlatformCluster bool `json:"allowPlatformCluster,omitempty"`
+}
+
+type ClusterAccessConfig struct {
+	// Timeout is the maximum time to wait for access to a cluster.
+	// Once exceeded, the cluster is only retried with the next periodic reconciliation.
+	// If unset, the controller waits indefinitely.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type CleanupConfig struct {
+	// DrainTimeout is the time given to the Envoy proxies to drain in-flight connections before the Gateway and its listeners
+	// are deleted and Envoy Gateway is uninstalled. It is independent of proxy.shutdown.drainTimeout, which Envoy applies on shutdown.
+	// If unset, the gateway is deleted immediately.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+
+	// PropagationPolicy is the propagation policy used to delete the resources of the gateway.
+	// Foreground only removes a resource once all of its dependents are gone.
+	// Default: the default propagation policy of the respective resource, usually Background.
+	// +kubebuilder:validation:Enum=Foreground;Background
+	// +optional
+	PropagationPolicy *metav1.DeletionPropagation `json:"propagationPolicy,omitempty"`
+
+	// RetryInterval is the interval in which resources which are still pending deletion are checked again.
+	// Default: 10s
+	// +optional
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+}
+
+type CommonMetadata struct {
+	// Labels to be added to all managed resources.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to be added to all managed resources.
+	// Annotations managed by the controller itself take precedence.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type ClusterTerm struct {
@@ -29,6 +112,14 @@ type ClusterTerm struct {
 
 	// ClusterRef can be used to reference a single cluster.
 	ClusterRef *ClusterRef `json:"clusterRef,omitempty"`
+
+	// ClusterRefs can be used to reference multiple clusters.
+	// The term matches if any of the referenced clusters matches.
+	ClusterRefs []ClusterRef `json:"clusterRefs,omitempty"`
+
+	// ExcludeSelector excludes clusters using labels and purpose.
+	// Clusters matching the ExcludeSelector of any term are never selected, even if they match another term.
+	ExcludeSelector *ClusterSelector `json:"excludeSelector,omitempty"`
 }
 
 type ClusterSelector struct {
@@ -62,26 +153,480 @@ type EnvoyGatewayConfig struct {
 	// +kubebuilder:validation:Enum=IPv4;IPv6;DualStack
 	// +optional
 	IPFamily *egv1a1.IPFamily `json:"ipFamily,omitempty"`
+
+	// DeploymentNamespaceLabels are labels that are added to the namespace
+	// Envoy Gateway is deployed into, e.g. to allow discovery by the Prometheus operator.
+	// +optional
+	DeploymentNamespaceLabels map[string]string `json:"deploymentNamespaceLabels,omitempty"`
+
+	// Proxy configures the Envoy Proxy deployment.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// Monitoring configures the monitoring of Envoy Gateway and Envoy Proxy.
+	// +optional
+	Monitoring *MonitoringConfig `json:"monitoring,omitempty"`
+
+	// RateLimit enables global rate limiting for the traffic of the gateway.
+	// Requires the rate limit image to be configured.
+	// +optional
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+	// LogLevel of the Envoy Gateway controller.
+	// Accepted values are "debug", "info", "warn" and "error".
+	// If unset, the default of the chart applies.
+	// +kubebuilder:validation:Enum=debug;info;warn;error
+	// +optional
+	LogLevel egv1a1.LogLevel `json:"logLevel,omitempty"`
+
+	// ControllerName is the name of the Envoy Gateway controller which manages the GatewayClass,
+	// e.g. to use a forked or renamed Envoy Gateway controller.
+	// The field is immutable on the GatewayClass, so the GatewayClass is recreated when it is changed.
+	// Default: gateway.envoyproxy.io/gatewayclass-controller
+	// +optional
+	ControllerName string `json:"controllerName,omitempty"`
+
+	// ReleaseName is the name of the Helm release of Envoy Gateway, e.g. if the default collides with an existing release.
+	// Changing it uninstalls the previous release and installs a new one.
+	// Default: eg
+	// +kubebuilder:validation:MaxLength=53
+	// +optional
+	ReleaseName string `json:"releaseName,omitempty"`
+
+	// StorageNamespace is the namespace on the target cluster in which the Helm release of Envoy Gateway is stored.
+	// Default: the deployment namespace
+	// +optional
+	StorageNamespace string `json:"storageNamespace,omitempty"`
+
+	// InstallCRDs controls whether the Helm release installs the Gateway API and Envoy Gateway CRDs.
+	// If set, the crds.gatewayAPI.enabled and crds.envoyGateway.enabled values of the chart are set accordingly,
+	// which is required for charts that render the CRDs as templates. If false, the CRDs of the chart are skipped
+	// and must be provided by other means, until then the gateway cannot be configured.
+	// Default: the CRDs of the chart are installed, without setting the values.
+	// +optional
+	InstallCRDs *bool `json:"installCRDs,omitempty"`
+}
+
+type RateLimitConfig struct {
+	// RedisURL is the URL of the Redis instance which is used by the rate limit service to store its counters.
+	// +kubebuilder:validation:MinLength=1
+	RedisURL string `json:"redisURL"`
+
+	// Rules are the rate limit rules which are applied to the traffic of the gateway.
+	// +kubebuilder:validation:MinItems=1
+	Rules []RateLimitRule `json:"rules"`
+
+	// Deployment configures the deployment of the rate limit service.
+	// If unset, the Envoy Gateway defaults apply.
+	// +optional
+	Deployment *RateLimitDeploymentConfig `json:"deployment,omitempty"`
+}
+
+type RateLimitDeploymentConfig struct {
+	// Replicas is the number of rate limit service pods.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources are the compute resources of the rate limit service container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+type RateLimitRule struct {
+	// Headers select the requests to which the rule applies by their headers.
+	// +optional
+	Headers []RateLimitHeaderMatch `json:"headers,omitempty"`
+
+	// SourceCIDR selects the requests to which the rule applies by the client IP address.
+	// Each client IP address within the CIDR is limited separately.
+	// +optional
+	SourceCIDR string `json:"sourceCIDR,omitempty"`
+
+	// Requests is the number of requests which are allowed per unit.
+	Requests uint32 `json:"requests"`
+
+	// Unit of the rate limit.
+	Unit egv1a1.RateLimitUnit `json:"unit"`
+}
+
+type RateLimitHeaderMatch struct {
+	// Name of the header.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Value of the header which must match exactly.
+	// If unset, each distinct value of the header is limited separately.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+type MonitoringConfig struct {
+	// ServiceMonitor configures the creation of prometheus-operator monitoring resources
+	// for the Envoy Gateway controller and the Envoy Proxy data plane.
+	// The resources are only created if the prometheus-operator CRDs are installed in the cluster.
+	// +optional
+	ServiceMonitor *ServiceMonitorConfig `json:"serviceMonitor,omitempty"`
+}
+
+type ServiceMonitorConfig struct {
+	// Enabled specifies whether the monitoring resources should be created.
+	Enabled bool `json:"enabled"`
+
+	// Labels are added to the monitoring resources, e.g. to match the selectors of the Prometheus instance.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type ProxyConfig struct {
+	// ProviderType specifies where the Envoy Proxy is run.
+	// Accepted values are "Kubernetes" and "Host". Default: Kubernetes
+	// Options that only apply to the Kubernetes provider must not be set when "Host" is used.
+	// +kubebuilder:validation:Enum=Kubernetes;Host
+	// +optional
+	ProviderType egv1a1.EnvoyProxyProviderType `json:"providerType,omitempty"`
+
+	// Replicas is the number of Envoy Proxy pods. Only applies to the Kubernetes provider.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Concurrency is the number of worker threads of each Envoy Proxy.
+	// If unset, the Envoy default applies, which is the number of hardware threads or the CPU limit of the pod.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass of the Envoy Proxy pods, which protects them from eviction under resource pressure.
+	// Only applies to the Kubernetes provider. If unset, no priority class is assigned.
+	// +optional
+	PriorityClassName *string `json:"priorityClassName,omitempty"`
+
+	// PodDisruptionBudget configures a PodDisruptionBudget for the Envoy Proxy deployment.
+	// If unset, no PodDisruptionBudget is created.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
+
+	// AccessLog configures the access logs of the Envoy Proxy.
+	// If unset, the Envoy Gateway defaults apply.
+	// +optional
+	AccessLog *AccessLogConfig `json:"accessLog,omitempty"`
+
+	// Metrics configures the metrics of the Envoy Proxy.
+	// If unset, the Envoy Gateway defaults apply.
+	// +optional
+	Metrics *ProxyMetricsConfig `json:"metrics,omitempty"`
+
+	// Shutdown configures how the Envoy Proxy drains connections when a pod is terminated, e.g. during rollouts.
+	// If unset, the Envoy Gateway defaults apply.
+	// +optional
+	Shutdown *ProxyShutdownConfig `json:"shutdown,omitempty"`
+
+	// Bootstrap customizes the bootstrap config of the Envoy Proxy, e.g. to add static clusters or admin settings.
+	// If unset, the bootstrap config generated by Envoy Gateway is used.
+	// +optional
+	Bootstrap *ProxyBootstrapConfig `json:"bootstrap,omitempty"`
+
+	// Logging configures the log levels of the Envoy Proxy.
+	// If unset, the Envoy Gateway defaults apply.
+	// +optional
+	Logging *ProxyLoggingConfig `json:"logging,omitempty"`
+
+	// ServiceAccount configures a dedicated ServiceAccount for the Envoy Proxy pods. Only applies to the Kubernetes provider.
+	// The ServiceAccount is created in the deployment namespace of Envoy Gateway.
+	// If unset, Envoy Gateway creates a ServiceAccount for each proxy.
+	// +optional
+	ServiceAccount *ProxyServiceAccountConfig `json:"serviceAccount,omitempty"`
+
+	// Service configures the Service of the Envoy Proxy. Only applies to the Kubernetes provider.
+	// If unset, the Envoy Gateway defaults apply.
+	// +optional
+	Service *ProxyServiceConfig `json:"service,omitempty"`
+}
+
+type ProxyServiceConfig struct {
+	// ExternalTrafficPolicy is the externalTrafficPolicy of the Service.
+	// Use "Local" to preserve the source IPs of clients, "Cluster" to distribute the traffic to all nodes.
+	// If unset, the Envoy Gateway default applies.
+	// +kubebuilder:validation:Enum=Local;Cluster
+	// +optional
+	ExternalTrafficPolicy egv1a1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LoadBalancerClass selects the load balancer implementation if more than one is available in the cluster.
+	// +optional
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+
+	// LoadBalancerIP is the static IPv4 or IPv6 address of the load balancer.
+	// It is ignored by load balancer implementations which don't support it.
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// LoadBalancerSourceRanges are the CIDRs of the clients which are allowed to access the load balancer.
+	// They are configured as firewall rules by the load balancer implementation, if supported.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// Ports are additional ports of the Service which forward to the port of a gateway listener,
+	// e.g. to expose the TLS listener on port 9443 through the standard port 443 of the load balancer.
+	// The ports which Envoy Gateway derives from the listeners are kept, so the ports must differ from the listener ports.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Ports []ProxyServicePort `json:"ports,omitempty"`
+}
+
+type ProxyServicePort struct {
+	// Name is the name of the Service port.
+	Name string `json:"name"`
+
+	// Port is the port exposed by the Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Listener is the name of the gateway listener which receives the traffic of the port.
+	// Without configured listeners, the default listener is named "tls".
+	Listener gatewayv1.SectionName `json:"listener"`
+}
+
+type ProxyServiceAccountConfig struct {
+	// Name is the name of the ServiceAccount.
+	// An existing ServiceAccount with this name is adopted, but not deleted during cleanup.
+	Name string `json:"name"`
+
+	// Annotations are added to the ServiceAccount, e.g. to bind it to a cloud provider identity.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ProxyLoggingConfig struct {
+	// Level is the default log level of all components of the Envoy Proxy.
+	// Accepted values are "trace", "debug", "info", "warn" and "error".
+	// If unset, the Envoy Gateway default applies.
+	// +kubebuilder:validation:Enum=trace;debug;info;warn;error
+	// +optional
+	Level egv1a1.LogLevel `json:"level,omitempty"`
+
+	// Components overrides the log level of individual components, e.g. "upstream" or "http".
+	// Components which are not listed use the default level.
+	// +optional
+	Components map[egv1a1.ProxyLogComponent]egv1a1.LogLevel `json:"components,omitempty"`
+}
+
+type ProxyBootstrapConfig struct {
+	// Type specifies how the Value is applied to the bootstrap config generated by Envoy Gateway.
+	// Merge merges the Value into the generated config, Replace replaces the generated config with the Value
+	// and JSONPatch applies the Value as a list of JSON patch operations.
+	// +kubebuilder:validation:Enum=Merge;Replace;JSONPatch
+	Type egv1a1.BootstrapType `json:"type"`
+
+	// Value is a YAML or JSON string. It contains an Envoy bootstrap config for the types Merge and Replace
+	// and a list of JSON patch operations for the type JSONPatch.
+	Value string `json:"value"`
+}
+
+type ProxyShutdownConfig struct {
+	// DrainTimeout is the maximum time given to the Envoy Proxy to drain connections on shutdown.
+	// It should be less than the terminationGracePeriodSeconds of the pods.
+	// If unset, the Envoy Gateway default applies.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+
+	// MinDrainDuration is the minimum time the Envoy Proxy keeps draining, which allows load balancers to stop sending traffic.
+	// If unset, the Envoy Gateway default applies.
+	// +optional
+	MinDrainDuration *metav1.Duration `json:"minDrainDuration,omitempty"`
+}
+
+type ProxyMetricsConfig struct {
+	// Prometheus configures the Prometheus endpoint of the Envoy Proxy.
+	// +optional
+	Prometheus *PrometheusMetricsConfig `json:"prometheus,omitempty"`
+
+	// OpenTelemetryEndpoint is the endpoint of an OTLP collector in the form "host:port".
+	// If set, the metrics are additionally pushed to the collector.
+	// +optional
+	OpenTelemetryEndpoint string `json:"openTelemetryEndpoint,omitempty"`
+}
+
+type PrometheusMetricsConfig struct {
+	// Disabled disables the Prometheus endpoint of the Envoy Proxy.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Compression of the responses of the Prometheus endpoint. Accepted values are "Gzip", "Brotli" and "Zstd".
+	// If unset, the responses are not compressed.
+	// +kubebuilder:validation:Enum=Gzip;Brotli;Zstd
+	// +optional
+	Compression egv1a1.CompressorType `json:"compression,omitempty"`
+}
+
+type AccessLogConfig struct {
+	// Format of the access log entries. Accepted values are "Text" and "JSON".
+	// Default: Text
+	// +kubebuilder:validation:Enum=Text;JSON
+	// +optional
+	Format egv1a1.ProxyAccessLogFormatType `json:"format,omitempty"`
+
+	// JSONFields are the fields of a JSON access log entry, mapped to Envoy command operators.
+	// Only applies to the JSON format. If unset, a default set of connection-level fields is logged.
+	// +optional
+	JSONFields map[string]string `json:"jsonFields,omitempty"`
+
+	// Sinks to which the access logs are written.
+	// +kubebuilder:validation:MinItems=1
+	Sinks []AccessLogSink `json:"sinks"`
+}
+
+type AccessLogSink struct {
+	// Type of the sink. Accepted values are "File" and "OpenTelemetry".
+	// +kubebuilder:validation:Enum=File;OpenTelemetry
+	Type egv1a1.ProxyAccessLogSinkType `json:"type"`
+
+	// Path of the file to which the access logs are written. Only applies to the File sink.
+	// Default: /dev/stdout
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Endpoint of the OTLP collector in the form "host:port". Required for the OpenTelemetry sink.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.minAvailable) != has(self.maxUnavailable)",message="exactly one of minAvailable or maxUnavailable must be specified"
+type PodDisruptionBudgetConfig struct {
+	// MinAvailable is the minimum number (or percentage) of Envoy Proxy pods that must be available during voluntary disruptions.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the maximum number (or percentage) of Envoy Proxy pods that can be unavailable during voluntary disruptions.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
+// ChartType is the type of the repository the chart is pulled from.
+// +kubebuilder:validation:Enum=oci;http
+type ChartType string
+
+const (
+	// ChartTypeOCI pulls the chart from an OCI registry.
+	ChartTypeOCI ChartType = "oci"
+	// ChartTypeHTTP pulls the chart from a classic HTTP Helm repository.
+	ChartTypeHTTP ChartType = "http"
+)
+
 type EnvoyGatewayChart struct {
+	// Type of the repository the chart is pulled from. Accepted values are "oci" and "http".
+	// Default: oci
+	// +optional
+	Type ChartType `json:"type,omitempty"`
+
 	// URL to the chart. Default: oci://docker.io/envoyproxy/gateway-helm
+	// For the http type, this is the URL of the Helm repository.
 	// +kubebuilder:default="oci://docker.io/envoyproxy/gateway-helm"
 	URL string `json:"url"`
 
+	// Name of the chart in the Helm repository. Only used for the http type.
+	// Default: gateway-helm
+	// +optional
+	Name string `json:"name,omitempty"`
+
 	// Tag of the chart. Example: 1.5.4
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:MinLength=1
-	Tag string `json:"tag"`
+	// For the http type, this is the version of the chart.
+	// Required unless VersionFrom is set. If both are set, the Tag takes precedence.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// VersionFrom reads the tag of the chart from a ConfigMap, so that the version can be managed centrally.
+	// The ConfigMap is read from the namespace of the platform service on the platform cluster when a cluster is reconciled.
+	// +optional
+	VersionFrom *ConfigMapKeyReference `json:"versionFrom,omitempty"`
 
 	// SecretRef specifies the Secret containing authentication credentials
-	// for the OCIRepository.
+	// for the OCIRepository or HelmRepository.
 	// For HTTP/S basic auth the secret must contain 'username' and 'password'
 	// fields.
 	// Support for TLS auth using the 'certFile' and 'keyFile', and/or 'caFile'
 	// keys is deprecated. Please use `.spec.certSecretRef` instead.
 	// +optional
 	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// CertSecretRef specifies the Secret containing the TLS authentication data
+	// for the OCIRepository or HelmRepository.
+	// The secret may contain 'tls.crt' and 'tls.key' for mutual TLS authentication,
+	// and/or 'ca.crt' for verifying the registry's certificate.
+	// It can be set in addition to SecretRef.
+	// +optional
+	CertSecretRef *meta.LocalObjectReference `json:"certSecretRef,omitempty"`
+
+	// AllowedTags restricts the chart tag to the given list of tags.
+	// If empty, all tags are allowed.
+	// +optional
+	AllowedTags []string `json:"allowedTags,omitempty"`
+
+	// VersionConstraint is a semantic version constraint the chart tag has to satisfy. Example: ">= 1.5.0, < 1.7.0"
+	// +optional
+	VersionConstraint string `json:"versionConstraint,omitempty"`
+
+	// LayerSelector configures which layer of the OCI artifact is used as chart.
+	// Only used for the oci type.
+	// Default: the layer with media type "application/vnd.cncf.helm.chart.content.v1.tar+gzip" is copied.
+	// +optional
+	LayerSelector *LayerSelectorConfig `json:"layerSelector,omitempty"`
+
+	// LayerMediaTypes are media types of the chart layer which are tried in the given order,
+	// e.g. if mirrored charts are packaged inconsistently across registries.
+	// The next media type is selected once Flux fails to find the layer in the OCI artifact. If none matches,
+	// the layer selector is omitted, so the first layer is used. The media types are tried again from the start when the chart tag changes.
+	// Only used for the oci type and mutually exclusive with the media type of the LayerSelector.
+	// +optional
+	LayerMediaTypes []string `json:"layerMediaTypes,omitempty"`
+
+	// DriftDetection configures how the HelmRelease handles changes to the installed resources made out-of-band.
+	// Default: drift detection is disabled.
+	// +optional
+	DriftDetection *DriftDetectionConfig `json:"driftDetection,omitempty"`
+}
+
+type ConfigMapKeyReference struct {
+	// Name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key in the data of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+type LayerSelectorConfig struct {
+	// Disabled omits the layer selector, so the first layer of the OCI artifact is used.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// MediaType of the layer which is used.
+	// Default: application/vnd.cncf.helm.chart.content.v1.tar+gzip
+	// +optional
+	MediaType string `json:"mediaType,omitempty"`
+
+	// Operation specifies how the layer is handled. Accepted values are "extract" and "copy".
+	// Default: copy
+	// +kubebuilder:validation:Enum=extract;copy
+	// +optional
+	Operation string `json:"operation,omitempty"`
+}
+
+type DriftDetectionConfig struct {
+	// Mode of the drift detection. Accepted values are "enabled", "warn" and "disabled".
+	// With "enabled", drifted resources are corrected, with "warn" drift is only reported.
+	// Default: disabled
+	// +kubebuilder:validation:Enum=enabled;warn;disabled
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// IgnorePaths are JSON Pointer (RFC 6901) paths which are excluded from the drift detection of all resources.
+	// Example: /spec/replicas
+	// +optional
+	IgnorePaths []string `json:"ignorePaths,omitempty"`
 }
 
 type ImagesConfig struct {
@@ -94,16 +639,323 @@ type ImagesConfig struct {
 	// Ratelimit image. Example: docker.io/envoyproxy/ratelimit:e74a664a
 	Ratelimit string `json:"rateLimit"`
 
+	// ShutdownManager is the image of the shutdown-manager sidecar, which Envoy Gateway adds to the Envoy proxy pods.
+	// Example: docker.io/envoyproxy/gateway:v1.5.1
+	// Default: the default image of Envoy Gateway.
+	// +optional
+	ShutdownManager string `json:"shutdownManager,omitempty"`
+
 	// ImagePullSecrets specifies the Secrets containing authentication credentials
-	// for the Envoy Gateway deployment.
+	// for the Envoy Gateway deployment and the Envoy proxy pods.
+	// They are set on pod level and therefore apply to all containers of the pods, including init containers and sidecars.
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// RequireDigest requires the EnvoyProxy, EnvoyGateway, Ratelimit and ShutdownManager images to be pinned by digest,
+	// e.g. docker.io/envoyproxy/gateway@sha256:<digest>.
+	// The EnvoyProxy and EnvoyGateway images must be set, because the default images of the chart are not pinned.
+	// +optional
+	RequireDigest bool `json:"requireDigest,omitempty"`
 }
 
 type GatewayConfig struct {
 	// TLSPort is the port on which the gateway will listen for TLS traffic.
+	// Ignored if Listeners are configured.
 	// +kubebuilder:default=9443
 	TLSPort int32 `json:"tlsPort,omitempty"`
+
+	// Listeners are the listeners of the gateway.
+	// Default: a single TLS passthrough listener named "tls" on the TLSPort.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Listeners []ListenerConfig `json:"listeners,omitempty"`
+
+	// RestrictToBaseDomain restricts the listeners to the domains of the cluster by setting their hostname to
+	// *.<cluster domain>, so that the gateway only accepts traffic for the cluster instead of any SNI.
+	// If AdditionalBaseDomains are configured, each listener is repeated for each of these domains and named
+	// <name>-<index>, so the names of other listeners must not follow this pattern.
+	// The hostnames of HostnameCertificateRefs must be within the base domains and are only rendered for the clusters
+	// whose domains contain them.
+	// Default: false, the listeners accept any hostname.
+	// +optional
+	RestrictToBaseDomain bool `json:"restrictToBaseDomain,omitempty"`
+
+	// Routes are materialized as TLSRoutes attached to the gateway.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Routes []RouteConfig `json:"routes,omitempty"`
+
+	// BackendTrafficPolicy configures the traffic from the gateway to the backends.
+	// If set, a BackendTrafficPolicy is attached to the gateway.
+	// +optional
+	BackendTrafficPolicy *BackendTrafficPolicyConfig `json:"backendTrafficPolicy,omitempty"`
+
+	// ClientTrafficPolicy configures the traffic from the clients to the gateway.
+	// If set, a ClientTrafficPolicy is attached to the gateway.
+	// +optional
+	ClientTrafficPolicy *ClientTrafficPolicyConfig `json:"clientTrafficPolicy,omitempty"`
+
+	// AllowedRoutes restricts the namespaces from which routes may be attached to the gateway listener.
+	// Default: routes from all namespaces are allowed.
+	// +optional
+	AllowedRoutes *AllowedRoutesConfig `json:"allowedRoutes,omitempty"`
+
+	// EnvoyPatchPolicy configures raw Envoy xDS patches which are not exposed through the typed APIs of Envoy Gateway.
+	// If enabled, an EnvoyPatchPolicy is attached to the gateway.
+	// +optional
+	EnvoyPatchPolicy *EnvoyPatchPolicyConfig `json:"envoyPatchPolicy,omitempty"`
+
+	// SecurityPolicy configures the authentication of the traffic to the gateway.
+	// If set, a SecurityPolicy is attached to the gateway.
+	// +optional
+	SecurityPolicy *SecurityPolicyConfig `json:"securityPolicy,omitempty"`
+}
+
+type SecurityPolicyConfig struct {
+	// JWT enables the validation of JSON Web Tokens before traffic is passed to the backends.
+	// +optional
+	JWT *JWTConfig `json:"jwt,omitempty"`
+
+	// OIDC enables the OpenID Connect login flow for the traffic to the gateway.
+	// +optional
+	OIDC *OIDCConfig `json:"oidc,omitempty"`
+
+	// CORS configures the Cross-Origin Resource Sharing headers for the traffic to the gateway.
+	// +optional
+	CORS *CORSConfig `json:"cors,omitempty"`
+}
+
+type CORSConfig struct {
+	// AllowOrigins are the origins which are allowed to make requests, e.g. "https://*.example.com".
+	// +kubebuilder:validation:MinItems=1
+	AllowOrigins []egv1a1.Origin `json:"allowOrigins"`
+
+	// AllowMethods are the HTTP methods which are allowed in cross-origin requests.
+	// +optional
+	AllowMethods []string `json:"allowMethods,omitempty"`
+
+	// AllowHeaders are the HTTP headers which are allowed in cross-origin requests.
+	// +optional
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+
+	// MaxAge is the duration for which the response to a preflight request may be cached.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+type OIDCConfig struct {
+	// Issuer is the issuer URL of the OpenID Connect provider.
+	// +kubebuilder:validation:MinLength=1
+	Issuer string `json:"issuer"`
+
+	// ClientID is the client ID of the application registered at the OpenID Connect provider.
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// ClientSecretRef references a Secret in the namespace of the Cluster resource on the platform cluster,
+	// which contains the client secret in the "client-secret" key.
+	// The Secret is copied into the gateway namespace of the target cluster.
+	ClientSecretRef corev1.LocalObjectReference `json:"clientSecretRef"`
+
+	// RedirectURL is the URL to which the OpenID Connect provider redirects after the login.
+	// Default: the default of Envoy Gateway ("%REQ(x-forwarded-proto)%://%REQ(:authority)%/oauth2/callback").
+	// +optional
+	RedirectURL string `json:"redirectURL,omitempty"`
+
+	// Scopes which are requested in addition to the "openid" scope.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+type JWTConfig struct {
+	// Issuer is the principal that issued the JWT, which must match the "iss" claim.
+	// +kubebuilder:validation:MinLength=1
+	Issuer string `json:"issuer"`
+
+	// JWKSURI is the HTTPS URI from which the JSON Web Key Set used to verify the JWT is fetched.
+	// +kubebuilder:validation:MinLength=1
+	JWKSURI string `json:"jwksURI"`
+
+	// Audiences is a list of JWT audiences allowed access. If empty, the audience is not checked.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+type EnvoyPatchPolicyConfig struct {
+	// Enabled explicitly enables the EnvoyPatchPolicy API of Envoy Gateway and materializes the EnvoyPatches.
+	// Patches are applied to the generated xDS resources without further validation by Envoy Gateway,
+	// so invalid patches may break the gateway.
+	Enabled bool `json:"enabled"`
+
+	// EnvoyPatches are passed through to the EnvoyPatchPolicy as JSON patches.
+	// Each patch must be an object with the fields "type", "name" and "operation".
+	// +optional
+	EnvoyPatches []apiextensionsv1.JSON `json:"envoyPatches,omitempty"`
+}
+
+type ListenerConfig struct {
+	// Name is the name of the listener, unique within the gateway.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name gatewayv1.SectionName `json:"name"`
+
+	// Port is the port on which the listener accepts traffic.
+	// +kubebuilder:validation:Type=integer
+	// +kubebuilder:validation:Format=int32
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port gatewayv1.PortNumber `json:"port"`
+
+	// Protocol is the protocol of the listener.
+	// TCP accepts plain TCP connections without TLS config, e.g. if TLS is terminated in front of the gateway.
+	// +kubebuilder:validation:Enum=TLS;HTTPS;HTTP;TCP
+	// +kubebuilder:default=TLS
+	Protocol gatewayv1.ProtocolType `json:"protocol,omitempty"`
+
+	// TLSMode is the TLS mode of the listener. Only applies to the TLS and HTTPS protocols.
+	// Passthrough forwards the encrypted traffic to the backends, Terminate decrypts it at the gateway.
+	// Default: Passthrough for TLS, Terminate for HTTPS.
+	// +kubebuilder:validation:Enum=Passthrough;Terminate
+	// +optional
+	TLSMode *gatewayv1.TLSModeType `json:"tlsMode,omitempty"`
+
+	// CertificateRefs reference the Secrets with the certificates used to terminate TLS.
+	// Must be set if TLSMode is Terminate.
+	// +optional
+	CertificateRefs []gatewayv1.SecretObjectReference `json:"certificateRefs,omitempty"`
+
+	// HostnameCertificateRefs reference the Secrets with the certificates used to terminate TLS for specific SNI hostnames.
+	// A separate listener is rendered for each hostname, the CertificateRefs are used for all other hostnames.
+	// Must only be set if TLSMode is Terminate.
+	// +optional
+	HostnameCertificateRefs []HostnameCertificateRefs `json:"hostnameCertificateRefs,omitempty"`
+
+	// AllowedRoutes restricts the namespaces from which routes may be attached to this listener.
+	// Default: the AllowedRoutes of the GatewayConfig.
+	// +optional
+	AllowedRoutes *AllowedRoutesConfig `json:"allowedRoutes,omitempty"`
+}
+
+type HostnameCertificateRefs struct {
+	// Hostname is the SNI hostname to which the certificates apply, e.g. "api.example.com" or "*.example.com".
+	Hostname gatewayv1.Hostname `json:"hostname"`
+
+	// CertificateRefs reference the Secrets with the certificates for the hostname.
+	// +kubebuilder:validation:MinItems=1
+	CertificateRefs []gatewayv1.SecretObjectReference `json:"certificateRefs"`
+}
+
+type AllowedRoutesConfig struct {
+	// From indicates in which namespaces routes may be attached to the gateway.
+	// Accepted values are "All", "Same" (only the namespace of the gateway) and "Selector".
+	// +kubebuilder:validation:Enum=All;Same;Selector
+	// +kubebuilder:default=All
+	From gatewayv1.FromNamespaces `json:"from,omitempty"`
+
+	// Selector selects the namespaces from which routes may be attached to the gateway.
+	// Must only be set if From is "Selector".
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type ClientTrafficPolicyConfig struct {
+	// TLSMinVersion is the minimum TLS version accepted from clients.
+	// Only applies to listeners which terminate TLS.
+	// +optional
+	TLSMinVersion *egv1a1.TLSVersion `json:"tlsMinVersion,omitempty"`
+
+	// TCPKeepalive enables TCP keepalive for client connections.
+	// +optional
+	TCPKeepalive *TCPKeepaliveConfig `json:"tcpKeepalive,omitempty"`
+
+	// Connection configures limits of client connections.
+	// +optional
+	Connection *ClientConnectionConfig `json:"connection,omitempty"`
+}
+
+type TCPKeepaliveConfig struct {
+	// Probes is the number of unacknowledged probes after which the connection is considered dead.
+	// +optional
+	Probes *uint32 `json:"probes,omitempty"`
+
+	// IdleTime is the time a connection needs to be idle before keepalive probes are sent.
+	// +optional
+	IdleTime *metav1.Duration `json:"idleTime,omitempty"`
+
+	// Interval is the time between keepalive probes.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+type ClientConnectionConfig struct {
+	// BufferLimit is the maximum amount of data buffered per client connection.
+	// +optional
+	BufferLimit *resource.Quantity `json:"bufferLimit,omitempty"`
+
+	// ConnectionLimit is the maximum number of concurrent client connections per Envoy Proxy.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ConnectionLimit *int64 `json:"connectionLimit,omitempty"`
+}
+
+type BackendTrafficPolicyConfig struct {
+	// ConnectTimeout is the timeout for establishing a connection to a backend.
+	// +optional
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
+
+	// MaxConnections is the maximum number of connections the gateway establishes to a backend.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConnections *int64 `json:"maxConnections,omitempty"`
+
+	// HealthCheck configures active health checks of the backends.
+	// +optional
+	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty"`
+}
+
+type HealthCheckConfig struct {
+	// Path of the HTTP endpoint which is used for the health checks.
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+}
+
+type RouteConfig struct {
+	// Name of the TLSRoute.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the TLSRoute on the target cluster.
+	// Default: namespace of the gateway
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Subdomain is prepended to the base domain of the cluster to form the SNI hostname of the route.
+	// Example: "api" results in "api.<cluster name>.<cluster namespace>.<base domain>".
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Subdomain string `json:"subdomain"`
+
+	// BackendRef references the Service on the target cluster to which the traffic is forwarded.
+	BackendRef RouteBackendRef `json:"backendRef"`
+}
+
+type RouteBackendRef struct {
+	// Name of the Service.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Service.
+	// Default: namespace of the route
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port of the Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
 }
 
 type DNSConfig struct {
@@ -112,9 +964,25 @@ type DNSConfig struct {
 	// +kubebuilder:validation:MinLength=1
 	BaseDomain string `json:"baseDomain"`
 
+	// AdditionalBaseDomains are further domains under which the clusters are reachable, e.g. a legacy base domain.
+	// Subdomains are derived from them in the same way as from the BaseDomain.
+	// +optional
+	// +listType=set
+	AdditionalBaseDomains []string `json:"additionalBaseDomains,omitempty"`
+
 	// SubdomainTemplate defines how subdomains for clusters will be generated.
-	// +kubebuilder:default={{.Cluster.Name}}.{{.Cluster.Namespace}}
-	// SubdomainTemplate string `json:"subdomainTemplate"`
+	// It is a Go text/template which is rendered with the fields .Cluster.Name, .Cluster.Namespace,
+	// .Cluster.Labels and .Cluster.Annotations, e.g. {{ index .Cluster.Labels "team" }}.{{ .Cluster.Name }}.
+	// Only the builtin template functions are available. Missing labels or annotations render as empty strings.
+	// Defaults to {{.Cluster.Name}}.{{.Cluster.Namespace}}.
+	// +optional
+	SubdomainTemplate string `json:"subdomainTemplate,omitempty"`
+
+	// BaseDomainAnnotation is the key of the annotation on the Gateway which lists the domains of the cluster,
+	// e.g. to integrate with the DNS tooling of the landscape.
+	// Default: dns.openmcp.cloud/base-domain
+	// +optional
+	BaseDomainAnnotation string `json:"baseDomainAnnotation,omitempty"`
 }
 
 // +kubebuilder:object:root=true
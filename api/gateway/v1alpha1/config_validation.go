@@ -0,0 +1,914 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ociTagRegexp matches valid tags as defined by the OCI distribution specification.
+var ociTagRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+// supportedLogLevels are the log levels supported by the Envoy Gateway controller.
+var supportedLogLevels = []egv1a1.LogLevel{
+	egv1a1.LogLevelDebug,
+	egv1a1.LogLevelInfo,
+	egv1a1.LogLevelWarn,
+	egv1a1.LogLevelError,
+}
+
+// supportedProxyLogLevels are the log levels supported by the Envoy Proxy.
+var supportedProxyLogLevels = []egv1a1.LogLevel{
+	egv1a1.LogLevelTrace,
+	egv1a1.LogLevelDebug,
+	egv1a1.LogLevelInfo,
+	egv1a1.LogLevelWarn,
+	egv1a1.LogLevelError,
+}
+
+// supportedExternalTrafficPolicies are the external traffic policies supported by the Service of the Envoy Proxy.
+var supportedExternalTrafficPolicies = []egv1a1.ServiceExternalTrafficPolicy{
+	egv1a1.ServiceExternalTrafficPolicyLocal,
+	egv1a1.ServiceExternalTrafficPolicyCluster,
+}
+
+// supportedDriftDetectionModes are the drift detection modes of the Flux HelmRelease.
+var supportedDriftDetectionModes = []string{
+	"enabled",
+	"warn",
+	"disabled",
+}
+
+// supportedProxyLogComponents are the components of the Envoy Proxy whose log level can be configured.
+// The default component is configured by ProxyLoggingConfig.Level instead.
+var supportedProxyLogComponents = []egv1a1.ProxyLogComponent{
+	"system", // part of the EnvoyProxy enum, but without a constant
+	egv1a1.LogComponentUpstream,
+	egv1a1.LogComponentHTTP,
+	egv1a1.LogComponentConnection,
+	egv1a1.LogComponentAdmin,
+	egv1a1.LogComponentClient,
+	egv1a1.LogComponentFilter,
+	egv1a1.LogComponentMain,
+	egv1a1.LogComponentRouter,
+	egv1a1.LogComponentRuntime,
+}
+
+// controllerNameRegexp matches domain-prefixed paths as required for the controller name of a GatewayClass.
+var controllerNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/[A-Za-z0-9/\-._~%!$&'()*+,;=:]+$`)
+
+// imageDigestRegexp matches image references which are pinned by a sha256 digest.
+var imageDigestRegexp = regexp.MustCompile(`@sha256:[a-f0-9]{64}$`)
+
+// Validate validates the GatewayServiceConfigSpec.
+// It returns an aggregated error containing all validation errors or nil, if the spec is valid.
+func (s *GatewayServiceConfigSpec) Validate() error {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, s.EnvoyGateway.Validate(field.NewPath("spec", "envoyGateway"))...)
+	allErrs = append(allErrs, s.DNS.Validate(field.NewPath("spec", "dns"))...)
+	for i := range s.Clusters {
+		allErrs = append(allErrs, s.Clusters[i].Validate(field.NewPath("spec", "clusters").Index(i))...)
+	}
+	if s.Gateway != nil {
+		allErrs = append(allErrs, s.Gateway.Validate(field.NewPath("spec", "gateway"))...)
+	}
+	if s.Cleanup != nil {
+		allErrs = append(allErrs, s.Cleanup.Validate(field.NewPath("spec", "cleanup"))...)
+	}
+	if s.ClusterAccess != nil {
+		allErrs = append(allErrs, s.ClusterAccess.Validate(field.NewPath("spec", "clusterAccess"))...)
+	}
+	if s.CreateNamespaces != nil && !*s.CreateNamespaces && len(s.EnvoyGateway.DeploymentNamespaceLabels) > 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "envoyGateway", "deploymentNamespaceLabels"), "must not be set when namespaces are not created"))
+	}
+	allErrs = append(allErrs, s.validateServicePortListeners()...)
+	allErrs = append(allErrs, s.validateRestrictedHostnames()...)
+	return allErrs.ToAggregate()
+}
+
+// validateRestrictedHostnames checks that the hostnames with their own certificates are within the base domains
+// if the listeners are restricted to the base domain, so that they don't accept traffic for other domains.
+func (s *GatewayServiceConfigSpec) validateRestrictedHostnames() field.ErrorList {
+	if s.Gateway == nil || !s.Gateway.RestrictToBaseDomain {
+		return nil
+	}
+	baseDomains := append([]string{s.DNS.BaseDomain}, s.DNS.AdditionalBaseDomains...)
+	allErrs := field.ErrorList{}
+	for i, l := range s.Gateway.Listeners {
+		for j, refs := range l.HostnameCertificateRefs {
+			if !slices.ContainsFunc(baseDomains, func(domain string) bool { return HostnameInDomain(refs.Hostname, domain) }) {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "gateway", "listeners").Index(i).Child("hostnameCertificateRefs").Index(j).Child("hostname"),
+					refs.Hostname, "must be within the base domains if restrictToBaseDomain is set"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// HostnameInDomain checks if the given hostname, which may be a wildcard, is the given domain or one of its subdomains.
+func HostnameInDomain(hostname gatewayv1.Hostname, domain string) bool {
+	name := strings.TrimPrefix(string(hostname), "*.")
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// validateServicePortListeners checks that the additional ports of the proxy Service refer to listeners of the gateway
+// and don't clash with the ports of the listeners, which Envoy Gateway adds to the Service itself.
+func (s *GatewayServiceConfigSpec) validateServicePortListeners() field.ErrorList {
+	if s.EnvoyGateway.Proxy == nil || s.EnvoyGateway.Proxy.Service == nil {
+		return nil
+	}
+	tlsPort := DefaultTLSPort
+	if s.Gateway != nil && s.Gateway.TLSPort != 0 {
+		tlsPort = s.Gateway.TLSPort
+	}
+	listeners := []ListenerConfig{{Name: DefaultListenerName, Port: tlsPort}}
+	if s.Gateway != nil && len(s.Gateway.Listeners) > 0 {
+		listeners = s.Gateway.Listeners
+	}
+	allErrs := field.ErrorList{}
+	for i, port := range s.EnvoyGateway.Proxy.Service.Ports {
+		idxPath := field.NewPath("spec", "envoyGateway", "proxy", "service", "ports").Index(i)
+		if port.Listener != "" && !slices.ContainsFunc(listeners, func(l ListenerConfig) bool { return l.Name == port.Listener }) {
+			allErrs = append(allErrs, field.NotFound(idxPath.Child("listener"), port.Listener))
+		}
+		// ports are merged by their number, a Service port equal to a listener port would replace the port of the listener
+		if j := slices.IndexFunc(listeners, func(l ListenerConfig) bool { return l.Port == port.Port }); j >= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), port.Port, fmt.Sprintf("must not be the port of listener %q", listeners[j].Name)))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the GatewayConfig.
+func (c *GatewayConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.BackendTrafficPolicy != nil {
+		allErrs = append(allErrs, c.BackendTrafficPolicy.Validate(fldPath.Child("backendTrafficPolicy"))...)
+	}
+	if c.AllowedRoutes != nil {
+		allErrs = append(allErrs, c.AllowedRoutes.Validate(fldPath.Child("allowedRoutes"))...)
+	}
+	names := map[gatewayv1.SectionName]bool{}
+	for i := range c.Listeners {
+		idxPath := fldPath.Child("listeners").Index(i)
+		allErrs = append(allErrs, c.Listeners[i].Validate(idxPath)...)
+		if names[c.Listeners[i].Name] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), c.Listeners[i].Name))
+		}
+		names[c.Listeners[i].Name] = true
+	}
+	// the names of the listeners generated for hostnames must neither clash with configured names nor exceed the limits of a section name
+	for i := range c.Listeners {
+		for j := range c.Listeners[i].HostnameCertificateRefs {
+			idxPath := fldPath.Child("listeners").Index(i).Child("hostnameCertificateRefs").Index(j)
+			name := c.Listeners[i].HostnameListenerName(j)
+			for _, msg := range validation.IsDNS1123Subdomain(string(name)) {
+				allErrs = append(allErrs, field.Invalid(idxPath, name, "generated listener name: "+msg))
+			}
+			if names[name] {
+				allErrs = append(allErrs, field.Duplicate(idxPath, name))
+			}
+			names[name] = true
+		}
+	}
+	if c.RestrictToBaseDomain {
+		allErrs = append(allErrs, c.validateDomainListenerNames(fldPath)...)
+	}
+	if c.EnvoyPatchPolicy != nil {
+		allErrs = append(allErrs, c.EnvoyPatchPolicy.Validate(fldPath.Child("envoyPatchPolicy"))...)
+	}
+	if c.SecurityPolicy != nil {
+		allErrs = append(allErrs, c.SecurityPolicy.Validate(fldPath.Child("securityPolicy"))...)
+	}
+	return allErrs
+}
+
+// validateDomainListenerNames checks that the listeners which are repeated for the additional base domains
+// don't clash with the names of the other listeners, including the ones generated for hostnames.
+// The number of domains is not known here, so any name which could be generated for the listener is rejected.
+func (c *GatewayConfig) validateDomainListenerNames(fldPath *field.Path) field.ErrorList {
+	names := make([]gatewayv1.SectionName, 0, len(c.Listeners))
+	for i := range c.Listeners {
+		names = append(names, c.Listeners[i].Name)
+		for j := range c.Listeners[i].HostnameCertificateRefs {
+			names = append(names, c.Listeners[i].HostnameListenerName(j))
+		}
+	}
+	allErrs := field.ErrorList{}
+	for i := range c.Listeners {
+		if c.Listeners[i].EffectiveProtocol() == gatewayv1.TCPProtocolType {
+			// TCP listeners don't support hostnames and are not repeated
+			continue
+		}
+		for _, name := range names {
+			if IsDomainListenerName(name, c.Listeners[i].Name) {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Child("listeners").Index(i).Child("name"), name))
+			}
+		}
+	}
+	return allErrs
+}
+
+// DomainListenerName returns the name of the copy of the given listener for the additional base domain with the given index.
+// The listener for the primary domain, index 0, keeps its name.
+func DomainListenerName(listener gatewayv1.SectionName, i int) gatewayv1.SectionName {
+	if i == 0 {
+		return listener
+	}
+	return gatewayv1.SectionName(fmt.Sprintf("%s-%d", listener, i))
+}
+
+// IsDomainListenerName checks if the given name is generated by DomainListenerName for the given listener and an additional domain.
+func IsDomainListenerName(name, listener gatewayv1.SectionName) bool {
+	suffix, ok := strings.CutPrefix(string(name), string(listener)+"-")
+	if !ok || suffix == "" || suffix[0] == '0' {
+		return false
+	}
+	return strings.Trim(suffix, "0123456789") == ""
+}
+
+// Validate validates the ListenerConfig.
+func (c *ListenerConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, msg := range validation.IsDNS1123Subdomain(string(c.Name)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), c.Name, msg))
+	}
+	for _, msg := range validation.IsValidPortNum(int(c.Port)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), c.Port, msg))
+	}
+	tlsMode := c.EffectiveTLSMode()
+	switch c.Protocol {
+	case "", gatewayv1.TLSProtocolType:
+	case gatewayv1.HTTPSProtocolType:
+		if tlsMode != gatewayv1.TLSModeTerminate {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tlsMode"), tlsMode, "must be Terminate for protocol HTTPS"))
+		}
+	case gatewayv1.HTTPProtocolType, gatewayv1.TCPProtocolType:
+		if c.TLSMode != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("tlsMode"), "must only be set for protocols TLS and HTTPS"))
+		}
+		if len(c.CertificateRefs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("certificateRefs"), "must only be set for protocols TLS and HTTPS"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), c.Protocol, []string{
+			string(gatewayv1.TLSProtocolType), string(gatewayv1.HTTPSProtocolType), string(gatewayv1.HTTPProtocolType), string(gatewayv1.TCPProtocolType),
+		}))
+	}
+	if tlsMode == gatewayv1.TLSModeTerminate && len(c.CertificateRefs) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("certificateRefs"), "must be set if tlsMode is Terminate"))
+	}
+	if tlsMode == gatewayv1.TLSModePassthrough && len(c.CertificateRefs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("certificateRefs"), "must only be set if tlsMode is Terminate"))
+	}
+	if tlsMode != gatewayv1.TLSModeTerminate && len(c.HostnameCertificateRefs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostnameCertificateRefs"), "must only be set if tlsMode is Terminate"))
+	}
+	hostnames := map[gatewayv1.Hostname]bool{}
+	for i, refs := range c.HostnameCertificateRefs {
+		idxPath := fldPath.Child("hostnameCertificateRefs").Index(i)
+		for _, msg := range validation.IsDNS1123Subdomain(strings.TrimPrefix(string(refs.Hostname), "*.")) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("hostname"), refs.Hostname, msg))
+		}
+		if hostnames[refs.Hostname] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("hostname"), refs.Hostname))
+		}
+		hostnames[refs.Hostname] = true
+		if len(refs.CertificateRefs) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("certificateRefs"), ""))
+		}
+	}
+	if c.AllowedRoutes != nil {
+		allErrs = append(allErrs, c.AllowedRoutes.Validate(fldPath.Child("allowedRoutes"))...)
+	}
+	return allErrs
+}
+
+// HostnameListenerName returns the name of the listener which is generated for the hostname with the given index.
+func (c *ListenerConfig) HostnameListenerName(i int) gatewayv1.SectionName {
+	return gatewayv1.SectionName(fmt.Sprintf("%s-sni-%d", c.Name, i))
+}
+
+// EffectiveProtocol returns the protocol of the listener, defaulting to TLS.
+func (c *ListenerConfig) EffectiveProtocol() gatewayv1.ProtocolType {
+	if c.Protocol == "" {
+		return gatewayv1.TLSProtocolType
+	}
+	return c.Protocol
+}
+
+// EffectiveTLSMode returns the TLS mode of the listener, defaulting to Passthrough for TLS and to Terminate for HTTPS.
+// Listeners with other protocols don't have a TLS mode, for them an empty string is returned.
+func (c *ListenerConfig) EffectiveTLSMode() gatewayv1.TLSModeType {
+	switch c.EffectiveProtocol() {
+	case gatewayv1.TLSProtocolType:
+		return ptr.Deref(c.TLSMode, gatewayv1.TLSModePassthrough)
+	case gatewayv1.HTTPSProtocolType:
+		return ptr.Deref(c.TLSMode, gatewayv1.TLSModeTerminate)
+	}
+	return ""
+}
+
+// Validate validates the AllowedRoutesConfig.
+func (c *AllowedRoutesConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.From == gatewayv1.NamespacesFromSelector && c.Selector == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("selector"), "must be set if from is Selector"))
+	}
+	if c.From != gatewayv1.NamespacesFromSelector && c.Selector != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("selector"), "must only be set if from is Selector"))
+	}
+	if c.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(c.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("selector"), c.Selector, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the EnvoyPatchPolicyConfig.
+func (c *EnvoyPatchPolicyConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if !c.Enabled && len(c.EnvoyPatches) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("envoyPatches"), "must only be set if enabled is true"))
+	}
+	for i, patch := range c.EnvoyPatches {
+		var obj map[string]any
+		if err := json.Unmarshal(patch.Raw, &obj); err != nil || obj == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("envoyPatches").Index(i), string(patch.Raw), "must be a valid JSON object"))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the SecurityPolicyConfig.
+func (c *SecurityPolicyConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.JWT != nil {
+		allErrs = append(allErrs, c.JWT.Validate(fldPath.Child("jwt"))...)
+	}
+	if c.OIDC != nil {
+		allErrs = append(allErrs, c.OIDC.Validate(fldPath.Child("oidc"))...)
+	}
+	if c.CORS != nil {
+		allErrs = append(allErrs, c.CORS.Validate(fldPath.Child("cors"))...)
+	}
+	return allErrs
+}
+
+// Validate validates the CORSConfig.
+func (c *CORSConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(c.AllowOrigins) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("allowOrigins"), "must not be empty"))
+	}
+	for i, origin := range c.AllowOrigins {
+		if strings.TrimSpace(string(origin)) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("allowOrigins").Index(i), "must not be empty"))
+		}
+	}
+	if c.MaxAge != nil && c.MaxAge.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxAge"), c.MaxAge.Duration.String(), "must not be negative"))
+	}
+	return allErrs
+}
+
+// Validate validates the OIDCConfig.
+func (c *OIDCConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if strings.TrimSpace(c.Issuer) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("issuer"), "must not be empty"))
+	} else if u, err := url.Parse(c.Issuer); err != nil || u.Scheme != "https" || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("issuer"), c.Issuer, "must be a valid https:// URL"))
+	}
+	if strings.TrimSpace(c.ClientID) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientID"), "must not be empty"))
+	}
+	if c.ClientSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientSecretRef", "name"), "must not be empty"))
+	}
+	if c.RedirectURL != "" {
+		if u, err := url.Parse(c.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("redirectURL"), c.RedirectURL, "must be a valid http:// or https:// URL"))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the JWTConfig.
+func (c *JWTConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if strings.TrimSpace(c.Issuer) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("issuer"), "must not be empty"))
+	}
+	if strings.TrimSpace(c.JWKSURI) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("jwksURI"), "must not be empty"))
+	} else if u, err := url.Parse(c.JWKSURI); err != nil || u.Scheme != "https" || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("jwksURI"), c.JWKSURI, "must be a valid https:// URI"))
+	}
+	return allErrs
+}
+
+// Validate validates the BackendTrafficPolicyConfig.
+func (c *BackendTrafficPolicyConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.ConnectTimeout != nil && c.ConnectTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("connectTimeout"), c.ConnectTimeout.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// Validate validates the CleanupConfig.
+func (c *CleanupConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.DrainTimeout != nil && c.DrainTimeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("drainTimeout"), c.DrainTimeout.Duration.String(), "must not be negative"))
+	}
+	if c.PropagationPolicy != nil && *c.PropagationPolicy != metav1.DeletePropagationForeground && *c.PropagationPolicy != metav1.DeletePropagationBackground {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("propagationPolicy"), *c.PropagationPolicy, []metav1.DeletionPropagation{
+			metav1.DeletePropagationForeground, metav1.DeletePropagationBackground,
+		}))
+	}
+	if c.RetryInterval != nil && c.RetryInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retryInterval"), c.RetryInterval.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// Validate validates the ClusterAccessConfig.
+func (c *ClusterAccessConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.Timeout != nil && c.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), c.Timeout.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// Validate validates the ClusterTerm.
+func (t *ClusterTerm) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if t.Selector == nil && t.ClusterRef == nil && len(t.ClusterRefs) == 0 && t.ExcludeSelector == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "one of selector, clusterRef, clusterRefs or excludeSelector must be set"))
+	}
+	if t.ClusterRef != nil && t.ClusterRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clusterRef", "name"), "must not be empty"))
+	}
+	for i, ref := range t.ClusterRefs {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("clusterRefs").Index(i).Child("name"), "must not be empty"))
+		}
+	}
+	if t.Selector != nil && t.ExcludeSelector != nil && reflect.DeepEqual(t.Selector, t.ExcludeSelector) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("excludeSelector"), t.ExcludeSelector, "must not be equal to selector, the term would never match"))
+	}
+	return allErrs
+}
+
+// Validate validates the DNSConfig.
+func (c *DNSConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if strings.TrimSpace(c.BaseDomain) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("baseDomain"), "must not be empty"))
+	}
+	seen := map[string]bool{c.BaseDomain: true}
+	for i, domain := range c.AdditionalBaseDomains {
+		idxPath := fldPath.Child("additionalBaseDomains").Index(i)
+		for _, msg := range validation.IsDNS1123Subdomain(domain) {
+			allErrs = append(allErrs, field.Invalid(idxPath, domain, msg))
+		}
+		if seen[domain] {
+			allErrs = append(allErrs, field.Duplicate(idxPath, domain))
+		}
+		seen[domain] = true
+	}
+	if c.SubdomainTemplate != "" {
+		if _, err := ParseSubdomainTemplate(c.SubdomainTemplate); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("subdomainTemplate"), c.SubdomainTemplate, err.Error()))
+		}
+	}
+	if c.BaseDomainAnnotation != "" {
+		for _, msg := range validation.IsQualifiedName(c.BaseDomainAnnotation) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("baseDomainAnnotation"), c.BaseDomainAnnotation, msg))
+		}
+	}
+	return allErrs
+}
+
+// ParseSubdomainTemplate parses the given DNSConfig.SubdomainTemplate.
+// Missing map keys, e.g. labels which are not set on a cluster, render as empty strings.
+func ParseSubdomainTemplate(text string) (*template.Template, error) {
+	return template.New("subdomain").Option("missingkey=zero").Parse(text)
+}
+
+// Validate validates the EnvoyGatewayConfig.
+func (c *EnvoyGatewayConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, c.Chart.Validate(fldPath.Child("chart"))...)
+	if c.Proxy != nil {
+		allErrs = append(allErrs, c.Proxy.Validate(fldPath.Child("proxy"))...)
+	}
+	if c.Images != nil {
+		allErrs = append(allErrs, c.Images.Validate(fldPath.Child("images"))...)
+	}
+	if c.RateLimit != nil && (c.Images == nil || c.Images.Ratelimit == "") {
+		allErrs = append(allErrs, field.Required(fldPath.Child("images", "rateLimit"), "must be set when rate limiting is enabled"))
+	}
+	if c.LogLevel != "" && !slices.Contains(supportedLogLevels, c.LogLevel) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("logLevel"), c.LogLevel, supportedLogLevels))
+	}
+	if c.ReleaseName != "" {
+		if len(c.ReleaseName) > 53 {
+			allErrs = append(allErrs, field.TooLong(fldPath.Child("releaseName"), c.ReleaseName, 53))
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(c.ReleaseName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("releaseName"), c.ReleaseName, msg))
+		}
+	}
+	if c.StorageNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(c.StorageNamespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storageNamespace"), c.StorageNamespace, msg))
+		}
+	}
+	if c.ControllerName != "" && (len(c.ControllerName) > 253 || !controllerNameRegexp.MatchString(c.ControllerName)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("controllerName"), c.ControllerName, "must be a domain prefixed path, e.g. example.com/gateway-controller"))
+	}
+	return allErrs
+}
+
+// Validate validates the ImagesConfig.
+func (c *ImagesConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if !c.RequireDigest {
+		return allErrs
+	}
+
+	images := []struct {
+		name     string
+		image    string
+		required bool
+	}{
+		{name: "proxy", image: c.EnvoyProxy, required: true},
+		{name: "gateway", image: c.EnvoyGateway, required: true},
+		{name: "rateLimit", image: c.Ratelimit},
+		{name: "shutdownManager", image: c.ShutdownManager},
+	}
+	for _, img := range images {
+		switch {
+		case img.image == "" && img.required:
+			allErrs = append(allErrs, field.Required(fldPath.Child(img.name), "must be set when digests are required"))
+		case img.image != "" && !imageDigestRegexp.MatchString(img.image):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(img.name), img.image, "must be pinned by a sha256 digest when digests are required"))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the ProxyConfig.
+func (c *ProxyConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.ProviderType == egv1a1.EnvoyProxyProviderTypeHost {
+		if c.Replicas != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("replicas"), "must not be set when using the Host provider"))
+		}
+		if c.PodDisruptionBudget != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("podDisruptionBudget"), "must not be set when using the Host provider"))
+		}
+		if c.PriorityClassName != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("priorityClassName"), "must not be set when using the Host provider"))
+		}
+		if c.ServiceAccount != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("serviceAccount"), "must not be set when using the Host provider"))
+		}
+		if c.Service != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("service"), "must not be set when using the Host provider"))
+		}
+	}
+	if c.Concurrency != nil && *c.Concurrency < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("concurrency"), *c.Concurrency, "must be positive"))
+	}
+	if c.PriorityClassName != nil {
+		if *c.PriorityClassName == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("priorityClassName"), *c.PriorityClassName, "must not be empty"))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(*c.PriorityClassName) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("priorityClassName"), *c.PriorityClassName, msg))
+			}
+		}
+	}
+	if c.AccessLog != nil {
+		allErrs = append(allErrs, c.AccessLog.Validate(fldPath.Child("accessLog"))...)
+	}
+	if c.Metrics != nil && c.Metrics.OpenTelemetryEndpoint != "" {
+		if _, _, err := parseEndpoint(c.Metrics.OpenTelemetryEndpoint); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("metrics", "openTelemetryEndpoint"), c.Metrics.OpenTelemetryEndpoint, err.Error()))
+		}
+	}
+	if c.Shutdown != nil {
+		allErrs = append(allErrs, c.Shutdown.Validate(fldPath.Child("shutdown"))...)
+	}
+	if c.Bootstrap != nil {
+		allErrs = append(allErrs, c.Bootstrap.Validate(fldPath.Child("bootstrap"))...)
+	}
+	if c.Logging != nil {
+		allErrs = append(allErrs, c.Logging.Validate(fldPath.Child("logging"))...)
+	}
+	if c.ServiceAccount != nil {
+		allErrs = append(allErrs, c.ServiceAccount.Validate(fldPath.Child("serviceAccount"))...)
+	}
+	if c.Service != nil {
+		allErrs = append(allErrs, c.Service.Validate(fldPath.Child("service"))...)
+	}
+
+	return allErrs
+}
+
+// Validate validates the ProxyServiceConfig.
+func (c *ProxyServiceConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.ExternalTrafficPolicy != "" && !slices.Contains(supportedExternalTrafficPolicies, c.ExternalTrafficPolicy) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("externalTrafficPolicy"), c.ExternalTrafficPolicy, supportedExternalTrafficPolicies))
+	}
+	if c.LoadBalancerClass != "" {
+		for _, msg := range validation.IsQualifiedName(c.LoadBalancerClass) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("loadBalancerClass"), c.LoadBalancerClass, msg))
+		}
+	}
+	if c.LoadBalancerIP != "" {
+		if net.ParseIP(c.LoadBalancerIP) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("loadBalancerIP"), c.LoadBalancerIP, "must be a valid IP address"))
+		}
+	}
+	for i, sourceRange := range c.LoadBalancerSourceRanges {
+		allErrs = append(allErrs, validation.IsValidCIDR(fldPath.Child("loadBalancerSourceRanges").Index(i), sourceRange)...)
+	}
+	names := map[string]bool{}
+	for i, port := range c.Ports {
+		idxPath := fldPath.Child("ports").Index(i)
+		for _, msg := range validation.IsDNS1123Label(port.Name) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), port.Name, msg))
+		}
+		if names[port.Name] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), port.Name))
+		}
+		names[port.Name] = true
+		for _, msg := range validation.IsValidPortNum(int(port.Port)) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), port.Port, msg))
+		}
+		if port.Listener == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("listener"), ""))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the ProxyServiceAccountConfig.
+func (c *ProxyServiceAccountConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(c.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), c.Name, msg))
+		}
+	}
+	for key := range c.Annotations {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("annotations").Key(key), key, msg))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the ProxyShutdownConfig.
+func (c *ProxyShutdownConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.DrainTimeout != nil && c.DrainTimeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("drainTimeout"), c.DrainTimeout.Duration.String(), "must not be negative"))
+	}
+	if c.MinDrainDuration != nil && c.MinDrainDuration.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minDrainDuration"), c.MinDrainDuration.Duration.String(), "must not be negative"))
+	}
+	if c.DrainTimeout != nil && c.MinDrainDuration != nil && c.MinDrainDuration.Duration > c.DrainTimeout.Duration {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minDrainDuration"), c.MinDrainDuration.Duration.String(), "must not exceed drainTimeout"))
+	}
+	return allErrs
+}
+
+// Validate validates the ProxyBootstrapConfig.
+func (c *ProxyBootstrapConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.Value == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("value"), ""))
+	}
+	switch c.Type {
+	case egv1a1.BootstrapTypeMerge, egv1a1.BootstrapTypeReplace:
+		if c.Value != "" {
+			bootstrap := map[string]any{}
+			if err := yaml.Unmarshal([]byte(c.Value), &bootstrap); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), c.Value, fmt.Sprintf("must be a YAML or JSON object: %v", err)))
+			}
+		}
+	case egv1a1.BootstrapTypeJSONPatch:
+		if c.Value != "" {
+			if _, err := ParseBootstrapJSONPatches(c.Value); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), c.Value, fmt.Sprintf("must be a list of JSON patch operations: %v", err)))
+			}
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), c.Type,
+			[]egv1a1.BootstrapType{egv1a1.BootstrapTypeMerge, egv1a1.BootstrapTypeReplace, egv1a1.BootstrapTypeJSONPatch}))
+	}
+	return allErrs
+}
+
+// ParseBootstrapJSONPatches parses the value of a ProxyBootstrapConfig of type JSONPatch.
+func ParseBootstrapJSONPatches(value string) ([]egv1a1.JSONPatchOperation, error) {
+	var patches []egv1a1.JSONPatchOperation
+	if err := yaml.UnmarshalStrict([]byte(value), &patches); err != nil {
+		return nil, err
+	}
+	for i, patch := range patches {
+		if patch.Op == "" {
+			return nil, fmt.Errorf("operation %d: op must be set", i)
+		}
+	}
+	return patches, nil
+}
+
+// Validate validates the ProxyLoggingConfig.
+func (c *ProxyLoggingConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.Level != "" && !slices.Contains(supportedProxyLogLevels, c.Level) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("level"), c.Level, supportedProxyLogLevels))
+	}
+	components := slices.Sorted(maps.Keys(c.Components))
+	for _, component := range components {
+		if !slices.Contains(supportedProxyLogComponents, component) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("components"), component, supportedProxyLogComponents))
+			continue
+		}
+		if level := c.Components[component]; !slices.Contains(supportedProxyLogLevels, level) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("components").Key(string(component)), level, supportedProxyLogLevels))
+		}
+	}
+	return allErrs
+}
+
+// Validate validates the AccessLogConfig.
+func (c *AccessLogConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(c.JSONFields) > 0 && c.Format != egv1a1.ProxyAccessLogFormatTypeJSON {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("jsonFields"), "must only be set for the JSON format"))
+	}
+	for i, sink := range c.Sinks {
+		sinkPath := fldPath.Child("sinks").Index(i)
+		switch sink.Type {
+		case egv1a1.ProxyAccessLogSinkTypeFile:
+			if sink.Endpoint != "" {
+				allErrs = append(allErrs, field.Forbidden(sinkPath.Child("endpoint"), "must not be set for the File sink"))
+			}
+		case egv1a1.ProxyAccessLogSinkTypeOpenTelemetry:
+			if sink.Path != "" {
+				allErrs = append(allErrs, field.Forbidden(sinkPath.Child("path"), "must not be set for the OpenTelemetry sink"))
+			}
+			if sink.Endpoint == "" {
+				allErrs = append(allErrs, field.Required(sinkPath.Child("endpoint"), "must be set for the OpenTelemetry sink"))
+			} else if _, _, err := parseEndpoint(sink.Endpoint); err != nil {
+				allErrs = append(allErrs, field.Invalid(sinkPath.Child("endpoint"), sink.Endpoint, err.Error()))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// Validate validates the EnvoyGatewayChart.
+func (c *EnvoyGatewayChart) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.VersionFrom != nil {
+		if c.VersionFrom.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("versionFrom", "name"), "must not be empty"))
+		}
+		if c.VersionFrom.Key == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("versionFrom", "key"), "must not be empty"))
+		}
+	}
+	if c.DriftDetection != nil {
+		allErrs = append(allErrs, c.DriftDetection.Validate(fldPath.Child("driftDetection"))...)
+	}
+	if len(c.LayerMediaTypes) > 0 && c.LayerSelector != nil && (c.LayerSelector.Disabled || c.LayerSelector.MediaType != "") {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("layerMediaTypes"), "must not be set together with a disabled layer selector or its media type"))
+	}
+	mediaTypes := map[string]bool{}
+	for i, mediaType := range c.LayerMediaTypes {
+		if mediaType == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("layerMediaTypes").Index(i), ""))
+		} else if mediaTypes[mediaType] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("layerMediaTypes").Index(i), mediaType))
+		}
+		mediaTypes[mediaType] = true
+	}
+	switch {
+	case strings.TrimSpace(c.Tag) == "" && c.VersionFrom == nil:
+		allErrs = append(allErrs, field.Required(fldPath.Child("tag"), "must not be empty"))
+		return allErrs
+	case c.Tag != "" && !ociTagRegexp.MatchString(c.Tag):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("tag"), c.Tag, "must be a valid OCI tag"))
+		return allErrs
+	}
+
+	switch c.Type {
+	case "", ChartTypeOCI:
+		if c.URL != "" && !strings.HasPrefix(c.URL, "oci://") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), c.URL, "must use the oci:// scheme for the oci type"))
+		}
+	case ChartTypeHTTP:
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), c.URL, "must use the http:// or https:// scheme for the http type"))
+		}
+		if c.LayerSelector != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("layerSelector"), "must not be set for the http type"))
+		}
+		if len(c.LayerMediaTypes) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("layerMediaTypes"), "must not be set for the http type"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), c.Type, []ChartType{ChartTypeOCI, ChartTypeHTTP}))
+	}
+
+	if c.Tag == "" {
+		// the tag is read from VersionFrom and validated once it has been resolved
+		return allErrs
+	}
+
+	if len(c.AllowedTags) > 0 && !slices.Contains(c.AllowedTags, c.Tag) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("tag"), c.Tag, c.AllowedTags))
+	}
+
+	if c.VersionConstraint != "" {
+		constraint, err := semver.NewConstraint(c.VersionConstraint)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("versionConstraint"), c.VersionConstraint, fmt.Sprintf("invalid semver constraint: %s", err)))
+		} else if version, err := semver.NewVersion(c.Tag); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tag"), c.Tag, fmt.Sprintf("tag is not a semantic version: %s", err)))
+		} else if !constraint.Check(version) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tag"), c.Tag, fmt.Sprintf("tag does not satisfy version constraint %q", c.VersionConstraint)))
+		}
+	}
+
+	return allErrs
+}
+
+// Validate validates the DriftDetectionConfig.
+func (c *DriftDetectionConfig) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.Mode != "" && !slices.Contains(supportedDriftDetectionModes, c.Mode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), c.Mode, supportedDriftDetectionModes))
+	}
+	for i, path := range c.IgnorePaths {
+		if !strings.HasPrefix(path, "/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ignorePaths").Index(i), path, "must be a JSON Pointer starting with /"))
+		}
+	}
+
+	return allErrs
+}
+
+// parseEndpoint splits an endpoint in the form "host:port" into host and port.
+func parseEndpoint(endpoint string) (string, int32, error) {
+	host, portStr, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port: %s", portStr)
+	}
+	return host, int32(port), nil
+}
@@ -0,0 +1,1509 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestEnvoyGatewayChart_Validate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		chart       EnvoyGatewayChart
+		expectedErr string
+	}{
+		{
+			desc: "should accept any tag without restrictions",
+			chart: EnvoyGatewayChart{
+				Tag: "1.5.4",
+			},
+		},
+		{
+			desc: "should reject empty tag",
+			chart: EnvoyGatewayChart{
+				Tag: " ",
+			},
+			expectedErr: "chart.tag: Required value: must not be empty",
+		},
+		{
+			desc: "should accept tag from ConfigMap",
+			chart: EnvoyGatewayChart{
+				VersionFrom:       &ConfigMapKeyReference{Name: "envoy-gateway-version", Key: "tag"},
+				AllowedTags:       []string{"1.5.4"},
+				VersionConstraint: ">= 1.5.0",
+			},
+		},
+		{
+			desc: "should reject incomplete ConfigMap reference",
+			chart: EnvoyGatewayChart{
+				VersionFrom: &ConfigMapKeyReference{Name: "envoy-gateway-version"},
+			},
+			expectedErr: "chart.versionFrom.key: Required value: must not be empty",
+		},
+		{
+			desc: "should reject malformed tag",
+			chart: EnvoyGatewayChart{
+				Tag: "1.5.4 ",
+			},
+			expectedErr: `chart.tag: Invalid value: "1.5.4 ": must be a valid OCI tag`,
+		},
+		{
+			desc: "should accept tag with leading v",
+			chart: EnvoyGatewayChart{
+				Tag:               "v1.5.4",
+				VersionConstraint: ">= 1.5.0",
+			},
+		},
+		{
+			desc: "should accept OCI chart",
+			chart: EnvoyGatewayChart{
+				Type: ChartTypeOCI,
+				URL:  "oci://docker.io/envoyproxy/gateway-helm",
+				Tag:  "1.5.4",
+			},
+		},
+		{
+			desc: "should reject OCI chart with HTTP URL",
+			chart: EnvoyGatewayChart{
+				URL: "https://charts.example.com",
+				Tag: "1.5.4",
+			},
+			expectedErr: `chart.url: Invalid value: "https://charts.example.com": must use the oci:// scheme for the oci type`,
+		},
+		{
+			desc: "should accept HTTP chart",
+			chart: EnvoyGatewayChart{
+				Type: ChartTypeHTTP,
+				URL:  "https://charts.example.com",
+				Name: "gateway-helm",
+				Tag:  "1.5.4",
+			},
+		},
+		{
+			desc: "should reject HTTP chart with OCI URL",
+			chart: EnvoyGatewayChart{
+				Type: ChartTypeHTTP,
+				URL:  "oci://docker.io/envoyproxy/gateway-helm",
+				Tag:  "1.5.4",
+			},
+			expectedErr: `chart.url: Invalid value: "oci://docker.io/envoyproxy/gateway-helm": must use the http:// or https:// scheme for the http type`,
+		},
+		{
+			desc: "should reject layer selector for HTTP chart",
+			chart: EnvoyGatewayChart{
+				Type:          ChartTypeHTTP,
+				URL:           "https://charts.example.com",
+				Tag:           "1.5.4",
+				LayerSelector: &LayerSelectorConfig{Disabled: true},
+			},
+			expectedErr: "chart.layerSelector: Forbidden: must not be set for the http type",
+		},
+		{
+			desc: "should accept layer media types",
+			chart: EnvoyGatewayChart{
+				Tag:             "1.5.4",
+				LayerMediaTypes: []string{"application/vnd.cncf.helm.chart.content.v1.tar+gzip", "application/tar+gzip"},
+				LayerSelector:   &LayerSelectorConfig{Operation: "extract"},
+			},
+		},
+		{
+			desc: "should reject duplicate layer media types",
+			chart: EnvoyGatewayChart{
+				Tag:             "1.5.4",
+				LayerMediaTypes: []string{"application/tar+gzip", "application/tar+gzip"},
+			},
+			expectedErr: `chart.layerMediaTypes[1]: Duplicate value: "application/tar+gzip"`,
+		},
+		{
+			desc: "should reject layer media types together with the media type of the layer selector",
+			chart: EnvoyGatewayChart{
+				Tag:             "1.5.4",
+				LayerMediaTypes: []string{"application/tar+gzip"},
+				LayerSelector:   &LayerSelectorConfig{MediaType: "application/tar+gzip"},
+			},
+			expectedErr: "chart.layerMediaTypes: Forbidden: must not be set together with a disabled layer selector or its media type",
+		},
+		{
+			desc: "should reject layer media types for HTTP chart",
+			chart: EnvoyGatewayChart{
+				Type:            ChartTypeHTTP,
+				URL:             "https://charts.example.com",
+				Tag:             "1.5.4",
+				LayerMediaTypes: []string{"application/tar+gzip"},
+			},
+			expectedErr: "chart.layerMediaTypes: Forbidden: must not be set for the http type",
+		},
+		{
+			desc: "should accept drift detection",
+			chart: EnvoyGatewayChart{
+				Tag: "1.5.4",
+				DriftDetection: &DriftDetectionConfig{
+					Mode:        "warn",
+					IgnorePaths: []string{"/spec/replicas"},
+				},
+			},
+		},
+		{
+			desc: "should reject unknown drift detection mode",
+			chart: EnvoyGatewayChart{
+				Tag:            "1.5.4",
+				DriftDetection: &DriftDetectionConfig{Mode: "strict"},
+			},
+			expectedErr: `chart.driftDetection.mode: Unsupported value: "strict": supported values: "enabled", "warn", "disabled"`,
+		},
+		{
+			desc: "should reject relative drift detection ignore path",
+			chart: EnvoyGatewayChart{
+				Tag:            "1.5.4",
+				DriftDetection: &DriftDetectionConfig{IgnorePaths: []string{"spec/replicas"}},
+			},
+			expectedErr: `chart.driftDetection.ignorePaths[0]: Invalid value: "spec/replicas": must be a JSON Pointer starting with /`,
+		},
+		{
+			desc: "should accept allowed tag",
+			chart: EnvoyGatewayChart{
+				Tag:         "1.5.4",
+				AllowedTags: []string{"1.5.3", "1.5.4"},
+			},
+		},
+		{
+			desc: "should reject disallowed tag",
+			chart: EnvoyGatewayChart{
+				Tag:         "1.6.0",
+				AllowedTags: []string{"1.5.3", "1.5.4"},
+			},
+			expectedErr: `chart.tag: Unsupported value: "1.6.0": supported values: "1.5.3", "1.5.4"`,
+		},
+		{
+			desc: "should accept tag matching version constraint",
+			chart: EnvoyGatewayChart{
+				Tag:               "1.5.4",
+				VersionConstraint: ">= 1.5.0, < 1.7.0",
+			},
+		},
+		{
+			desc: "should reject tag not matching version constraint",
+			chart: EnvoyGatewayChart{
+				Tag:               "1.7.1",
+				VersionConstraint: ">= 1.5.0, < 1.7.0",
+			},
+			expectedErr: `chart.tag: Invalid value: "1.7.1": tag does not satisfy version constraint ">= 1.5.0, < 1.7.0"`,
+		},
+		{
+			desc: "should reject non-semver tag when version constraint is set",
+			chart: EnvoyGatewayChart{
+				Tag:               "latest",
+				VersionConstraint: ">= 1.5.0",
+			},
+			expectedErr: `chart.tag: Invalid value: "latest": tag is not a semantic version`,
+		},
+		{
+			desc: "should reject invalid version constraint",
+			chart: EnvoyGatewayChart{
+				Tag:               "1.5.4",
+				VersionConstraint: "foo",
+			},
+			expectedErr: `chart.versionConstraint: Invalid value: "foo": invalid semver constraint`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.chart.Validate(field.NewPath("chart"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Contains(t, errs[0].Error(), tC.expectedErr)
+			}
+		})
+	}
+}
+
+func TestProxyConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		proxy        ProxyConfig
+		expectedErrs []string
+	}{
+		{
+			desc: "should accept empty config",
+		},
+		{
+			desc: "should accept kubernetes options with the Kubernetes provider",
+			proxy: ProxyConfig{
+				ProviderType:        egv1a1.EnvoyProxyProviderTypeKubernetes,
+				Replicas:            ptr.To[int32](2),
+				PodDisruptionBudget: &PodDisruptionBudgetConfig{},
+			},
+		},
+		{
+			desc: "should accept the Host provider without kubernetes options",
+			proxy: ProxyConfig{
+				ProviderType: egv1a1.EnvoyProxyProviderTypeHost,
+			},
+		},
+		{
+			desc: "should reject kubernetes options with the Host provider",
+			proxy: ProxyConfig{
+				ProviderType:        egv1a1.EnvoyProxyProviderTypeHost,
+				Replicas:            ptr.To[int32](2),
+				PodDisruptionBudget: &PodDisruptionBudgetConfig{},
+			},
+			expectedErrs: []string{
+				"proxy.replicas: Forbidden: must not be set when using the Host provider",
+				"proxy.podDisruptionBudget: Forbidden: must not be set when using the Host provider",
+			},
+		},
+		{
+			desc: "should accept a concurrency",
+			proxy: ProxyConfig{
+				Concurrency: ptr.To[int32](4),
+			},
+		},
+		{
+			desc: "should reject a concurrency of zero",
+			proxy: ProxyConfig{
+				Concurrency: ptr.To[int32](0),
+			},
+			expectedErrs: []string{
+				"proxy.concurrency: Invalid value: 0: must be positive",
+			},
+		},
+		{
+			desc: "should accept a priority class",
+			proxy: ProxyConfig{
+				PriorityClassName: ptr.To("system-cluster-critical"),
+			},
+		},
+		{
+			desc: "should reject an empty priority class",
+			proxy: ProxyConfig{
+				PriorityClassName: ptr.To(""),
+			},
+			expectedErrs: []string{
+				`proxy.priorityClassName: Invalid value: "": must not be empty`,
+			},
+		},
+		{
+			desc: "should reject a priority class with the Host provider",
+			proxy: ProxyConfig{
+				ProviderType:      egv1a1.EnvoyProxyProviderTypeHost,
+				PriorityClassName: ptr.To("system-cluster-critical"),
+			},
+			expectedErrs: []string{
+				"proxy.priorityClassName: Forbidden: must not be set when using the Host provider",
+			},
+		},
+		{
+			desc: "should accept a service account",
+			proxy: ProxyConfig{
+				ServiceAccount: &ProxyServiceAccountConfig{
+					Name:        "envoy-proxy",
+					Annotations: map[string]string{"iam.gke.io/gcp-service-account": "envoy@example.iam.gserviceaccount.com"},
+				},
+			},
+		},
+		{
+			desc: "should reject an invalid service account",
+			proxy: ProxyConfig{
+				ServiceAccount: &ProxyServiceAccountConfig{
+					Name:        "Envoy_Proxy",
+					Annotations: map[string]string{"invalid key": "foo"},
+				},
+			},
+			expectedErrs: []string{
+				`proxy.serviceAccount.name: Invalid value: "Envoy_Proxy": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+				`proxy.serviceAccount.annotations[invalid key]: Invalid value: "invalid key": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
+			},
+		},
+		{
+			desc: "should accept an external traffic policy",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{ExternalTrafficPolicy: egv1a1.ServiceExternalTrafficPolicyLocal},
+			},
+		},
+		{
+			desc: "should reject an unsupported external traffic policy",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{ExternalTrafficPolicy: "Global"},
+			},
+			expectedErrs: []string{
+				`proxy.service.externalTrafficPolicy: Unsupported value: "Global": supported values: "Local", "Cluster"`,
+			},
+		},
+		{
+			desc: "should accept load balancer options",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{
+					LoadBalancerClass:        "example.com/internal",
+					LoadBalancerIP:           "203.0.113.10",
+					LoadBalancerSourceRanges: []string{"10.0.0.0/8", "2001:db8::/32"},
+				},
+			},
+		},
+		{
+			desc: "should accept an IPv6 load balancer IP",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{
+					LoadBalancerIP: "2001:db8::1",
+				},
+			},
+		},
+		{
+			desc: "should reject invalid load balancer options",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{
+					LoadBalancerClass:        "internal lb",
+					LoadBalancerIP:           "203.0.113.300",
+					LoadBalancerSourceRanges: []string{"10.0.0.0"},
+				},
+			},
+			expectedErrs: []string{
+				`proxy.service.loadBalancerClass: Invalid value: "internal lb": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
+				`proxy.service.loadBalancerIP: Invalid value: "203.0.113.300": must be a valid IP address`,
+				`proxy.service.loadBalancerSourceRanges[0]: Invalid value: "10.0.0.0": must be a valid CIDR value, (e.g. 10.9.8.0/24 or 2001:db8::/64)`,
+			},
+		},
+		{
+			desc: "should accept additional service ports",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{
+					Ports: []ProxyServicePort{{Name: "https", Port: 443, Listener: "tls"}},
+				},
+			},
+		},
+		{
+			desc: "should reject invalid service ports",
+			proxy: ProxyConfig{
+				Service: &ProxyServiceConfig{
+					Ports: []ProxyServicePort{
+						{Name: "https", Port: 443, Listener: "tls"},
+						{Name: "https", Port: 0},
+					},
+				},
+			},
+			expectedErrs: []string{
+				`proxy.service.ports[1].name: Duplicate value: "https"`,
+				`proxy.service.ports[1].port: Invalid value: 0: must be between 1 and 65535, inclusive`,
+				`proxy.service.ports[1].listener: Required value`,
+			},
+		},
+		{
+			desc: "should reject service options with the Host provider",
+			proxy: ProxyConfig{
+				ProviderType: egv1a1.EnvoyProxyProviderTypeHost,
+				Service:      &ProxyServiceConfig{ExternalTrafficPolicy: egv1a1.ServiceExternalTrafficPolicyLocal},
+			},
+			expectedErrs: []string{
+				"proxy.service: Forbidden: must not be set when using the Host provider",
+			},
+		},
+		{
+			desc: "should reject a service account with the Host provider",
+			proxy: ProxyConfig{
+				ProviderType:   egv1a1.EnvoyProxyProviderTypeHost,
+				ServiceAccount: &ProxyServiceAccountConfig{Name: "envoy-proxy"},
+			},
+			expectedErrs: []string{
+				"proxy.serviceAccount: Forbidden: must not be set when using the Host provider",
+			},
+		},
+		{
+			desc: "should accept metrics with an OTLP endpoint",
+			proxy: ProxyConfig{
+				Metrics: &ProxyMetricsConfig{
+					Prometheus:            &PrometheusMetricsConfig{Compression: egv1a1.GzipCompressorType},
+					OpenTelemetryEndpoint: "otel-collector:4317",
+				},
+			},
+		},
+		{
+			desc: "should reject invalid metrics OTLP endpoint",
+			proxy: ProxyConfig{
+				Metrics: &ProxyMetricsConfig{
+					OpenTelemetryEndpoint: "otel-collector",
+				},
+			},
+			expectedErrs: []string{
+				`proxy.metrics.openTelemetryEndpoint: Invalid value: "otel-collector": address otel-collector: missing port in address`,
+			},
+		},
+		{
+			desc: "should accept shutdown config",
+			proxy: ProxyConfig{
+				Shutdown: &ProxyShutdownConfig{
+					DrainTimeout:     &metav1.Duration{Duration: time.Minute},
+					MinDrainDuration: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+		},
+		{
+			desc: "should reject invalid shutdown config",
+			proxy: ProxyConfig{
+				Shutdown: &ProxyShutdownConfig{
+					DrainTimeout:     &metav1.Duration{Duration: 10 * time.Second},
+					MinDrainDuration: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			expectedErrs: []string{
+				`proxy.shutdown.minDrainDuration: Invalid value: "1m0s": must not exceed drainTimeout`,
+			},
+		},
+		{
+			desc: "should reject negative shutdown durations",
+			proxy: ProxyConfig{
+				Shutdown: &ProxyShutdownConfig{
+					DrainTimeout:     &metav1.Duration{Duration: -time.Minute},
+					MinDrainDuration: &metav1.Duration{Duration: -time.Minute},
+				},
+			},
+			expectedErrs: []string{
+				`proxy.shutdown.drainTimeout: Invalid value: "-1m0s": must not be negative`,
+				`proxy.shutdown.minDrainDuration: Invalid value: "-1m0s": must not be negative`,
+			},
+		},
+		{
+			desc: "should accept merge bootstrap",
+			proxy: ProxyConfig{
+				Bootstrap: &ProxyBootstrapConfig{
+					Type:  egv1a1.BootstrapTypeMerge,
+					Value: "admin:\n  address:\n    socket_address:\n      port_value: 19001\n",
+				},
+			},
+		},
+		{
+			desc: "should accept JSON patch bootstrap",
+			proxy: ProxyConfig{
+				Bootstrap: &ProxyBootstrapConfig{
+					Type:  egv1a1.BootstrapTypeJSONPatch,
+					Value: `[{"op": "add", "path": "/static_resources/clusters/-", "value": {"name": "static"}}]`,
+				},
+			},
+		},
+		{
+			desc: "should reject unsupported bootstrap type",
+			proxy: ProxyConfig{
+				Bootstrap: &ProxyBootstrapConfig{
+					Type:  "Strategic",
+					Value: "{}",
+				},
+			},
+			expectedErrs: []string{
+				`proxy.bootstrap.type: Unsupported value: "Strategic": supported values: "Merge", "Replace", "JSONPatch"`,
+			},
+		},
+		{
+			desc: "should reject bootstrap without value",
+			proxy: ProxyConfig{
+				Bootstrap: &ProxyBootstrapConfig{
+					Type: egv1a1.BootstrapTypeReplace,
+				},
+			},
+			expectedErrs: []string{
+				`proxy.bootstrap.value: Required value`,
+			},
+		},
+		{
+			desc: "should reject bootstrap value which is not an object",
+			proxy: ProxyConfig{
+				Bootstrap: &ProxyBootstrapConfig{
+					Type:  egv1a1.BootstrapTypeMerge,
+					Value: "- foo",
+				},
+			},
+			expectedErrs: []string{
+				`proxy.bootstrap.value: Invalid value: "- foo": must be a YAML or JSON object: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal array into Go value of type map[string]interface {}`,
+			},
+		},
+		{
+			desc: "should accept proxy logging",
+			proxy: ProxyConfig{
+				Logging: &ProxyLoggingConfig{
+					Level: egv1a1.LogLevelWarn,
+					Components: map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{
+						egv1a1.LogComponentUpstream: egv1a1.LogLevelTrace,
+						"system":                    egv1a1.LogLevelDebug,
+					},
+				},
+			},
+		},
+		{
+			desc: "should reject invalid proxy logging",
+			proxy: ProxyConfig{
+				Logging: &ProxyLoggingConfig{
+					Level: "verbose",
+					Components: map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{
+						egv1a1.LogComponentDefault: egv1a1.LogLevelInfo,
+						egv1a1.LogComponentHTTP:    "verbose",
+					},
+				},
+			},
+			expectedErrs: []string{
+				`proxy.logging.level: Unsupported value: "verbose": supported values: "trace", "debug", "info", "warn", "error"`,
+				`proxy.logging.components: Unsupported value: "default": supported values: "system", "upstream", "http", "connection", "admin", "client", "filter", "main", "router", "runtime"`,
+				`proxy.logging.components[http]: Unsupported value: "verbose": supported values: "trace", "debug", "info", "warn", "error"`,
+			},
+		},
+		{
+			desc: "should reject invalid JSON patches",
+			proxy: ProxyConfig{
+				Bootstrap: &ProxyBootstrapConfig{
+					Type:  egv1a1.BootstrapTypeJSONPatch,
+					Value: `[{"path": "/admin"}]`,
+				},
+			},
+			expectedErrs: []string{
+				`proxy.bootstrap.value: Invalid value: "[{\"path\": \"/admin\"}]": must be a list of JSON patch operations: operation 0: op must be set`,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.proxy.Validate(field.NewPath("proxy"))
+			if assert.Len(t, errs, len(tC.expectedErrs)) {
+				for i, expected := range tC.expectedErrs {
+					assert.Equal(t, expected, errs[i].Error())
+				}
+			}
+		})
+	}
+}
+
+func TestCleanupConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		cleanup     CleanupConfig
+		expectedErr string
+	}{
+		{
+			desc: "should accept empty config",
+		},
+		{
+			desc: "should accept positive drain timeout",
+			cleanup: CleanupConfig{
+				DrainTimeout: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+		{
+			desc: "should reject negative drain timeout",
+			cleanup: CleanupConfig{
+				DrainTimeout: &metav1.Duration{Duration: -time.Minute},
+			},
+			expectedErr: `cleanup.drainTimeout: Invalid value: "-1m0s": must not be negative`,
+		},
+		{
+			desc: "should accept foreground deletion and retry interval",
+			cleanup: CleanupConfig{
+				PropagationPolicy: ptr.To(metav1.DeletePropagationForeground),
+				RetryInterval:     &metav1.Duration{Duration: 30 * time.Second},
+			},
+		},
+		{
+			desc: "should reject orphan propagation policy",
+			cleanup: CleanupConfig{
+				PropagationPolicy: ptr.To(metav1.DeletePropagationOrphan),
+			},
+			expectedErr: `cleanup.propagationPolicy: Unsupported value: "Orphan": supported values: "Foreground", "Background"`,
+		},
+		{
+			desc: "should reject zero retry interval",
+			cleanup: CleanupConfig{
+				RetryInterval: &metav1.Duration{},
+			},
+			expectedErr: `cleanup.retryInterval: Invalid value: "0s": must be positive`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.cleanup.Validate(field.NewPath("cleanup"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tC.expectedErr, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestClusterAccessConfig_Validate(t *testing.T) {
+	config := ClusterAccessConfig{
+		Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	assert.Empty(t, config.Validate(field.NewPath("clusterAccess")))
+
+	config.Timeout = &metav1.Duration{}
+	errs := config.Validate(field.NewPath("clusterAccess"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `clusterAccess.timeout: Invalid value: "0s": must be positive`, errs[0].Error())
+	}
+}
+
+func TestGatewayServiceConfigSpec_Validate_createNamespaces(t *testing.T) {
+	spec := GatewayServiceConfigSpec{
+		CreateNamespaces: ptr.To(false),
+		EnvoyGateway: EnvoyGatewayConfig{
+			DeploymentNamespaceLabels: map[string]string{"example.com/team": "gateway"},
+		},
+	}
+	err := spec.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "spec.envoyGateway.deploymentNamespaceLabels: Forbidden: must not be set when namespaces are not created")
+	}
+
+	spec.CreateNamespaces = nil
+	err = spec.Validate()
+	if err != nil {
+		assert.NotContains(t, err.Error(), "deploymentNamespaceLabels")
+	}
+}
+
+func TestGatewayServiceConfigSpec_Validate_servicePortListeners(t *testing.T) {
+	spec := GatewayServiceConfigSpec{
+		EnvoyGateway: EnvoyGatewayConfig{
+			Proxy: &ProxyConfig{
+				Service: &ProxyServiceConfig{
+					Ports: []ProxyServicePort{{Name: "https", Port: 443, Listener: "tls"}},
+				},
+			},
+		},
+	}
+	err := spec.Validate()
+	if err != nil {
+		assert.NotContains(t, err.Error(), "listener")
+	}
+
+	spec.Gateway = &GatewayConfig{
+		Listeners: []ListenerConfig{{Name: "web", Port: 8080, Protocol: gatewayv1.HTTPProtocolType}},
+	}
+	err = spec.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `spec.envoyGateway.proxy.service.ports[0].listener: Not found: "tls"`)
+	}
+}
+
+func TestGatewayServiceConfigSpec_Validate_servicePortConflicts(t *testing.T) {
+	spec := GatewayServiceConfigSpec{
+		EnvoyGateway: EnvoyGatewayConfig{
+			Proxy: &ProxyConfig{
+				Service: &ProxyServiceConfig{
+					Ports: []ProxyServicePort{{Name: "https", Port: 9443, Listener: "tls"}},
+				},
+			},
+		},
+	}
+	err := spec.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `spec.envoyGateway.proxy.service.ports[0].port: Invalid value: 9443: must not be the port of listener "tls"`)
+	}
+
+	spec.Gateway = &GatewayConfig{
+		Listeners: []ListenerConfig{
+			{Name: "web", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+			{Name: "web-alt", Port: 8080, Protocol: gatewayv1.HTTPProtocolType},
+		},
+	}
+	spec.EnvoyGateway.Proxy.Service.Ports = []ProxyServicePort{{Name: "http-alt", Port: 8080, Listener: "web"}}
+	err = spec.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `spec.envoyGateway.proxy.service.ports[0].port: Invalid value: 8080: must not be the port of listener "web-alt"`)
+	}
+
+	spec.EnvoyGateway.Proxy.Service.Ports[0].Port = 8081
+	err = spec.Validate()
+	if err != nil {
+		assert.NotContains(t, err.Error(), "ports[0].port")
+	}
+}
+
+func TestClusterTerm_Validate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		term        ClusterTerm
+		expectedErr string
+	}{
+		{
+			desc: "should accept selector",
+			term: ClusterTerm{Selector: &ClusterSelector{MatchPurpose: "platform"}},
+		},
+		{
+			desc: "should accept selector with different exclude selector",
+			term: ClusterTerm{
+				Selector:        &ClusterSelector{MatchPurpose: "platform"},
+				ExcludeSelector: &ClusterSelector{MatchLabels: map[string]string{"gateway": "false"}},
+			},
+		},
+		{
+			desc:        "should reject empty term",
+			term:        ClusterTerm{},
+			expectedErr: "clusters[0]: Required value: one of selector, clusterRef, clusterRefs or excludeSelector must be set",
+		},
+		{
+			desc:        "should reject cluster reference without name",
+			term:        ClusterTerm{ClusterRefs: []ClusterRef{{Namespace: "default"}}},
+			expectedErr: "clusters[0].clusterRefs[0].name: Required value: must not be empty",
+		},
+		{
+			desc: "should reject exclude selector equal to selector",
+			term: ClusterTerm{
+				Selector:        &ClusterSelector{MatchPurpose: "platform"},
+				ExcludeSelector: &ClusterSelector{MatchPurpose: "platform"},
+			},
+			expectedErr: "clusters[0].excludeSelector: Invalid value:",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.term.Validate(field.NewPath("clusters").Index(0))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Contains(t, errs[0].Error(), tC.expectedErr)
+			}
+		})
+	}
+}
+
+func TestDNSConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		dns         DNSConfig
+		expectedErr string
+	}{
+		{
+			desc: "should accept config without additional base domains",
+			dns:  DNSConfig{BaseDomain: "example.com"},
+		},
+		{
+			desc: "should accept additional base domains",
+			dns:  DNSConfig{BaseDomain: "example.com", AdditionalBaseDomains: []string{"legacy.example.com", "example.org"}},
+		},
+		{
+			desc: "should accept subdomain template",
+			dns:  DNSConfig{BaseDomain: "example.com", SubdomainTemplate: `{{ index .Cluster.Labels "team" }}.{{ .Cluster.Name }}`},
+		},
+		{
+			desc:        "should reject unparsable subdomain template",
+			dns:         DNSConfig{BaseDomain: "example.com", SubdomainTemplate: "{{ .Cluster.Name"},
+			expectedErr: "dns.subdomainTemplate: Invalid value:",
+		},
+		{
+			desc:        "should reject empty base domain",
+			dns:         DNSConfig{},
+			expectedErr: "dns.baseDomain: Required value: must not be empty",
+		},
+		{
+			desc:        "should reject invalid additional base domain",
+			dns:         DNSConfig{BaseDomain: "example.com", AdditionalBaseDomains: []string{"Example_Org"}},
+			expectedErr: `dns.additionalBaseDomains[0]: Invalid value: "Example_Org": a lowercase RFC 1123 subdomain`,
+		},
+		{
+			desc:        "should reject additional base domain equal to base domain",
+			dns:         DNSConfig{BaseDomain: "example.com", AdditionalBaseDomains: []string{"example.org", "example.com"}},
+			expectedErr: `dns.additionalBaseDomains[1]: Duplicate value: "example.com"`,
+		},
+		{
+			desc: "should accept custom base domain annotation",
+			dns:  DNSConfig{BaseDomain: "example.com", BaseDomainAnnotation: "external-dns.alpha.kubernetes.io/hostname"},
+		},
+		{
+			desc:        "should reject invalid base domain annotation",
+			dns:         DNSConfig{BaseDomain: "example.com", BaseDomainAnnotation: "example.com/base domain"},
+			expectedErr: `dns.baseDomainAnnotation: Invalid value: "example.com/base domain": name part must consist of alphanumeric characters`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.dns.Validate(field.NewPath("dns"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Contains(t, errs[0].Error(), tC.expectedErr)
+			}
+		})
+	}
+}
+
+func TestAllowedRoutesConfig_Validate(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"gateway-access": "true"}}
+	testCases := []struct {
+		desc          string
+		allowedRoutes AllowedRoutesConfig
+		expectedErr   string
+	}{
+		{
+			desc:          "should accept routes from all namespaces",
+			allowedRoutes: AllowedRoutesConfig{From: gatewayv1.NamespacesFromAll},
+		},
+		{
+			desc:          "should accept routes from the same namespace",
+			allowedRoutes: AllowedRoutesConfig{From: gatewayv1.NamespacesFromSame},
+		},
+		{
+			desc:          "should accept selector",
+			allowedRoutes: AllowedRoutesConfig{From: gatewayv1.NamespacesFromSelector, Selector: selector},
+		},
+		{
+			desc:          "should reject missing selector",
+			allowedRoutes: AllowedRoutesConfig{From: gatewayv1.NamespacesFromSelector},
+			expectedErr:   "allowedRoutes.selector: Required value: must be set if from is Selector",
+		},
+		{
+			desc:          "should reject selector without from Selector",
+			allowedRoutes: AllowedRoutesConfig{From: gatewayv1.NamespacesFromSame, Selector: selector},
+			expectedErr:   "allowedRoutes.selector: Forbidden: must only be set if from is Selector",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.allowedRoutes.Validate(field.NewPath("allowedRoutes"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tC.expectedErr, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestListenerConfig_Validate(t *testing.T) {
+	certificateRefs := []gatewayv1.SecretObjectReference{{Name: "tls-cert"}}
+	testCases := []struct {
+		desc        string
+		listener    ListenerConfig
+		expectedErr string
+	}{
+		{
+			desc:     "should accept TLS passthrough listener",
+			listener: ListenerConfig{Name: "tls", Port: 9443},
+		},
+		{
+			desc:     "should accept HTTPS listener with certificates",
+			listener: ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, CertificateRefs: certificateRefs},
+		},
+		{
+			desc:     "should accept HTTP listener",
+			listener: ListenerConfig{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+		},
+		{
+			desc:     "should accept TCP listener",
+			listener: ListenerConfig{Name: "tcp", Port: 9443, Protocol: gatewayv1.TCPProtocolType},
+		},
+		{
+			desc:        "should reject TLS mode for TCP listener",
+			listener:    ListenerConfig{Name: "tcp", Port: 9443, Protocol: gatewayv1.TCPProtocolType, TLSMode: ptr.To(gatewayv1.TLSModePassthrough)},
+			expectedErr: "listeners[0].tlsMode: Forbidden: must only be set for protocols TLS and HTTPS",
+		},
+		{
+			desc:        "should reject invalid name",
+			listener:    ListenerConfig{Name: "TLS_Listener", Port: 9443},
+			expectedErr: `listeners[0].name: Invalid value: "TLS_Listener": a lowercase RFC 1123 subdomain`,
+		},
+		{
+			desc:        "should reject invalid port",
+			listener:    ListenerConfig{Name: "tls", Port: 0},
+			expectedErr: "listeners[0].port: Invalid value: 0: must be between 1 and 65535, inclusive",
+		},
+		{
+			desc:        "should reject TLS termination without certificates",
+			listener:    ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType},
+			expectedErr: "listeners[0].certificateRefs: Required value: must be set if tlsMode is Terminate",
+		},
+		{
+			desc:        "should reject HTTPS passthrough",
+			listener:    ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, TLSMode: ptr.To(gatewayv1.TLSModePassthrough)},
+			expectedErr: `listeners[0].tlsMode: Invalid value: "Passthrough": must be Terminate for protocol HTTPS`,
+		},
+		{
+			desc:        "should reject certificates for passthrough",
+			listener:    ListenerConfig{Name: "tls", Port: 9443, CertificateRefs: certificateRefs},
+			expectedErr: "listeners[0].certificateRefs: Forbidden: must only be set if tlsMode is Terminate",
+		},
+		{
+			desc: "should accept hostname certificates for TLS termination",
+			listener: ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, CertificateRefs: certificateRefs, HostnameCertificateRefs: []HostnameCertificateRefs{
+				{Hostname: "api.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}},
+				{Hostname: "*.apps.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "apps-cert"}}},
+			}},
+		},
+		{
+			desc: "should reject hostname certificates for passthrough",
+			listener: ListenerConfig{Name: "tls", Port: 9443, HostnameCertificateRefs: []HostnameCertificateRefs{
+				{Hostname: "api.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}},
+			}},
+			expectedErr: "listeners[0].hostnameCertificateRefs: Forbidden: must only be set if tlsMode is Terminate",
+		},
+		{
+			desc: "should reject invalid hostname",
+			listener: ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, CertificateRefs: certificateRefs, HostnameCertificateRefs: []HostnameCertificateRefs{
+				{Hostname: "API.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}},
+			}},
+			expectedErr: `listeners[0].hostnameCertificateRefs[0].hostname: Invalid value: "API.example.com": a lowercase RFC 1123 subdomain`,
+		},
+		{
+			desc: "should reject duplicate hostname",
+			listener: ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, CertificateRefs: certificateRefs, HostnameCertificateRefs: []HostnameCertificateRefs{
+				{Hostname: "api.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}},
+				{Hostname: "api.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "other-cert"}}},
+			}},
+			expectedErr: `listeners[0].hostnameCertificateRefs[1].hostname: Duplicate value: "api.example.com"`,
+		},
+		{
+			desc: "should reject hostname without certificates",
+			listener: ListenerConfig{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, CertificateRefs: certificateRefs, HostnameCertificateRefs: []HostnameCertificateRefs{
+				{Hostname: "api.example.com"},
+			}},
+			expectedErr: "listeners[0].hostnameCertificateRefs[0].certificateRefs: Required value",
+		},
+		{
+			desc:        "should reject TLS mode for HTTP",
+			listener:    ListenerConfig{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType, TLSMode: ptr.To(gatewayv1.TLSModeTerminate)},
+			expectedErr: "listeners[0].tlsMode: Forbidden: must only be set for protocols TLS and HTTPS",
+		},
+		{
+			desc:        "should reject unsupported protocol",
+			listener:    ListenerConfig{Name: "udp", Port: 53, Protocol: gatewayv1.UDPProtocolType},
+			expectedErr: `listeners[0].protocol: Unsupported value: "UDP"`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.listener.Validate(field.NewPath("listeners").Index(0))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Contains(t, errs[0].Error(), tC.expectedErr)
+			}
+		})
+	}
+}
+
+func TestListenerConfig_EffectiveTLSMode(t *testing.T) {
+	testCases := []struct {
+		listener ListenerConfig
+		expected gatewayv1.TLSModeType
+	}{
+		{listener: ListenerConfig{}, expected: gatewayv1.TLSModePassthrough},
+		{listener: ListenerConfig{Protocol: gatewayv1.TLSProtocolType, TLSMode: ptr.To(gatewayv1.TLSModeTerminate)}, expected: gatewayv1.TLSModeTerminate},
+		{listener: ListenerConfig{Protocol: gatewayv1.HTTPSProtocolType}, expected: gatewayv1.TLSModeTerminate},
+		{listener: ListenerConfig{Protocol: gatewayv1.HTTPProtocolType}},
+		{listener: ListenerConfig{Protocol: gatewayv1.TCPProtocolType}},
+	}
+	for _, tC := range testCases {
+		assert.Equal(t, tC.expected, tC.listener.EffectiveTLSMode(), "protocol %q", tC.listener.Protocol)
+	}
+}
+
+func TestGatewayConfig_Validate_duplicateListeners(t *testing.T) {
+	cfg := GatewayConfig{
+		Listeners: []ListenerConfig{
+			{Name: "tls", Port: 9443},
+			{Name: "tls", Port: 9444},
+		},
+	}
+	errs := cfg.Validate(field.NewPath("gateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `gateway.listeners[1].name: Duplicate value: "tls"`, errs[0].Error())
+	}
+}
+
+func TestGatewayConfig_Validate_hostnameListenerNames(t *testing.T) {
+	hostnameRefs := []HostnameCertificateRefs{{Hostname: "api.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}}}
+	https := func(name string) ListenerConfig {
+		return ListenerConfig{
+			Name:                    gatewayv1.SectionName(name),
+			Port:                    443,
+			Protocol:                gatewayv1.HTTPSProtocolType,
+			CertificateRefs:         []gatewayv1.SecretObjectReference{{Name: "default-cert"}},
+			HostnameCertificateRefs: hostnameRefs,
+		}
+	}
+
+	cfg := GatewayConfig{
+		Listeners: []ListenerConfig{
+			https("https"),
+			{Name: "https-sni-0", Port: 8443},
+		},
+	}
+	errs := cfg.Validate(field.NewPath("gateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `gateway.listeners[0].hostnameCertificateRefs[0]: Duplicate value: "https-sni-0"`, errs[0].Error())
+	}
+
+	cfg = GatewayConfig{
+		Listeners: []ListenerConfig{https(strings.Repeat("a", 250))},
+	}
+	errs = cfg.Validate(field.NewPath("gateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "gateway.listeners[0].hostnameCertificateRefs[0]: Invalid value")
+		assert.Contains(t, errs[0].Error(), "generated listener name: must be no more than 253 characters")
+	}
+}
+
+func TestGatewayConfig_Validate_domainListenerNames(t *testing.T) {
+	cfg := GatewayConfig{
+		Listeners: []ListenerConfig{
+			{Name: "tls", Port: 9443},
+			{Name: "tls-1", Port: 9444},
+			{Name: "tcp", Port: 5432, Protocol: gatewayv1.TCPProtocolType},
+			{Name: "tcp-1", Port: 5433, Protocol: gatewayv1.TCPProtocolType},
+			{Name: "tls-01", Port: 9445},
+		},
+	}
+	errs := cfg.Validate(field.NewPath("gateway"))
+	assert.Empty(t, errs)
+
+	// the listeners are only repeated for the additional domains if they are restricted to the base domains
+	cfg.RestrictToBaseDomain = true
+	errs = cfg.Validate(field.NewPath("gateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `gateway.listeners[0].name: Duplicate value: "tls-1"`, errs[0].Error())
+	}
+}
+
+func TestDomainListenerName(t *testing.T) {
+	assert.Equal(t, gatewayv1.SectionName("tls"), DomainListenerName("tls", 0))
+	assert.Equal(t, gatewayv1.SectionName("tls-12"), DomainListenerName("tls", 12))
+	assert.True(t, IsDomainListenerName("tls-12", "tls"))
+	assert.False(t, IsDomainListenerName("tls", "tls"))
+	assert.False(t, IsDomainListenerName("tls-", "tls"))
+	assert.False(t, IsDomainListenerName("tls-0", "tls"))
+	assert.False(t, IsDomainListenerName("tls-sni-0", "tls"))
+}
+
+func TestGatewayServiceConfigSpec_Validate_restrictedHostnames(t *testing.T) {
+	spec := GatewayServiceConfigSpec{
+		DNS: DNSConfig{BaseDomain: "example.com", AdditionalBaseDomains: []string{"legacy.example.org"}},
+		Gateway: &GatewayConfig{
+			Listeners: []ListenerConfig{
+				{
+					Name:            "https",
+					Port:            443,
+					Protocol:        gatewayv1.HTTPSProtocolType,
+					CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "default-cert"}},
+					HostnameCertificateRefs: []HostnameCertificateRefs{
+						{Hostname: "api.foo.bar.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}},
+						{Hostname: "*.legacy.example.org", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "legacy-cert"}}},
+						{Hostname: "api.example.net", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "other-cert"}}},
+					},
+				},
+			},
+		},
+	}
+	err := spec.Validate()
+	if err != nil {
+		assert.NotContains(t, err.Error(), "restrictToBaseDomain")
+	}
+
+	spec.Gateway.RestrictToBaseDomain = true
+	err = spec.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `spec.gateway.listeners[0].hostnameCertificateRefs[2].hostname: Invalid value: "api.example.net": must be within the base domains if restrictToBaseDomain is set`)
+		assert.NotContains(t, err.Error(), "hostnameCertificateRefs[0].hostname")
+		assert.NotContains(t, err.Error(), "hostnameCertificateRefs[1].hostname")
+	}
+}
+
+func TestHostnameInDomain(t *testing.T) {
+	assert.True(t, HostnameInDomain("example.com", "example.com"))
+	assert.True(t, HostnameInDomain("api.example.com", "example.com"))
+	assert.True(t, HostnameInDomain("*.example.com", "example.com"))
+	assert.False(t, HostnameInDomain("api.myexample.com", "example.com"))
+	assert.False(t, HostnameInDomain("example.com", "api.example.com"))
+}
+
+func TestEnvoyPatchPolicyConfig_Validate(t *testing.T) {
+	patch := apiextensionsv1.JSON{Raw: []byte(`{"type":"type.googleapis.com/envoy.config.listener.v3.Listener","name":"default/default/tls","operation":{"op":"add","path":"/per_connection_buffer_limit_bytes","value":32768}}`)}
+	testCases := []struct {
+		desc        string
+		config      EnvoyPatchPolicyConfig
+		expectedErr string
+	}{
+		{
+			desc: "should accept disabled config",
+		},
+		{
+			desc:   "should accept valid patches",
+			config: EnvoyPatchPolicyConfig{Enabled: true, EnvoyPatches: []apiextensionsv1.JSON{patch}},
+		},
+		{
+			desc:        "should reject patches if not enabled",
+			config:      EnvoyPatchPolicyConfig{EnvoyPatches: []apiextensionsv1.JSON{patch}},
+			expectedErr: "envoyPatchPolicy.envoyPatches: Forbidden: must only be set if enabled is true",
+		},
+		{
+			desc:        "should reject invalid JSON",
+			config:      EnvoyPatchPolicyConfig{Enabled: true, EnvoyPatches: []apiextensionsv1.JSON{{Raw: []byte(`{"type":`)}}},
+			expectedErr: `envoyPatchPolicy.envoyPatches[0]: Invalid value: "{\"type\":": must be a valid JSON object`,
+		},
+		{
+			desc:        "should reject patch which is not an object",
+			config:      EnvoyPatchPolicyConfig{Enabled: true, EnvoyPatches: []apiextensionsv1.JSON{{Raw: []byte(`["add"]`)}}},
+			expectedErr: `envoyPatchPolicy.envoyPatches[0]: Invalid value: "[\"add\"]": must be a valid JSON object`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.config.Validate(field.NewPath("envoyPatchPolicy"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tC.expectedErr, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestSecurityPolicyConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		config       SecurityPolicyConfig
+		expectedErrs []string
+	}{
+		{
+			desc: "should accept empty config",
+		},
+		{
+			desc: "should accept valid JWT config",
+			config: SecurityPolicyConfig{
+				JWT: &JWTConfig{
+					Issuer:    "https://issuer.example.com",
+					JWKSURI:   "https://issuer.example.com/jwks.json",
+					Audiences: []string{"gateway"},
+				},
+			},
+		},
+		{
+			desc: "should reject empty issuer and JWKS URI",
+			config: SecurityPolicyConfig{
+				JWT: &JWTConfig{Issuer: " "},
+			},
+			expectedErrs: []string{
+				"securityPolicy.jwt.issuer: Required value: must not be empty",
+				"securityPolicy.jwt.jwksURI: Required value: must not be empty",
+			},
+		},
+		{
+			desc: "should accept valid OIDC config",
+			config: SecurityPolicyConfig{
+				OIDC: &OIDCConfig{
+					Issuer:          "https://issuer.example.com",
+					ClientID:        "dashboard",
+					ClientSecretRef: corev1.LocalObjectReference{Name: "oidc-client"},
+					RedirectURL:     "https://dashboard.example.com/oauth2/callback",
+				},
+			},
+		},
+		{
+			desc: "should reject incomplete OIDC config",
+			config: SecurityPolicyConfig{
+				OIDC: &OIDCConfig{
+					Issuer:      "issuer.example.com",
+					RedirectURL: "/oauth2/callback",
+				},
+			},
+			expectedErrs: []string{
+				`securityPolicy.oidc.issuer: Invalid value: "issuer.example.com": must be a valid https:// URL`,
+				"securityPolicy.oidc.clientID: Required value: must not be empty",
+				"securityPolicy.oidc.clientSecretRef.name: Required value: must not be empty",
+				`securityPolicy.oidc.redirectURL: Invalid value: "/oauth2/callback": must be a valid http:// or https:// URL`,
+			},
+		},
+		{
+			desc: "should accept valid CORS config",
+			config: SecurityPolicyConfig{
+				CORS: &CORSConfig{
+					AllowOrigins: []egv1a1.Origin{"https://*.example.com"},
+					AllowMethods: []string{"GET", "POST"},
+					MaxAge:       &metav1.Duration{Duration: time.Hour},
+				},
+			},
+		},
+		{
+			desc: "should reject CORS config without origins",
+			config: SecurityPolicyConfig{
+				CORS: &CORSConfig{
+					MaxAge: &metav1.Duration{Duration: -time.Hour},
+				},
+			},
+			expectedErrs: []string{
+				"securityPolicy.cors.allowOrigins: Required value: must not be empty",
+				`securityPolicy.cors.maxAge: Invalid value: "-1h0m0s": must not be negative`,
+			},
+		},
+		{
+			desc: "should reject JWKS URI without https scheme",
+			config: SecurityPolicyConfig{
+				JWT: &JWTConfig{
+					Issuer:  "https://issuer.example.com",
+					JWKSURI: "http://issuer.example.com/jwks.json",
+				},
+			},
+			expectedErrs: []string{
+				`securityPolicy.jwt.jwksURI: Invalid value: "http://issuer.example.com/jwks.json": must be a valid https:// URI`,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.config.Validate(field.NewPath("securityPolicy"))
+			actual := make([]string, 0, len(errs))
+			for _, err := range errs {
+				actual = append(actual, err.Error())
+			}
+			assert.ElementsMatch(t, tC.expectedErrs, actual)
+		})
+	}
+}
+
+func TestBackendTrafficPolicyConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      BackendTrafficPolicyConfig
+		expectedErr string
+	}{
+		{
+			desc: "should accept empty config",
+		},
+		{
+			desc: "should accept positive connect timeout",
+			config: BackendTrafficPolicyConfig{
+				ConnectTimeout: &metav1.Duration{Duration: time.Second},
+			},
+		},
+		{
+			desc: "should reject zero connect timeout",
+			config: BackendTrafficPolicyConfig{
+				ConnectTimeout: &metav1.Duration{},
+			},
+			expectedErr: `backendTrafficPolicy.connectTimeout: Invalid value: "0s": must be positive`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.config.Validate(field.NewPath("backendTrafficPolicy"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tC.expectedErr, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestEnvoyGatewayConfig_Validate_rateLimit(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      EnvoyGatewayConfig
+		expectedErr string
+	}{
+		{
+			desc: "should accept config without rate limiting",
+		},
+		{
+			desc: "should accept rate limiting with ratelimit image",
+			config: EnvoyGatewayConfig{
+				Images: &ImagesConfig{
+					Ratelimit: "docker.io/envoyproxy/ratelimit:e74a664a",
+				},
+				RateLimit: &RateLimitConfig{},
+			},
+		},
+		{
+			desc: "should reject rate limiting without ratelimit image",
+			config: EnvoyGatewayConfig{
+				RateLimit: &RateLimitConfig{},
+			},
+			expectedErr: "envoyGateway.images.rateLimit: Required value: must be set when rate limiting is enabled",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tC.config.Chart = EnvoyGatewayChart{Tag: "1.5.4"}
+			errs := tC.config.Validate(field.NewPath("envoyGateway"))
+			if tC.expectedErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tC.expectedErr, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestEnvoyGatewayConfig_Validate_logLevel(t *testing.T) {
+	config := EnvoyGatewayConfig{
+		Chart:    EnvoyGatewayChart{Tag: "1.5.4"},
+		LogLevel: egv1a1.LogLevelDebug,
+	}
+	assert.Empty(t, config.Validate(field.NewPath("envoyGateway")))
+
+	config.LogLevel = egv1a1.LogLevelTrace
+	errs := config.Validate(field.NewPath("envoyGateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `envoyGateway.logLevel: Unsupported value: "trace": supported values: "debug", "info", "warn", "error"`, errs[0].Error())
+	}
+}
+
+func TestEnvoyGatewayConfig_Validate_controllerName(t *testing.T) {
+	config := EnvoyGatewayConfig{
+		Chart:          EnvoyGatewayChart{Tag: "1.5.4"},
+		ControllerName: "example.com/gatewayclass-controller",
+	}
+	assert.Empty(t, config.Validate(field.NewPath("envoyGateway")))
+
+	config.ControllerName = "gatewayclass-controller"
+	errs := config.Validate(field.NewPath("envoyGateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "envoyGateway.controllerName", errs[0].Field)
+	}
+}
+
+func TestEnvoyGatewayConfig_Validate_release(t *testing.T) {
+	config := EnvoyGatewayConfig{
+		Chart:            EnvoyGatewayChart{Tag: "1.5.4"},
+		ReleaseName:      "envoy-gateway",
+		StorageNamespace: "helm-releases",
+	}
+	assert.Empty(t, config.Validate(field.NewPath("envoyGateway")))
+
+	config.ReleaseName = "Envoy_Gateway"
+	config.StorageNamespace = "helm.releases"
+	errs := config.Validate(field.NewPath("envoyGateway"))
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "envoyGateway.releaseName", errs[0].Field)
+		assert.Equal(t, "envoyGateway.storageNamespace", errs[1].Field)
+	}
+
+	config.ReleaseName = strings.Repeat("a", 54)
+	config.StorageNamespace = ""
+	errs = config.Validate(field.NewPath("envoyGateway"))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, field.ErrorTypeTooLong, errs[0].Type)
+	}
+}
+
+func TestImagesConfig_Validate(t *testing.T) {
+	const digest = "@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	testCases := []struct {
+		desc         string
+		images       ImagesConfig
+		expectedErrs []string
+	}{
+		{
+			desc: "should accept tags when digests are not required",
+			images: ImagesConfig{
+				EnvoyProxy: "docker.io/envoyproxy/envoy:distroless-v1.35.3",
+			},
+		},
+		{
+			desc: "should accept digests when digests are required",
+			images: ImagesConfig{
+				EnvoyProxy:    "docker.io/envoyproxy/envoy" + digest,
+				EnvoyGateway:  "docker.io/envoyproxy/gateway:v1.5.1" + digest,
+				RequireDigest: true,
+			},
+		},
+		{
+			desc: "should reject tags and missing images when digests are required",
+			images: ImagesConfig{
+				EnvoyProxy:      "docker.io/envoyproxy/envoy:distroless-v1.35.3",
+				Ratelimit:       "docker.io/envoyproxy/ratelimit:e74a664a",
+				ShutdownManager: "docker.io/envoyproxy/gateway:v1.5.1",
+				RequireDigest:   true,
+			},
+			expectedErrs: []string{
+				`images.proxy: Invalid value: "docker.io/envoyproxy/envoy:distroless-v1.35.3": must be pinned by a sha256 digest when digests are required`,
+				"images.gateway: Required value: must be set when digests are required",
+				`images.rateLimit: Invalid value: "docker.io/envoyproxy/ratelimit:e74a664a": must be pinned by a sha256 digest when digests are required`,
+				`images.shutdownManager: Invalid value: "docker.io/envoyproxy/gateway:v1.5.1": must be pinned by a sha256 digest when digests are required`,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.images.Validate(field.NewPath("images"))
+			if assert.Len(t, errs, len(tC.expectedErrs)) {
+				for i, expected := range tC.expectedErrs {
+					assert.Equal(t, expected, errs[i].Error())
+				}
+			}
+		})
+	}
+}
+
+func TestAccessLogConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		accessLog    AccessLogConfig
+		expectedErrs []string
+	}{
+		{
+			desc: "should accept file sink",
+			accessLog: AccessLogConfig{
+				Sinks: []AccessLogSink{{Type: egv1a1.ProxyAccessLogSinkTypeFile, Path: "/var/log/access.log"}},
+			},
+		},
+		{
+			desc: "should accept OpenTelemetry sink with JSON fields",
+			accessLog: AccessLogConfig{
+				Format:     egv1a1.ProxyAccessLogFormatTypeJSON,
+				JSONFields: map[string]string{"start_time": "%START_TIME%"},
+				Sinks:      []AccessLogSink{{Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry, Endpoint: "otel:4317"}},
+			},
+		},
+		{
+			desc: "should reject invalid combinations",
+			accessLog: AccessLogConfig{
+				JSONFields: map[string]string{"start_time": "%START_TIME%"},
+				Sinks: []AccessLogSink{
+					{Type: egv1a1.ProxyAccessLogSinkTypeFile, Endpoint: "otel:4317"},
+					{Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry, Path: "/dev/stdout"},
+					{Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry, Endpoint: "otel:http"},
+				},
+			},
+			expectedErrs: []string{
+				"accessLog.jsonFields: Forbidden: must only be set for the JSON format",
+				"accessLog.sinks[0].endpoint: Forbidden: must not be set for the File sink",
+				"accessLog.sinks[1].path: Forbidden: must not be set for the OpenTelemetry sink",
+				"accessLog.sinks[1].endpoint: Required value: must be set for the OpenTelemetry sink",
+				`accessLog.sinks[2].endpoint: Invalid value: "otel:http": invalid port: http`,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			errs := tC.accessLog.Validate(field.NewPath("accessLog"))
+			if assert.Len(t, errs, len(tC.expectedErrs)) {
+				for i, expected := range tC.expectedErrs {
+					assert.Equal(t, expected, errs[i].Error())
+				}
+			}
+		})
+	}
+}
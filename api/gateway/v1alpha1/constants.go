@@ -1,11 +1,78 @@
 package v1alpha1
 
 import (
+	"crypto/sha256"
+	"fmt"
+
 	openmcpconst "github.com/openmcp-project/openmcp-operator/api/constants"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	OperationAnnotation = "gateway." + openmcpconst.OperationAnnotation
 
+	// SuspendAnnotation suspends the Flux resources of the gateway on a Cluster if set to "true".
+	// The gateway resources are still reconciled, but upgrades of the Envoy Gateway deployment are paused.
+	SuspendAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/suspend"
+
+	// FreezeAnnotation freezes the gateway on a Cluster if set to "true".
+	// In contrast to the ignore operation annotation, which skips the Cluster entirely, a frozen gateway is neither
+	// updated nor uninstalled and the finalizer is kept, so the Cluster cannot be deleted until the annotation is removed.
+	FreezeAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/freeze"
+
+	// ProxyReplicasAnnotation overrides the number of Envoy Proxy replicas of the gateway on a Cluster.
+	ProxyReplicasAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/proxy-replicas"
+
+	// ProxyImageAnnotation overrides the Envoy Proxy image of the gateway on a Cluster.
+	ProxyImageAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/proxy-image"
+
+	// ChartTagAnnotation pins the Envoy Gateway chart of the gateway on a Cluster to the given tag,
+	// e.g. to canary a new version on a single Cluster.
+	ChartTagAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/chart-tag"
+
+	// ConfirmUninstallAnnotation confirms on the GatewayServiceConfig that the gateway may be uninstalled from more Clusters
+	// than the mass uninstall threshold of the controller allows. The value has to be the generation of the GatewayServiceConfig,
+	// so that a confirmation does not apply to later changes.
+	ConfirmUninstallAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/confirm-uninstall"
+
+	// ClusterNameLabel is set on the Flux resources on the platform cluster which install the gateway on a Cluster.
+	// Its value is the name of the Cluster as returned by ClusterNameLabelValue, which is in the same namespace as the resources.
+	// The full name is stored in the ClusterNameAnnotation.
+	ClusterNameLabel = "gateway." + openmcpconst.OpenMCPGroupName + "/cluster-name"
+
+	// ClusterNamespaceLabel is set next to the ClusterNameLabel and contains the namespace of the Cluster,
+	// so that the resources can be selected by label alone, e.g. with kubectl or in metrics.
+	ClusterNamespaceLabel = "gateway." + openmcpconst.OpenMCPGroupName + "/cluster-namespace"
+
+	// ClusterNameAnnotation is set next to the ClusterNameLabel and contains the full name of the Cluster.
+	ClusterNameAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/cluster-name"
+
+	// ClusterNamespaceAnnotation is set next to the ClusterNameAnnotation and contains the namespace of the Cluster.
+	ClusterNamespaceAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/cluster-namespace"
+
+	// AddressAnnotation is set on a Cluster by the controller if enabled and contains the comma-separated addresses
+	// assigned to the gateway, so that other controllers can discover the endpoint of the gateway.
+	AddressAnnotation = "gateway." + openmcpconst.OpenMCPGroupName + "/address"
+
+	// DefaultListenerName is the name of the TLS passthrough listener of the gateway if no listeners are configured.
+	DefaultListenerName = "tls"
+
+	// DefaultTLSPort is the port of the default listener if no TLSPort is configured.
+	DefaultTLSPort int32 = 9443
+
 	GatewayFinalizerOnCluster = "platformservice." + openmcpconst.OpenMCPGroupName + "/gateway"
 )
+
+// clusterNameHashLength is the number of hex characters of the hash which replaces the end of a truncated Cluster name.
+const clusterNameHashLength = 10
+
+// ClusterNameLabelValue returns the value of the ClusterNameLabel for the given Cluster name.
+// Names which exceed the maximum length of a label value are truncated and suffixed with a hash of the full name,
+// so that the value stays unique.
+func ClusterNameLabelValue(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:clusterNameHashLength]
+	return name[:validation.LabelValueMaxLength-clusterNameHashLength-1] + "-" + hash
+}
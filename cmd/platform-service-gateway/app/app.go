@@ -27,6 +27,9 @@ func NewPlatformServiceGatewayCommand() *cobra.Command {
 	so.AddPersistentFlags(cmd)
 	cmd.AddCommand(NewInitCommand(so))
 	cmd.AddCommand(NewRunCommand(so))
+	cmd.AddCommand(NewValidateCommand(so))
+	cmd.AddCommand(NewComputeDomainCommand(so))
+	cmd.AddCommand(NewPrintValuesCommand(so))
 
 	return cmd
 }
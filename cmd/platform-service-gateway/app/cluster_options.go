@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
+)
+
+// ClusterOptions are the options of the commands which inspect the gateway of a single cluster.
+type ClusterOptions struct {
+	*SharedOptions
+
+	// Cluster is the reference to the Cluster in the format <namespace>/<name>.
+	Cluster string
+	// ConfigFile is the path of a GatewayServiceConfig manifest to use instead of the one in the platform cluster.
+	ConfigFile string
+
+	// fields filled in Complete()
+	clusterRef types.NamespacedName
+}
+
+func (o *ClusterOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Cluster, "cluster", "", "Reference to the Cluster in the format <namespace>/<name>.")
+	cmd.Flags().StringVar(&o.ConfigFile, "config", "", "Path of a GatewayServiceConfig manifest. If not set, the GatewayServiceConfig of the provider is read from the platform cluster.")
+	_ = cmd.MarkFlagRequired("cluster")
+}
+
+func (o *ClusterOptions) Complete(ctx context.Context) error {
+	namespace, name, found := strings.Cut(o.Cluster, "/")
+	if !found || namespace == "" || name == "" {
+		return fmt.Errorf("%w: '%s'", errInvalidClusterRef, o.Cluster)
+	}
+	o.clusterRef = types.NamespacedName{Namespace: namespace, Name: name}
+
+	if err := o.SharedOptions.Complete(); err != nil {
+		return err
+	}
+	return o.PlatformCluster.InitializeClient(schemes.Platform)
+}
+
+// load returns the Cluster and the GatewayServiceConfig, which is read from the ConfigFile if given.
+func (o *ClusterOptions) load(ctx context.Context) (*clustersv1alpha1.Cluster, *v1alpha1.GatewayServiceConfig, error) {
+	cfg, err := loadGatewayServiceConfig(ctx, o.SharedOptions, o.ConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &clustersv1alpha1.Cluster{}
+	if err := o.PlatformCluster.Client().Get(ctx, o.clusterRef, c); err != nil {
+		return nil, nil, fmt.Errorf("error getting Cluster '%s': %w", o.clusterRef, err)
+	}
+	return c, cfg, nil
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/pkg/envoy"
+)
+
+var errInvalidClusterRef = errors.New("invalid cluster reference, expected <namespace>/<name>")
+
+func NewComputeDomainCommand(so *SharedOptions) *cobra.Command {
+	opts := &ComputeDomainOptions{
+		ClusterOptions: ClusterOptions{SharedOptions: so},
+	}
+	cmd := &cobra.Command{
+		Use:   "compute-domain --cluster <namespace>/<name>",
+		Short: "Print the base domains of a cluster",
+		Long: `Print the base domains the gateway of the given cluster is exposed under, one per line, starting with the primary one.
+The cluster is read from the platform cluster. The GatewayServiceConfig of the provider is read from the platform cluster as well,
+unless a file is given via --config.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(cmd.Context()); err != nil {
+				panic(fmt.Errorf("error completing options: %w", err))
+			}
+			if err := opts.Run(cmd); err != nil {
+				cmd.PrintErrln(err)
+				os.Exit(1)
+			}
+		},
+	}
+	opts.AddFlags(cmd)
+
+	return cmd
+}
+
+type ComputeDomainOptions struct {
+	ClusterOptions
+}
+
+func (o *ComputeDomainOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	cluster, cfg, err := o.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	domains, err := envoy.EffectiveBaseDomains(cluster, cfg.Spec.DNS)
+	if err != nil {
+		return fmt.Errorf("error computing base domains of Cluster '%s': %w", o.clusterRef, err)
+	}
+	for _, domain := range domains {
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), domain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadGatewayServiceConfig returns the GatewayServiceConfig from the given file or, if none is given,
+// the one of the provider from the platform cluster.
+func loadGatewayServiceConfig(ctx context.Context, so *SharedOptions, file string) (*v1alpha1.GatewayServiceConfig, error) {
+	cfg := &v1alpha1.GatewayServiceConfig{}
+
+	if file == "" {
+		if err := so.PlatformCluster.Client().Get(ctx, types.NamespacedName{Name: so.ProviderName}, cfg); err != nil {
+			return nil, fmt.Errorf("error getting GatewayServiceConfig '%s': %w", so.ProviderName, err)
+		}
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file '%s': %w", file, err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("error decoding GatewayServiceConfig from file '%s': %w", file, err)
+	}
+	return cfg, nil
+}
@@ -66,7 +66,9 @@ func (o *RunOptions) PrintRawOptions(cmd *cobra.Command) {
 
 func (o *RunOptions) PrintCompleted(cmd *cobra.Command) {
 	raw := map[string]any{
-		// TODO add options or remove
+		"leaderElection":          o.EnableLeaderElection,
+		"leaderElectionID":        o.LeaderElectionID,
+		"leaderElectionNamespace": o.LeaderElectionNS,
 	}
 	data, err := yaml.Marshal(raw)
 	if err != nil {
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openmcp-project/platform-service-gateway/internal/controllers/cluster"
+	"github.com/openmcp-project/platform-service-gateway/pkg/envoy"
+)
+
+func NewPrintValuesCommand(so *SharedOptions) *cobra.Command {
+	opts := &PrintValuesOptions{
+		ClusterOptions: ClusterOptions{SharedOptions: so},
+	}
+	cmd := &cobra.Command{
+		Use:   "print-values --cluster <namespace>/<name>",
+		Short: "Print the Helm values of the Envoy Gateway release of a cluster",
+		Long: `Print the Helm values the controller passes to Flux for the Envoy Gateway release of the given cluster as JSON.
+The override annotations of the cluster are applied, ignored overrides are reported on stderr.
+The cluster is read from the platform cluster. The GatewayServiceConfig of the provider is read from the platform cluster as well,
+unless a file is given via --config.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(cmd.Context()); err != nil {
+				panic(fmt.Errorf("error completing options: %w", err))
+			}
+			if err := opts.Run(cmd); err != nil {
+				cmd.PrintErrln(err)
+				os.Exit(1)
+			}
+		},
+	}
+	opts.AddFlags(cmd)
+
+	return cmd
+}
+
+type PrintValuesOptions struct {
+	ClusterOptions
+}
+
+func (o *PrintValuesOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	c, cfg, err := o.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	envoyConfig, warnings := cluster.EffectiveEnvoyGatewayConfig(c, cfg)
+	for _, warning := range warnings {
+		cmd.PrintErrln(warning)
+	}
+	gw := &envoy.Gateway{
+		Cluster:       c,
+		EnvoyConfig:   *envoyConfig,
+		GatewayConfig: cfg.Spec.Gateway,
+		DNSConfig:     cfg.Spec.DNS,
+	}
+	values, err := gw.HelmValues()
+	if err != nil {
+		return fmt.Errorf("error generating Helm values of Cluster '%s': %w", o.clusterRef, err)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(values))
+	return err
+}
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	openmcpconst "github.com/openmcp-project/openmcp-operator/api/constants"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -22,12 +25,16 @@ import (
 	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
 	"github.com/openmcp-project/platform-service-gateway/internal/controllers/cluster"
 	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
+	"github.com/openmcp-project/platform-service-gateway/internal/webhooks"
 
 	"github.com/openmcp-project/controller-utils/pkg/logging"
 )
 
 var setupLog logging.Logger
 
+// defaultLeaderElectionID is the name of the leader election Lease, which must stay stable across upgrades.
+const defaultLeaderElectionID = "github.com/openmcp-project/platform-service-gateway"
+
 func NewRunCommand(so *SharedOptions) *cobra.Command {
 	opts := &RunOptions{
 		SharedOptions: so,
@@ -65,10 +72,19 @@ type RawRunOptions struct {
 	WebhookCertName      string `json:"webhook-cert-name"`
 	WebhookCertKey       string `json:"webhook-cert-key"`
 	EnableLeaderElection bool   `json:"leader-elect"`
+	LeaderElectionID     string `json:"leader-election-id"`
+	LeaderElectionNS     string `json:"leader-election-namespace"`
 	ProbeAddr            string `json:"health-probe-bind-address"`
 	PprofAddr            string `json:"pprof-bind-address"`
 	SecureMetrics        bool   `json:"metrics-secure"`
 	EnableHTTP2          bool   `json:"enable-http2"`
+	EnableWebhooks       bool   `json:"enable-webhooks"`
+
+	MassUninstallThreshold   int           `json:"mass-uninstall-threshold"`
+	OrphanCollectionInterval time.Duration `json:"orphan-collection-interval"`
+	AnnotateClusterAddress   bool          `json:"annotate-cluster-address"`
+	HealthFailureWindow      time.Duration `json:"health-failure-window"`
+	HealthFailureThreshold   float64       `json:"health-failure-threshold"`
 
 	Controllers []string `json:"controllers"`
 }
@@ -92,6 +108,8 @@ func (o *RunOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.ProbeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	cmd.Flags().StringVar(&o.PprofAddr, "pprof-bind-address", "", "The address the pprof endpoint binds to. Expected format is ':<port>'. Leave empty to disable pprof endpoint.")
 	cmd.Flags().BoolVar(&o.EnableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	cmd.Flags().StringVar(&o.LeaderElectionID, "leader-election-id", defaultLeaderElectionID, "The name of the Lease used for leader election. Changing it during a rolling upgrade lets old and new replicas reconcile at the same time.")
+	cmd.Flags().StringVar(&o.LeaderElectionNS, "leader-election-namespace", "", "The namespace of the Lease used for leader election. Defaults to the namespace of the provider pod.")
 	cmd.Flags().BoolVar(&o.SecureMetrics, "metrics-secure", true, "If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	cmd.Flags().StringVar(&o.WebhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
 	cmd.Flags().StringVar(&o.WebhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
@@ -100,6 +118,20 @@ func (o *RunOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.MetricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
 	cmd.Flags().StringVar(&o.MetricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	cmd.Flags().BoolVar(&o.EnableHTTP2, "enable-http2", false, "If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	cmd.Flags().BoolVar(&o.EnableWebhooks, "enable-webhooks", false, "If set, the validating webhook for GatewayServiceConfigs is served. Requires the webhook certificate.")
+	cmd.Flags().IntVar(&o.MassUninstallThreshold, "mass-uninstall-threshold", 0, "Maximum number of clusters from which the gateway is uninstalled due to a change of the GatewayServiceConfig without the confirm-uninstall annotation. 0 disables the safeguard.")
+	cmd.Flags().DurationVar(&o.OrphanCollectionInterval, "orphan-collection-interval", time.Hour, "Interval in which the gateway resources of Clusters which were deleted without uninstalling the gateway are removed. 0 disables the collection.")
+	cmd.Flags().BoolVar(&o.AnnotateClusterAddress, "annotate-cluster-address", false, "If set, the addresses of the gateway are written to the gateway.openmcp.cloud/address annotation of each Cluster.")
+	cmd.Flags().DurationVar(&o.HealthFailureWindow, "health-failure-window", 30*time.Minute, "Duration for which the reconciliation of a cluster has to fail continuously to count towards the health check.")
+	cmd.Flags().Float64Var(&o.HealthFailureThreshold, "health-failure-threshold", 1, "Ratio of clusters with failing reconciliations from which the health check fails, so that the provider is restarted. 1 means all clusters.")
+
+	// --enable-leader-election is accepted as an alias of --leader-elect
+	cmd.Flags().SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "enable-leader-election" {
+			name = "leader-elect"
+		}
+		return pflag.NormalizedName(name)
+	})
 }
 
 func (o *RunOptions) Complete(ctx context.Context) error {
@@ -114,6 +146,13 @@ func (o *RunOptions) Complete(ctx context.Context) error {
 	setupLog = o.Log.WithName("setup")
 	ctrl.SetLogger(o.Log.Logr())
 
+	if o.LeaderElectionNS == "" {
+		o.LeaderElectionNS = o.ProviderNamespace
+	}
+	if o.HealthFailureThreshold <= 0 || o.HealthFailureThreshold > 1 {
+		return fmt.Errorf("--health-failure-threshold must be in (0, 1], got %v", o.HealthFailureThreshold)
+	}
+
 	// kubebuilder default stuff
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
@@ -210,13 +249,17 @@ func (o *RunOptions) Run(ctx context.Context) error {
 	})
 
 	mgr, err := ctrl.NewManager(o.PlatformCluster.RESTConfig(), ctrl.Options{
-		Scheme:                 o.PlatformCluster.Scheme(),
-		Metrics:                o.MetricsServerOptions,
-		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: o.ProbeAddr,
-		PprofBindAddress:       o.PprofAddr,
-		LeaderElection:         o.EnableLeaderElection,
-		LeaderElectionID:       "github.com/openmcp-project/platform-service-gateway",
+		Scheme:                  o.PlatformCluster.Scheme(),
+		Metrics:                 o.MetricsServerOptions,
+		WebhookServer:           webhookServer,
+		HealthProbeBindAddress:  o.ProbeAddr,
+		PprofBindAddress:        o.PprofAddr,
+		LeaderElection:          o.EnableLeaderElection,
+		LeaderElectionID:        o.LeaderElectionID,
+		LeaderElectionNamespace: o.LeaderElectionNS,
+		Cache: cache.Options{
+			ByObject: cluster.CacheByObject(o.ProviderNamespace),
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -240,9 +283,30 @@ func (o *RunOptions) Run(ctx context.Context) error {
 		}
 		return fmt.Errorf("error getting GatewayServiceConfig '%s': %w", o.ProviderName, err)
 	}
-	if err := cluster.NewClusterReconciler(o.PlatformCluster, mgr.GetEventRecorder(cluster.ControllerName), o.ProviderName, o.ProviderNamespace).SetupWithManager(mgr); err != nil {
+	clusterReconciler := cluster.NewClusterReconciler(o.PlatformCluster, mgr.GetEventRecorder(cluster.ControllerName), o.ProviderName, o.ProviderNamespace)
+	clusterReconciler.MassUninstallThreshold = o.MassUninstallThreshold
+	clusterReconciler.AnnotateAddress = o.AnnotateClusterAddress
+	clusterReconciler.Health = &cluster.ReconcileHealth{
+		Window:           o.HealthFailureWindow,
+		FailureThreshold: o.HealthFailureThreshold,
+	}
+	if err := clusterReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to add Cluster reconciler to manager: %w", err)
 	}
+	if o.OrphanCollectionInterval > 0 {
+		if err := mgr.Add(&cluster.OrphanCollector{
+			PlatformClient: o.PlatformCluster.Client(),
+			Log:            logging.Wrap(mgr.GetLogger()).WithName("OrphanCollector"),
+			Interval:       o.OrphanCollectionInterval,
+		}); err != nil {
+			return fmt.Errorf("unable to add orphan collector to manager: %w", err)
+		}
+	}
+	if o.EnableWebhooks {
+		if err := webhooks.SetupGatewayServiceConfigWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to add GatewayServiceConfig webhook to manager: %w", err)
+		}
+	}
 
 	if o.MetricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
@@ -261,6 +325,9 @@ func (o *RunOptions) Run(ctx context.Context) error {
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		return fmt.Errorf("unable to set up health check: %w", err)
 	}
+	if err := mgr.AddHealthzCheck("reconcile", clusterReconciler.Health.Check); err != nil {
+		return fmt.Errorf("unable to set up reconcile health check: %w", err)
+	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		return fmt.Errorf("unable to set up ready check: %w", err)
 	}
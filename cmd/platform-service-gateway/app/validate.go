@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
+)
+
+var errInvalidConfig = errors.New("invalid GatewayServiceConfig")
+
+func NewValidateCommand(so *SharedOptions) *cobra.Command {
+	opts := &ValidateOptions{
+		SharedOptions: so,
+	}
+	cmd := &cobra.Command{
+		Use:   "validate [file...]",
+		Short: "Validate GatewayServiceConfigs",
+		Long: `Validate GatewayServiceConfigs read from the given files.
+If no file is given, the GatewayServiceConfig of the provider is read from the platform cluster.
+Exits with a non-zero exit code if any of the configs is invalid.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.Files = args
+			if err := opts.Complete(cmd.Context()); err != nil {
+				panic(fmt.Errorf("error completing options: %w", err))
+			}
+			if err := opts.Run(cmd); err != nil {
+				cmd.PrintErrln(err)
+				os.Exit(1)
+			}
+		},
+	}
+	opts.AddFlags(cmd)
+
+	return cmd
+}
+
+type ValidateOptions struct {
+	*SharedOptions
+
+	// Files contains the paths of the GatewayServiceConfig manifests to validate.
+	Files []string
+}
+
+func (o *ValidateOptions) AddFlags(cmd *cobra.Command) {}
+
+func (o *ValidateOptions) Complete(ctx context.Context) error {
+	if len(o.Files) > 0 {
+		// validating files does not require access to the platform cluster
+		return nil
+	}
+	if err := o.SharedOptions.Complete(); err != nil {
+		return err
+	}
+	return o.PlatformCluster.InitializeClient(schemes.Platform)
+}
+
+func (o *ValidateOptions) Run(cmd *cobra.Command) error {
+	configs, err := o.loadConfigs(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	invalid := 0
+	for _, cfg := range configs {
+		err := cfg.config.Spec.Validate()
+		if err == nil {
+			cmd.Printf("%s: valid\n", cfg.source)
+			continue
+		}
+		invalid++
+		cmd.PrintErrf("%s: invalid\n", cfg.source)
+		var agg utilerrors.Aggregate
+		if errors.As(err, &agg) {
+			for _, e := range agg.Errors() {
+				cmd.PrintErrf("  - %s\n", e)
+			}
+		} else {
+			cmd.PrintErrf("  - %s\n", err)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%w: %d of %d configs failed validation", errInvalidConfig, invalid, len(configs))
+	}
+	return nil
+}
+
+type sourcedConfig struct {
+	source string
+	config *v1alpha1.GatewayServiceConfig
+}
+
+// loadConfigs returns the GatewayServiceConfigs to validate together with their source.
+func (o *ValidateOptions) loadConfigs(ctx context.Context) ([]sourcedConfig, error) {
+	if len(o.Files) == 0 {
+		cfg, err := loadGatewayServiceConfig(ctx, o.SharedOptions, "")
+		if err != nil {
+			return nil, err
+		}
+		return []sourcedConfig{{source: fmt.Sprintf("GatewayServiceConfig %s", o.ProviderName), config: cfg}}, nil
+	}
+
+	configs := make([]sourcedConfig, 0, len(o.Files))
+	for _, file := range o.Files {
+		cfg, err := loadGatewayServiceConfig(ctx, o.SharedOptions, file)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, sourcedConfig{source: file, config: cfg})
+	}
+	return configs, nil
+}
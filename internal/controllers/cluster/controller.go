@@ -1,10 +1,14 @@
 package cluster
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	fluxmeta "github.com/fluxcd/pkg/apis/meta"
@@ -17,9 +21,12 @@ import (
 	accesslib "github.com/openmcp-project/openmcp-operator/lib/clusteraccess/advanced"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -38,27 +45,125 @@ var (
 	errFailedToGetAccessRequest          = errors.New("failed to get AccessRequest resource")
 	errFailedToGetClusterAccess          = errors.New("failed to get access to cluster")
 	errClusterAccessNotYetAvailable      = errors.New("cluster access is not yet available")
+	errClusterAccessTimeout              = errors.New("timed out waiting for cluster access")
+	errInvalidGatewayServiceConfig       = errors.New("invalid GatewayServiceConfig")
+	errFailedToResolveChartTag           = errors.New("failed to resolve chart tag")
+	errFailedToListClusters              = errors.New("failed to list Clusters")
+	errFailedToUpdateAddressAnnotation   = errors.New("failed to update address annotation")
 )
 
+// Event reasons are machine-readable codes which automation can rely on, e.g. for alerting.
+// They are documented in the README, keep both in sync.
 const (
-	reasonRemainingResources = "RemainingResources"
-	reasonGatewayInstalled   = "GatewayInstalled"
-	reasonGatewayUninstalled = "GatewayUninstalled"
+	reasonRemainingResources    = "RemainingResources"
+	reasonGatewayInstalled      = "GatewayInstalled"
+	reasonGatewayProgrammed     = "GatewayProgrammed"
+	reasonGatewayAddressMissing = "GatewayAddressMissing"
+	reasonGatewayUninstalled    = "GatewayUninstalled"
+	reasonInvalidConfig         = "InvalidConfig"
+	reasonWaitingForAccess      = "WaitingForClusterAccess"
+	reasonAccessTimeout         = "ClusterAccessTimeout"
+	reasonAccessEstablished     = "ClusterAccessEstablished"
+	reasonGatewayFrozen         = "GatewayFrozen"
+	reasonWaitingForCRDs        = "WaitingForGatewayCRDs"
+	reasonPlatformCluster       = "PlatformClusterRefused"
+	reasonChartTagOverridden    = "ChartTagOverridden"
+	reasonUnsupportedAPIVersion = "UnsupportedGatewayAPIVersion"
+	reasonInvalidOverride       = "InvalidClusterOverride"
+	reasonChartTagPinned        = "ChartTagPinned"
+	reasonMassUninstallBlocked  = "MassUninstallBlocked"
+	reasonReleaseFailed         = "HelmReleaseFailed"
+	reasonConfigMissing         = "GatewayServiceConfigMissing"
+)
 
+const (
 	actionInstallGateway   = "InstallGateway"
 	actionUninstallGateway = "UninstallGateway"
 
 	clusterId = "cluster"
 
+	// defaultDriftInterval is the interval in which clusters are reconciled to correct drift.
+	defaultDriftInterval = 1 * time.Hour
+	// defaultReleaseProgressInterval is the interval in which clusters are reconciled while the Helm release is being installed or upgraded.
+	defaultReleaseProgressInterval = 30 * time.Second
+
+	// defaultWaitingForCRDsEventInterval is the minimum interval between two WaitingForGatewayCRDs events of a cluster.
+	// Configure is retried every few seconds while the CRDs are missing, which would otherwise spam events.
+	defaultWaitingForCRDsEventInterval = 10 * time.Minute
+
 	ControllerName = "GatewayCluster"
 )
 
+// Timings are the requeue and event intervals of the ClusterReconciler.
+// Zero values fall back to the defaults, so tests can shorten individual intervals.
+type Timings struct {
+	// DriftInterval is the interval in which clusters are reconciled to correct drift.
+	DriftInterval time.Duration
+	// ReleaseProgressInterval is the interval in which clusters are reconciled while the Helm release is in progress.
+	ReleaseProgressInterval time.Duration
+	// WaitingForCRDsEventInterval is the minimum interval between two WaitingForGatewayCRDs events of a cluster.
+	WaitingForCRDsEventInterval time.Duration
+	// Gateway are the timings passed to the gateway manager of each cluster.
+	Gateway envoy.Timings
+}
+
+func (t Timings) getDriftInterval() time.Duration {
+	return cmp.Or(t.DriftInterval, defaultDriftInterval)
+}
+
+func (t Timings) getReleaseProgressInterval() time.Duration {
+	return cmp.Or(t.ReleaseProgressInterval, defaultReleaseProgressInterval)
+}
+
+func (t Timings) getWaitingForCRDsEventInterval() time.Duration {
+	return cmp.Or(t.WaitingForCRDsEventInterval, defaultWaitingForCRDsEventInterval)
+}
+
 type ClusterReconciler struct {
 	PlatformCluster         *clusters.Cluster
 	eventRecorder           events.EventRecorder
 	ProviderName            string
 	ProviderNamespace       string
 	ClusterAccessReconciler accesslib.ClusterAccessReconciler
+	Timings                 Timings
+
+	// MassUninstallThreshold is the number of Clusters from which the gateway may be uninstalled due to a change of the
+	// GatewayServiceConfig without confirmation. A value of 0 disables the safeguard.
+	MassUninstallThreshold int
+
+	// AnnotateAddress enables writing the addresses of the gateway to the AddressAnnotation of each Cluster.
+	AnnotateAddress bool
+
+	// Health records the outcome of each reconciliation if set.
+	Health *ReconcileHealth
+
+	// waitingForAccessSince stores when the controller started waiting for access per cluster.
+	waitingForAccessSince   map[types.NamespacedName]time.Time
+	waitingForAccessSinceMu sync.Mutex
+
+	// waitingForCRDsEvents stores when the last WaitingForGatewayCRDs event was emitted per cluster.
+	waitingForCRDsEvents   map[types.NamespacedName]time.Time
+	waitingForCRDsEventsMu sync.Mutex
+
+	// remainingResourcesEvents stores the remaining resources of the last RemainingResources event per cluster.
+	remainingResourcesEvents   map[types.NamespacedName]string
+	remainingResourcesEventsMu sync.Mutex
+
+	// accessEstablishedEvents stores the access resources of the last ClusterAccessEstablished event per cluster.
+	accessEstablishedEvents   map[types.NamespacedName]string
+	accessEstablishedEventsMu sync.Mutex
+
+	// gatewayAddressEvents stores the addresses of the last GatewayProgrammed or GatewayAddressMissing event per cluster.
+	gatewayAddressEvents   map[types.NamespacedName]string
+	gatewayAddressEventsMu sync.Mutex
+
+	// platformClusterRefusedEvents stores the clusters for which a PlatformClusterRefused event was emitted.
+	platformClusterRefusedEvents   map[types.NamespacedName]bool
+	platformClusterRefusedEventsMu sync.Mutex
+
+	// platformClusterUID is the UID of the kube-system namespace of the platform cluster.
+	platformClusterUID   types.UID
+	platformClusterUIDMu sync.Mutex
 }
 
 func NewClusterReconciler(platformCluster *clusters.Cluster, recorder events.EventRecorder, providerName, providerNamespace string) *ClusterReconciler {
@@ -90,7 +195,8 @@ func NewClusterReconciler(platformCluster *clusters.Cluster, recorder events.Eve
 var _ reconcile.Reconciler = &ClusterReconciler{}
 
 func (r *ClusterReconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
-	log := logging.FromContextOrPanic(ctx).WithName(ControllerName)
+	// all log lines of the reconciliation, including those of the gateway manager, carry the identity of the cluster
+	log := logging.FromContextOrPanic(ctx).WithName(ControllerName).WithValues("cluster", req.String())
 	ctx = logging.NewContext(ctx, log)
 	log.Info("Starting reconcile")
 
@@ -98,16 +204,42 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req reconcile.Request
 	// instead, output events for significant changes
 
 	res, err := r.reconcile(ctx, req)
+	// transient API errors (e.g. conflicts) are retried after a short delay instead of being reported as failures
+	err = utils.ClassifyError(err)
+	// retried errors still count for the health check, otherwise a provider whose API calls keep timing out is never unhealthy
+	r.Health.Record(req.NamespacedName, healthError(err))
 
 	retryable := &utils.RetryableError{}
 	if errors.As(err, &retryable) {
 		log.Info(fmt.Sprintf("Handling retryable error: %s", retryable.Unwrap()), "RequeueAfter", retryable.RequeueAfter)
-		return ctrl.Result{RequeueAfter: retryable.RequeueAfter}, nil
+		err = nil
+		res = ctrl.Result{RequeueAfter: retryable.RequeueAfter}
 	}
-
 	return res, err
 }
 
+// updateFinalizer adds or removes the gateway finalizer of the given Cluster with the given function and updates the Cluster if it changed.
+// On a conflict, the Cluster is fetched again and the update is retried once.
+// A remaining conflict is returned and requeued as a retryable error by Reconcile.
+func (r *ClusterReconciler) updateFinalizer(ctx context.Context, c *clustersv1alpha1.Cluster, mutate func(client.Object, string) bool) error {
+	if !mutate(c, gatewayv1alpha1.GatewayFinalizerOnCluster) {
+		return nil
+	}
+	err := r.PlatformCluster.Client().Update(ctx, c)
+	if !apierrors.IsConflict(err) {
+		return err
+	}
+
+	logging.FromContextOrPanic(ctx).Debug("Retrying finalizer update after conflict")
+	if err := r.PlatformCluster.Client().Get(ctx, client.ObjectKeyFromObject(c), c); err != nil {
+		return err
+	}
+	if !mutate(c, gatewayv1alpha1.GatewayFinalizerOnCluster) {
+		return nil
+	}
+	return r.PlatformCluster.Client().Update(ctx, c)
+}
+
 func (r *ClusterReconciler) reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
 	log := logging.FromContextOrPanic(ctx)
 
@@ -116,56 +248,99 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, req reconcile.Request
 	if err := r.PlatformCluster.Client().Get(ctx, req.NamespacedName, c); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("Resource not found")
+			r.forgetCluster(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, errors.Join(errFailedToGetCluster, err)
 	}
 
+	// without the GatewayServiceConfig, neither the installation nor the cleanup of the gateway can proceed.
+	// Its creation enqueues the affected clusters again, so the cluster is left untouched until then.
+	if _, err := r.getGatewayServiceConfig(ctx, r.ProviderName); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Skipping reconcile, GatewayServiceConfig not found", "name", r.ProviderName)
+			if !c.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(c, gatewayv1alpha1.GatewayFinalizerOnCluster) {
+				// the deletion of the cluster is blocked by the finalizer until the GatewayServiceConfig is restored
+				r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonConfigMissing, actionUninstallGateway,
+					"GatewayServiceConfig %s not found, the gateway cannot be uninstalled and the deletion of the cluster is blocked", r.ProviderName)
+			}
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	// handle operation annotation
+	var requestedAt, reconcileOpKey string
 	if c.GetAnnotations() != nil {
-		op, ok := c.GetAnnotations()[gatewayv1alpha1.OperationAnnotation]
+		opKey := gatewayv1alpha1.OperationAnnotation
+		op, ok := c.GetAnnotations()[opKey]
 		if !ok {
 			// only evaluate the generic operation annotation if no gateway-specific one is set
-			op, ok = c.GetAnnotations()[openmcpconst.OperationAnnotation]
+			opKey = openmcpconst.OperationAnnotation
+			op, ok = c.GetAnnotations()[opKey]
 		}
 		if ok {
 			switch op {
 			case openmcpconst.OperationAnnotationValueIgnore:
 				log.Info("Ignoring resource due to ignore operation annotation")
+				r.Health.Forget(req.NamespacedName)
 				return ctrl.Result{}, nil
 			case openmcpconst.OperationAnnotationValueReconcile:
-				log.Debug("Removing reconcile operation annotation from resource")
-				if err := ctrlutils.EnsureAnnotation(ctx, r.PlatformCluster.Client(), c, openmcpconst.OperationAnnotation, "", true, ctrlutils.DELETE); err != nil {
-					return ctrl.Result{}, errors.Join(errFailedToRemoveOperationAnnotation, err)
-				}
+				// forward the request to Flux, so that the chart is re-pulled and re-deployed immediately.
+				// The annotation is only removed once the request has been handled, so that it isn't lost if the reconciliation stops before.
+				reconcileOpKey = opKey
+				requestedAt = time.Now().UTC().Format(time.RFC3339Nano)
 			}
 		}
 	}
 
-	if !r.shouldReconcile(c) {
+	if !r.shouldReconcile(ctx, c) {
 		log.Debug("Ignoring cluster. Does not have a gateway finalizer or a config entry that matches")
+		r.Health.Forget(req.NamespacedName)
+		return ctrl.Result{}, r.removeReconcileOperationAnnotation(ctx, c, reconcileOpKey)
+	}
+
+	if c.Annotations[gatewayv1alpha1.FreezeAnnotation] == "true" {
+		// leave the existing gateway resources and the finalizer untouched until the annotation is removed
+		log.Info("Skipping reconcile due to freeze annotation")
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonGatewayFrozen, actionInstallGateway, "Gateway is frozen, remove the %s annotation to resume updates", gatewayv1alpha1.FreezeAnnotation)
+		r.Health.Forget(req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
+	r.Health.Track(req.NamespacedName)
+
+	if blocked, err := r.blockMassUninstall(ctx, c); err != nil || blocked {
+		return ctrl.Result{}, err
+	}
 
 	gwMgr, err := r.buildGatewayManager(ctx, req, c)
+	if errors.Is(err, errClusterAccessTimeout) {
+		// stop requeuing until the next drift correction to avoid hot loops on permanently broken access
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonAccessTimeout, actionInstallGateway, "%s", err.Error())
+		return ctrl.Result{RequeueAfter: r.Timings.getDriftInterval()}, nil
+	}
 	if err != nil {
 		return ctrl.Result{}, errors.Join(errFailedToBuildGatewayManager, err)
 	}
+	gwMgr.RequestedAt = requestedAt
 
-	if !c.DeletionTimestamp.IsZero() || !r.enabledForCluster(c) {
+	if refused, err := r.refusePlatformCluster(ctx, c, gwMgr.ClusterClient); err != nil || refused {
+		return ctrl.Result{}, err
+	}
+
+	if !c.DeletionTimestamp.IsZero() || !r.enabledForCluster(ctx, c) {
 		// delete gateway resources
 		if err := gwMgr.Cleanup(ctx); err != nil {
-			if utils.IsRemainingResourcesError(err) {
-				r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonRemainingResources, actionUninstallGateway, err.Error())
-			}
+			r.recordRemainingResources(c, err)
 			return ctrl.Result{}, err
 		}
 
 		// uninstall gateway
 		if err := gwMgr.Uninstall(ctx); err != nil {
-			if utils.IsRemainingResourcesError(err) {
-				r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonRemainingResources, actionUninstallGateway, err.Error())
-			}
+			r.recordRemainingResources(c, err)
+			return ctrl.Result{}, err
+		}
+		if err := r.removeReconcileOperationAnnotation(ctx, c, reconcileOpKey); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -178,40 +353,247 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, req reconcile.Request
 			return result, nil
 		}
 
-		if controllerutil.RemoveFinalizer(c, gatewayv1alpha1.GatewayFinalizerOnCluster) {
-			if err := r.PlatformCluster.Client().Update(ctx, c); err != nil {
-				return ctrl.Result{}, err
-			}
+		if err := r.updateAddressAnnotation(ctx, c, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.updateFinalizer(ctx, c, controllerutil.RemoveFinalizer); err != nil {
+			return ctrl.Result{}, err
 		}
 
+		r.forgetCluster(req.NamespacedName)
 		r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonGatewayUninstalled, actionUninstallGateway, "Gateway uninstalled successfully")
 		return ctrl.Result{}, nil
 	}
 
-	if controllerutil.AddFinalizer(c, gatewayv1alpha1.GatewayFinalizerOnCluster) {
-		if err := r.PlatformCluster.Client().Update(ctx, c); err != nil {
-			return ctrl.Result{}, err
-		}
+	if err := r.validateGatewayServiceConfig(ctx, c); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.resolveChartTag(ctx, c, &gwMgr.EnvoyConfig.Chart); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateFinalizer(ctx, c, controllerutil.AddFinalizer); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	if err := gwMgr.InstallOrUpdate(ctx); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := r.removeReconcileOperationAnnotation(ctx, c, reconcileOpKey); err != nil {
+		return ctrl.Result{}, err
+	}
 	if err := gwMgr.Configure(ctx); err != nil {
+		if errors.Is(err, envoy.ErrUnsupportedGatewayAPIVersion) {
+			// retrying doesn't help until the CRDs are upgraded, so only check again with the next drift correction
+			r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonUnsupportedAPIVersion, actionInstallGateway, "%s", err.Error())
+			return ctrl.Result{RequeueAfter: r.Timings.getDriftInterval()}, nil
+		}
+		if utils.IsCRDNotFoundError(err) {
+			r.recordWaitingForGatewayCRDs(c, err)
+		}
+		return ctrl.Result{}, err
+	}
+	r.resetWaitingForGatewayCRDs(c)
+	addresses, err := gwMgr.GatewayAddresses(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateAddressAnnotation(ctx, c, addresses); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordGatewayAddresses(c, addresses)
+
+	// check again soon while Flux is still working on the release, so that failures are surfaced promptly
+	inProgress, err := gwMgr.ReleaseInProgress(ctx)
+	if errors.Is(err, envoy.ErrReleaseFailed) {
+		// Flux doesn't retry the release until it changes, so only check again with the next drift correction
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonReleaseFailed, actionInstallGateway, "%s", err.Error())
+		return ctrl.Result{RequeueAfter: r.Timings.getDriftInterval()}, nil
+	}
+	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if inProgress {
+		log.Debug("Helm release is in progress", "RequeueAfter", r.Timings.getReleaseProgressInterval())
+		return ctrl.Result{RequeueAfter: r.Timings.getReleaseProgressInterval()}, nil
+	}
+	return ctrl.Result{RequeueAfter: r.Timings.getDriftInterval()}, nil
+}
+
+// removeReconcileOperationAnnotation removes the reconcile operation annotation with the given key from the cluster.
+// It is a no-op if the key is empty, i.e. no reconciliation was requested.
+func (r *ClusterReconciler) removeReconcileOperationAnnotation(ctx context.Context, c *clustersv1alpha1.Cluster, opKey string) error {
+	if opKey == "" {
+		return nil
+	}
+	logging.FromContextOrPanic(ctx).Debug("Removing reconcile operation annotation from resource")
+	if err := ctrlutils.EnsureAnnotation(ctx, r.PlatformCluster.Client(), c, opKey, "", true, ctrlutils.DELETE); err != nil {
+		return errors.Join(errFailedToRemoveOperationAnnotation, err)
+	}
+	return nil
+}
+
+// updateAddressAnnotation writes the addresses of the gateway to the AddressAnnotation of the cluster if enabled.
+// The annotation is removed if no addresses are given, e.g. after uninstalling the gateway, or if it is disabled.
+func (r *ClusterReconciler) updateAddressAnnotation(ctx context.Context, c *clustersv1alpha1.Cluster, addresses []string) error {
+	mode := ctrlutils.OVERWRITE
+	if !r.AnnotateAddress || len(addresses) == 0 {
+		mode = ctrlutils.DELETE
+	}
+	if err := ctrlutils.EnsureAnnotation(ctx, r.PlatformCluster.Client(), c, gatewayv1alpha1.AddressAnnotation, strings.Join(addresses, ","), true, mode); err != nil {
+		return errors.Join(errFailedToUpdateAddressAnnotation, err)
+	}
+	return nil
+}
+
+// withClusterLogValues returns a context whose logger carries the identity of the given cluster.
+func withClusterLogValues(ctx context.Context, cluster types.NamespacedName) context.Context {
+	return logging.NewContext(ctx, logging.FromContextOrDiscard(ctx).WithValues("cluster", cluster.String()))
+}
+
+// recordWaitingForGatewayCRDs counts that the Gateway of the cluster cannot be configured because the Envoy Gateway CRDs are missing.
+// The corresponding event is emitted at most once per WaitingForCRDsEventInterval of the Timings.
+func (r *ClusterReconciler) recordWaitingForGatewayCRDs(c *clustersv1alpha1.Cluster, err error) {
+	key := client.ObjectKeyFromObject(c)
+	waitingForGatewayCRDsTotal.WithLabelValues(key.String()).Inc()
+
+	r.waitingForCRDsEventsMu.Lock()
+	defer r.waitingForCRDsEventsMu.Unlock()
+	if last, ok := r.waitingForCRDsEvents[key]; ok && time.Since(last) < r.Timings.getWaitingForCRDsEventInterval() {
+		return
+	}
+	if r.waitingForCRDsEvents == nil {
+		r.waitingForCRDsEvents = map[types.NamespacedName]time.Time{}
+	}
+	r.waitingForCRDsEvents[key] = time.Now()
+	r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonWaitingForCRDs, actionInstallGateway, "Waiting for the Envoy Gateway CRDs to be installed: %s", err.Error())
+}
+
+// resetWaitingForGatewayCRDs resets the event throttling once the Gateway of the cluster could be configured.
+func (r *ClusterReconciler) resetWaitingForGatewayCRDs(c *clustersv1alpha1.Cluster) {
+	r.waitingForCRDsEventsMu.Lock()
+	defer r.waitingForCRDsEventsMu.Unlock()
+	delete(r.waitingForCRDsEvents, client.ObjectKeyFromObject(c))
+}
+
+// recordRemainingResources emits a RemainingResources event if the given error is a RemainingResourcesError.
+// The event is only emitted if the set of remaining resources of the cluster changed since the last event,
+// because the uninstallation is retried every few seconds while the resources are being deleted.
+func (r *ClusterReconciler) recordRemainingResources(c *clustersv1alpha1.Cluster, err error) {
+	remaining := &utils.RemainingResourcesError{}
+	if !errors.As(err, &remaining) {
+		return
+	}
+	key := client.ObjectKeyFromObject(c)
+	ids := strings.Join(remaining.ObjectIdentifiers(), ", ")
+
+	r.remainingResourcesEventsMu.Lock()
+	defer r.remainingResourcesEventsMu.Unlock()
+	if last, ok := r.remainingResourcesEvents[key]; ok && last == ids {
+		return
+	}
+	if r.remainingResourcesEvents == nil {
+		r.remainingResourcesEvents = map[types.NamespacedName]string{}
+	}
+	r.remainingResourcesEvents[key] = ids
+	r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonRemainingResources, actionUninstallGateway, "%s", remaining.Error())
+}
 
+// recordGatewayAddresses emits the GatewayInstalled event together with a GatewayProgrammed event listing the addresses of the gateway,
+// or a GatewayAddressMissing warning if the Gateway has no addresses yet. The events are only emitted if the addresses changed
+// since the last event, because the gateway is reconciled periodically to correct drift.
+func (r *ClusterReconciler) recordGatewayAddresses(c *clustersv1alpha1.Cluster, addresses []string) {
+	key := client.ObjectKeyFromObject(c)
+	joined := strings.Join(addresses, ", ")
+
+	r.gatewayAddressEventsMu.Lock()
+	defer r.gatewayAddressEventsMu.Unlock()
+	if last, ok := r.gatewayAddressEvents[key]; ok && last == joined {
+		return
+	}
+	if r.gatewayAddressEvents == nil {
+		r.gatewayAddressEvents = map[types.NamespacedName]string{}
+	}
+	r.gatewayAddressEvents[key] = joined
+	if len(addresses) == 0 {
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonGatewayAddressMissing, actionInstallGateway, "Gateway has not been programmed with an address yet")
+	} else {
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonGatewayProgrammed, actionInstallGateway, "Gateway programmed with addresses %s", joined)
+	}
 	r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonGatewayInstalled, actionInstallGateway, "Gateway installed successfully")
-	return ctrl.Result{RequeueAfter: 1 * time.Hour}, nil
+}
+
+// recordAccessEstablished emits a ClusterAccessEstablished event, so that Flux errors regarding the kubeconfig can be correlated
+// with the access resources. The event is only emitted if the AccessRequest or its Secret changed since the last event,
+// because the access is checked on every reconciliation.
+func (r *ClusterReconciler) recordAccessEstablished(c *clustersv1alpha1.Cluster, ar *clustersv1alpha1.AccessRequest) {
+	key := client.ObjectKeyFromObject(c)
+	access := fmt.Sprintf("%s/%s/%s", ar.Namespace, ar.Name, ar.Status.SecretRef.Name)
+
+	r.accessEstablishedEventsMu.Lock()
+	defer r.accessEstablishedEventsMu.Unlock()
+	if last, ok := r.accessEstablishedEvents[key]; ok && last == access {
+		return
+	}
+	if r.accessEstablishedEvents == nil {
+		r.accessEstablishedEvents = map[types.NamespacedName]string{}
+	}
+	r.accessEstablishedEvents[key] = access
+	r.eventRecorder.Eventf(c, ar, corev1.EventTypeNormal, reasonAccessEstablished, actionInstallGateway,
+		"Using kubeconfig Secret %s/%s of AccessRequest %s", ar.Namespace, ar.Status.SecretRef.Name, ar.Name)
+}
+
+// resolveChartTag reads the tag of the chart from the ConfigMap referenced by VersionFrom.
+// An inline tag takes precedence, which is reported with an event.
+func (r *ClusterReconciler) resolveChartTag(ctx context.Context, c *clustersv1alpha1.Cluster, chart *gatewayv1alpha1.EnvoyGatewayChart) error {
+	if chart.VersionFrom == nil {
+		return nil
+	}
+	ref := chart.VersionFrom
+	if chart.Tag != "" {
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonChartTagOverridden, actionInstallGateway,
+			"Chart tag %s overrides the tag from key %s of ConfigMap %s/%s", chart.Tag, ref.Key, r.ProviderNamespace, ref.Name)
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.PlatformCluster.Client().Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: r.ProviderNamespace}, cm); err != nil {
+		return errors.Join(errFailedToResolveChartTag, err)
+	}
+	tag := strings.TrimSpace(cm.Data[ref.Key])
+	if tag == "" {
+		return fmt.Errorf("%w: key %s of ConfigMap %s/%s is empty or missing", errFailedToResolveChartTag, ref.Key, cm.Namespace, cm.Name)
+	}
+	chart.Tag = tag
+
+	// the resolved tag has to satisfy the same constraints as an inline tag
+	if errs := chart.Validate(field.NewPath("spec", "envoyGateway", "chart")); len(errs) > 0 {
+		err := errs.ToAggregate()
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonInvalidConfig, actionInstallGateway, "%s", err.Error())
+		return errors.Join(errInvalidGatewayServiceConfig, err)
+	}
+	return nil
+}
+
+// CacheByObject returns the cache options of the objects watched by the controller, which have to be passed to the Manager.
+// Only the ConfigMaps in the namespace of the provider are cached, as they are only watched for the chart version.
+func CacheByObject(providerNamespace string) map[client.Object]cache.ByObject {
+	return map[client.Object]cache.ByObject{
+		&corev1.ConfigMap{}: {
+			Namespaces: map[string]cache.Config{providerNamespace: {}},
+		},
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// The Manager has to restrict its cache according to CacheByObject.
 func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	log := logging.Wrap(mgr.GetLogger()).WithName(ControllerName)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&clustersv1alpha1.Cluster{}).
 		Watches(&gatewayv1alpha1.GatewayServiceConfig{}, r.mapGatewayServiceConfigToClusters(log)).
 		Watches(&corev1.Secret{}, r.mapSecretToRequests(log)).
+		Watches(&corev1.ConfigMap{}, r.mapConfigMapToRequests(log)).
 		Complete(r)
 }
 
@@ -219,13 +601,27 @@ func (r *ClusterReconciler) buildGatewayManager(ctx context.Context, req reconci
 	log := logging.FromContextOrPanic(ctx)
 	log.Info("Creating or updating AccessRequest to get access to Cluster")
 
+	cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := r.ClusterAccessReconciler.Reconcile(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	if res.RequeueAfter > 0 {
+		since, started := r.startWaitingForAccess(req)
+		if err := r.checkClusterAccessTimeout(ctx, req, since, cfg.Spec.ClusterAccess); err != nil {
+			return nil, err
+		}
+		// the AccessRequest is polled every few seconds, the event is only emitted once per wait
+		if started {
+			r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonWaitingForAccess, actionInstallGateway, "Waiting for access to the cluster")
+		}
 		return nil, utils.NewRetryableError(errClusterAccessNotYetAvailable, res.RequeueAfter)
 	}
+	r.resetWaitingForAccess(req)
 
 	ar, err := r.ClusterAccessReconciler.AccessRequest(ctx, req, clusterId)
 	if err != nil {
@@ -236,19 +632,31 @@ func (r *ClusterReconciler) buildGatewayManager(ctx context.Context, req reconci
 	if err != nil {
 		return nil, errors.Join(errFailedToGetClusterAccess, err)
 	}
+	r.recordAccessEstablished(c, ar)
 
-	cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
-	if err != nil {
-		return nil, err
+	envoyConfig, warnings := EffectiveEnvoyGatewayConfig(c, cfg)
+	for _, warning := range warnings {
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonInvalidOverride, actionInstallGateway, "%s", warning)
+	}
+	if tag, ok := c.Annotations[gatewayv1alpha1.ChartTagAnnotation]; ok && envoyConfig.Chart.Tag == tag {
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeNormal, reasonChartTagPinned, actionInstallGateway,
+			"Chart tag is pinned to %s by the %s annotation", tag, gatewayv1alpha1.ChartTagAnnotation)
 	}
 
 	gw := &envoy.Gateway{
-		Cluster:        c,
-		EnvoyConfig:    cfg.Spec.EnvoyGateway,
-		GatewayConfig:  cfg.Spec.Gateway,
-		DNSConfig:      cfg.Spec.DNS,
-		PlatformClient: r.PlatformCluster.Client(),
-		ClusterClient:  access.Client(),
+		Cluster:             c,
+		EnvoyConfig:         *envoyConfig,
+		GatewayConfig:       cfg.Spec.Gateway,
+		DNSConfig:           cfg.Spec.DNS,
+		CommonMetadata:      cfg.Spec.CommonMetadata,
+		GatewayNamespace:    cfg.Spec.GatewayNamespace,
+		DeploymentNamespace: cfg.Spec.DeploymentNamespace,
+		CreateNamespaces:    cfg.Spec.CreateNamespaces,
+		CleanupConfig:       cfg.Spec.Cleanup,
+		Timings:             r.Timings.Gateway,
+		Suspend:             c.Annotations[gatewayv1alpha1.SuspendAnnotation] == "true",
+		PlatformClient:      r.PlatformCluster.Client(),
+		ClusterClient:       access.Client(),
 		FluxKubeconfig: &fluxmeta.KubeConfigReference{
 			SecretRef: &fluxmeta.SecretKeyReference{
 				Name: ar.Status.SecretRef.Name,
@@ -259,25 +667,256 @@ func (r *ClusterReconciler) buildGatewayManager(ctx context.Context, req reconci
 	return gw, nil
 }
 
-func (r *ClusterReconciler) shouldReconcile(cluster *clustersv1alpha1.Cluster) bool {
-	return controllerutil.ContainsFinalizer(cluster, gatewayv1alpha1.GatewayFinalizerOnCluster) || r.enabledForCluster(cluster)
+// startWaitingForAccess returns since when the controller has been waiting for access to the cluster of the given request.
+// started is true if the wait starts with this reconciliation.
+func (r *ClusterReconciler) startWaitingForAccess(req reconcile.Request) (since time.Time, started bool) {
+	r.waitingForAccessSinceMu.Lock()
+	defer r.waitingForAccessSinceMu.Unlock()
+	if r.waitingForAccessSince == nil {
+		r.waitingForAccessSince = map[types.NamespacedName]time.Time{}
+	}
+	since, ok := r.waitingForAccessSince[req.NamespacedName]
+	if !ok {
+		since = time.Now()
+		r.waitingForAccessSince[req.NamespacedName] = since
+	}
+	return since, !ok
+}
+
+// forgetCluster drops the state and the metric series which are kept per cluster,
+// once the cluster is deleted or its gateway is uninstalled.
+func (r *ClusterReconciler) forgetCluster(key types.NamespacedName) {
+	r.Health.Forget(key)
+	waitingForGatewayCRDsTotal.DeleteLabelValues(key.String())
+	deleteLocked(&r.waitingForAccessSinceMu, &r.waitingForAccessSince, key)
+	deleteLocked(&r.waitingForCRDsEventsMu, &r.waitingForCRDsEvents, key)
+	deleteLocked(&r.remainingResourcesEventsMu, &r.remainingResourcesEvents, key)
+	deleteLocked(&r.accessEstablishedEventsMu, &r.accessEstablishedEvents, key)
+	deleteLocked(&r.gatewayAddressEventsMu, &r.gatewayAddressEvents, key)
+	deleteLocked(&r.platformClusterRefusedEventsMu, &r.platformClusterRefusedEvents, key)
+}
+
+// deleteLocked deletes the given key from the map while holding the given lock.
+// The map is passed by pointer, because it is initialized lazily under the same lock.
+func deleteLocked[V any](mu *sync.Mutex, m *map[types.NamespacedName]V, key types.NamespacedName) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(*m, key)
 }
 
-func (r *ClusterReconciler) enabledForCluster(cluster *clustersv1alpha1.Cluster) bool {
-	ctx := context.Background()
+// checkClusterAccessTimeout returns an error wrapping errClusterAccessTimeout if the controller has been waiting
+// for access to the cluster of the given request since the given time for longer than the configured timeout.
+// The wait is measured from the first reconciliation without access, not from the creation of the AccessRequest,
+// so that an established AccessRequest which is briefly not ready doesn't time out immediately.
+func (r *ClusterReconciler) checkClusterAccessTimeout(ctx context.Context, req reconcile.Request, since time.Time, cfg *gatewayv1alpha1.ClusterAccessConfig) error {
+	if cfg == nil || cfg.Timeout == nil {
+		return nil
+	}
+	if pending := time.Since(since); pending > cfg.Timeout.Duration {
+		name := req.String()
+		if ar, err := r.ClusterAccessReconciler.AccessRequest(ctx, req, clusterId); err == nil {
+			name = client.ObjectKeyFromObject(ar).String()
+		}
+		return fmt.Errorf("%w: AccessRequest %s pending for %s", errClusterAccessTimeout, name, pending.Round(time.Second))
+	}
+	return nil
+}
+
+// resetWaitingForAccess resets the wait for access to the cluster of the given request once access is available.
+func (r *ClusterReconciler) resetWaitingForAccess(req reconcile.Request) {
+	r.waitingForAccessSinceMu.Lock()
+	defer r.waitingForAccessSinceMu.Unlock()
+	delete(r.waitingForAccessSince, req.NamespacedName)
+}
+
+// validateGatewayServiceConfig validates the GatewayServiceConfig and emits a warning event on the Cluster if it is invalid.
+func (r *ClusterReconciler) validateGatewayServiceConfig(ctx context.Context, c *clustersv1alpha1.Cluster) error {
 	cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
 	if err != nil {
+		return err
+	}
+	if err := cfg.Spec.Validate(); err != nil {
+		r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonInvalidConfig, actionInstallGateway, "%s", err.Error())
+		return errors.Join(errInvalidGatewayServiceConfig, err)
+	}
+	return nil
+}
+
+func (r *ClusterReconciler) shouldReconcile(ctx context.Context, cluster *clustersv1alpha1.Cluster) bool {
+	return controllerutil.ContainsFinalizer(cluster, gatewayv1alpha1.GatewayFinalizerOnCluster) || r.enabledForCluster(ctx, cluster) || r.hasOrphanedGateway(ctx, cluster)
+}
+
+// hasOrphanedGateway checks if the gateway is still installed on a cluster which has lost its finalizer,
+// e.g. because it was removed manually. Such clusters are reconciled to clean up the gateway.
+func (r *ClusterReconciler) hasOrphanedGateway(ctx context.Context, cluster *clustersv1alpha1.Cluster) bool {
+	log := logging.FromContextOrDiscard(ctx)
+	found, err := envoy.HasFluxResources(ctx, r.PlatformCluster.Client(), cluster)
+	if err != nil {
+		log.Error(err, "failed to check for orphaned gateway resources")
 		return false
 	}
+	if found {
+		log.Debug("Cluster has orphaned gateway resources without finalizer")
+	}
+	return found
+}
+
+// blockMassUninstall checks if the gateway would be uninstalled from the given cluster due to a change of the GatewayServiceConfig,
+// while the number of clusters which are no longer matched exceeds the MassUninstallThreshold.
+// Such uninstallations are blocked until they are confirmed with the ConfirmUninstallAnnotation on the GatewayServiceConfig.
+// Clusters in deletion are never blocked, so that their cleanup can finish.
+func (r *ClusterReconciler) blockMassUninstall(ctx context.Context, c *clustersv1alpha1.Cluster) (bool, error) {
+	if r.MassUninstallThreshold <= 0 || !c.DeletionTimestamp.IsZero() || r.enabledForCluster(ctx, c) {
+		return false, nil
+	}
+
+	cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
+	if err != nil {
+		return false, err
+	}
+	generation := strconv.FormatInt(cfg.Generation, 10)
+	if cfg.Annotations[gatewayv1alpha1.ConfirmUninstallAnnotation] == generation {
+		return false, nil
+	}
 
-	for _, ct := range cfg.Spec.Clusters {
+	list := &clustersv1alpha1.ClusterList{}
+	if err := r.PlatformCluster.Client().List(ctx, list); err != nil {
+		return false, errors.Join(errFailedToListClusters, err)
+	}
+	uninstalls := 0
+	for _, cluster := range list.Items {
+		if controllerutil.ContainsFinalizer(&cluster, gatewayv1alpha1.GatewayFinalizerOnCluster) && cluster.DeletionTimestamp.IsZero() &&
+			!r.enabledForCluster(ctx, &cluster) {
+			uninstalls++
+		}
+	}
+	if uninstalls <= r.MassUninstallThreshold {
+		return false, nil
+	}
+
+	logging.FromContextOrDiscard(ctx).Info("Blocking uninstallation of gateway", "clusters", uninstalls, "threshold", r.MassUninstallThreshold)
+	r.eventRecorder.Eventf(c, cfg, corev1.EventTypeWarning, reasonMassUninstallBlocked, actionUninstallGateway,
+		"Gateway would be uninstalled from %d clusters, which exceeds the threshold of %d. Annotate GatewayServiceConfig %s with %s=%s to confirm",
+		uninstalls, r.MassUninstallThreshold, cfg.Name, gatewayv1alpha1.ConfirmUninstallAnnotation, generation)
+	return true, nil
+}
+
+// refusePlatformCluster checks if the cluster of the given client is the platform cluster and the GatewayServiceConfig doesn't allow
+// to install the gateway into it. Clusters in deletion are never refused, so that their cleanup can finish.
+func (r *ClusterReconciler) refusePlatformCluster(ctx context.Context, c *clustersv1alpha1.Cluster, clusterClient client.Client) (bool, error) {
+	if !c.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+	cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
+	if err != nil {
+		return false, err
+	}
+	if cfg.Spec.AllowPlatformCluster {
+		r.resetPlatformClusterRefused(c)
+		return false, nil
+	}
+	isPlatform, err := r.isPlatformCluster(ctx, clusterClient)
+	if err != nil || !isPlatform {
+		return false, err
+	}
+	// an existing installation is left untouched, but the gateway is never installed into the platform cluster by accident
+	logging.FromContextOrDiscard(ctx).Info("Refusing to install gateway into the platform cluster")
+	r.recordPlatformClusterRefused(c)
+	return true, nil
+}
+
+// isPlatformCluster checks if the cluster of the given client is the platform cluster by comparing the UIDs of their kube-system namespaces.
+// The API server endpoints cannot be compared, because the platform cluster is usually accessed via its in-cluster service address.
+// Clusters without a kube-system namespace cannot be identified and are never considered the platform cluster.
+func (r *ClusterReconciler) isPlatformCluster(ctx context.Context, clusterClient client.Client) (bool, error) {
+	platformUID, err := r.getPlatformClusterUID(ctx)
+	if err != nil || platformUID == "" {
+		return false, err
+	}
+	ns := &corev1.Namespace{}
+	if err := clusterClient.Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace %s of cluster: %w", metav1.NamespaceSystem, err)
+	}
+	return ns.UID == platformUID, nil
+}
+
+// getPlatformClusterUID returns the UID of the kube-system namespace of the platform cluster, which is read only once.
+func (r *ClusterReconciler) getPlatformClusterUID(ctx context.Context) (types.UID, error) {
+	r.platformClusterUIDMu.Lock()
+	defer r.platformClusterUIDMu.Unlock()
+	if r.platformClusterUID != "" {
+		return r.platformClusterUID, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.PlatformCluster.Client().Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get namespace %s of platform cluster: %w", metav1.NamespaceSystem, err)
+	}
+	r.platformClusterUID = ns.UID
+	return r.platformClusterUID, nil
+}
+
+// recordPlatformClusterRefused emits a PlatformClusterRefused event once per cluster,
+// because the refusal is checked again on every reconciliation.
+func (r *ClusterReconciler) recordPlatformClusterRefused(c *clustersv1alpha1.Cluster) {
+	key := client.ObjectKeyFromObject(c)
+
+	r.platformClusterRefusedEventsMu.Lock()
+	defer r.platformClusterRefusedEventsMu.Unlock()
+	if r.platformClusterRefusedEvents[key] {
+		return
+	}
+	if r.platformClusterRefusedEvents == nil {
+		r.platformClusterRefusedEvents = map[types.NamespacedName]bool{}
+	}
+	r.platformClusterRefusedEvents[key] = true
+	r.eventRecorder.Eventf(c, nil, corev1.EventTypeWarning, reasonPlatformCluster, actionInstallGateway,
+		"Cluster is the platform cluster, set spec.allowPlatformCluster in the GatewayServiceConfig to install the gateway into it")
+}
+
+// resetPlatformClusterRefused resets the event deduplication once the platform cluster is allowed.
+func (r *ClusterReconciler) resetPlatformClusterRefused(c *clustersv1alpha1.Cluster) {
+	r.platformClusterRefusedEventsMu.Lock()
+	defer r.platformClusterRefusedEventsMu.Unlock()
+	delete(r.platformClusterRefusedEvents, client.ObjectKeyFromObject(c))
+}
+
+func (r *ClusterReconciler) enabledForCluster(ctx context.Context, cluster *clustersv1alpha1.Cluster) bool {
+	log := logging.FromContextOrDiscard(ctx)
+	cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
+	if err != nil {
+		return false
+	}
+
+	for i, ct := range cfg.Spec.Clusters {
+		if ct.ExcludeSelector != nil && selectorMatches(*ct.ExcludeSelector, cluster) {
+			log.Debug("Cluster is excluded by selector", "term", i, "purpose", ct.ExcludeSelector.MatchPurpose, "labels", ct.ExcludeSelector.MatchLabels)
+			return false
+		}
+	}
+
+	for i, ct := range cfg.Spec.Clusters {
 		if ct.ClusterRef != nil && refMatches(*ct.ClusterRef, cluster) {
+			log.Debug("Cluster matches reference", "term", i, "name", ct.ClusterRef.Name, "namespace", ct.ClusterRef.Namespace)
 			return true
 		}
+		for _, ref := range ct.ClusterRefs {
+			if refMatches(ref, cluster) {
+				log.Debug("Cluster matches reference", "term", i, "name", ref.Name, "namespace", ref.Namespace)
+				return true
+			}
+		}
 		if ct.Selector != nil && selectorMatches(*ct.Selector, cluster) {
+			log.Debug("Cluster matches selector", "term", i, "purpose", ct.Selector.MatchPurpose, "labels", ct.Selector.MatchLabels)
 			return true
 		}
 	}
+	log.Debug("Cluster matches no term")
 	return false
 }
 
@@ -320,35 +959,66 @@ func labelsMatch(labels map[string]string, cluster *clustersv1alpha1.Cluster) bo
 // mapGatewayServiceConfigToClusters returns an event handler that maps GatewayServiceConfig updates to reconciliation requests for matching  clusters.
 func (r *ClusterReconciler) mapGatewayServiceConfigToClusters(log logging.Logger) handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
-		gatewayServiceConfig, ok := obj.(*gatewayv1alpha1.GatewayServiceConfig)
-		if !ok {
-			return []reconcile.Request{}
-		}
-		// required
-		if gatewayServiceConfig.Name != r.ProviderName {
-			return []reconcile.Request{}
-		}
+		return r.gatewayServiceConfigToRequests(logging.NewContext(ctx, log), obj)
+	})
+}
 
-		log.Info("GatewayServiceConfig was updated, re-enqueueing matching cluster resources", "configName", gatewayServiceConfig.Name)
+// gatewayServiceConfigToRequests returns reconciliation requests for all clusters affected by a change of the GatewayServiceConfig.
+// Besides the matching clusters, this includes clusters which still have the gateway installed,
+// so that config changes are applied across the fleet and clusters which no longer match are cleaned up.
+func (r *ClusterReconciler) gatewayServiceConfigToRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logging.FromContextOrDiscard(ctx)
+	gatewayServiceConfig, ok := obj.(*gatewayv1alpha1.GatewayServiceConfig)
+	if !ok {
+		return []reconcile.Request{}
+	}
+	// required
+	if gatewayServiceConfig.Name != r.ProviderName {
+		return []reconcile.Request{}
+	}
 
-		clusters := &clustersv1alpha1.ClusterList{}
-		if err := r.PlatformCluster.Client().List(ctx, clusters); err != nil {
-			log.Error(err, "failed to list clusters")
-			return []reconcile.Request{}
+	log.Info("GatewayServiceConfig was updated, re-enqueueing matching cluster resources", "configName", gatewayServiceConfig.Name)
+
+	clusters := &clustersv1alpha1.ClusterList{}
+	if err := r.PlatformCluster.Client().List(ctx, clusters); err != nil {
+		log.Error(err, "failed to list clusters")
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for _, cluster := range clusters.Items {
+		if r.shouldReconcile(withClusterLogValues(ctx, client.ObjectKeyFromObject(&cluster)), &cluster) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      cluster.Name,
+					Namespace: cluster.Namespace,
+				},
+			})
 		}
+	}
+	return requests
+}
 
-		var requests []reconcile.Request
-		for _, cluster := range clusters.Items {
-			if r.shouldReconcile(&cluster) {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name:      cluster.Name,
-						Namespace: cluster.Namespace,
-					},
-				})
-			}
+// mapConfigMapToRequests returns an event handler that maps updates of the ConfigMap referenced by the chart's VersionFrom
+// to reconciliation requests for all clusters, so that a new chart version is rolled out promptly.
+func (r *ClusterReconciler) mapConfigMapToRequests(log logging.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		if obj.GetNamespace() != r.ProviderNamespace {
+			return nil
 		}
-		return requests
+
+		cfg, err := r.getGatewayServiceConfig(ctx, r.ProviderName)
+		if err != nil {
+			log.Error(err, "failed to get GatewayServiceConfig", "GatewayServiceConfigName", r.ProviderName)
+			return nil
+		}
+		ref := cfg.Spec.EnvoyGateway.Chart.VersionFrom
+		if ref == nil || ref.Name != obj.GetName() {
+			return nil
+		}
+
+		log.Info("Referenced chart version ConfigMap was updated, re-enqueueing clusters", "configMapName", obj.GetName())
+		return r.gatewayServiceConfigToRequests(logging.NewContext(ctx, log), cfg)
 	})
 }
 
@@ -366,11 +1036,11 @@ func (r *ClusterReconciler) mapSecretToRequests(log logging.Logger) handler.Even
 			return nil
 		}
 
-		if !isReferencedImagePullSecret(cfg, secret.Name) {
+		if !isReferencedSecret(cfg, secret.Name) {
 			return nil
 		}
 
-		log.Info("ImagePullSecret was updated, re-enqueueing clusters in same namespace", "secretName", secret.Name, "namespace", secret.Namespace)
+		log.Info("Referenced Secret was updated, re-enqueueing clusters in same namespace", "secretName", secret.Name, "namespace", secret.Namespace)
 
 		clusterList := &clustersv1alpha1.ClusterList{}
 		if err := r.PlatformCluster.Client().List(ctx, clusterList, client.InNamespace(secret.Namespace)); err != nil {
@@ -378,9 +1048,10 @@ func (r *ClusterReconciler) mapSecretToRequests(log logging.Logger) handler.Even
 			return nil
 		}
 
+		ctx = logging.NewContext(ctx, log)
 		var requests []reconcile.Request
 		for _, cluster := range clusterList.Items {
-			if r.shouldReconcile(&cluster) {
+			if r.shouldReconcile(withClusterLogValues(ctx, client.ObjectKeyFromObject(&cluster)), &cluster) {
 				requests = append(requests, reconcile.Request{
 					NamespacedName: types.NamespacedName{
 						Name:      cluster.Name,
@@ -393,6 +1064,20 @@ func (r *ClusterReconciler) mapSecretToRequests(log logging.Logger) handler.Even
 	})
 }
 
+// isReferencedSecret checks if the Secret with the given name is referenced by the GatewayServiceConfig,
+// either as ImagePullSecret or as OIDC client secret.
+func isReferencedSecret(cfg *gatewayv1alpha1.GatewayServiceConfig, secretName string) bool {
+	return isReferencedImagePullSecret(cfg, secretName) || isReferencedOIDCClientSecret(cfg, secretName)
+}
+
+func isReferencedOIDCClientSecret(cfg *gatewayv1alpha1.GatewayServiceConfig, secretName string) bool {
+	gw := cfg.Spec.Gateway
+	if gw == nil || gw.SecurityPolicy == nil || gw.SecurityPolicy.OIDC == nil {
+		return false
+	}
+	return gw.SecurityPolicy.OIDC.ClientSecretRef.Name == secretName
+}
+
 func isReferencedImagePullSecret(cfg *gatewayv1alpha1.GatewayServiceConfig, secretName string) bool {
 	if cfg.Spec.EnvoyGateway.Images == nil {
 		return false
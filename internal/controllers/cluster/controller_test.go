@@ -2,27 +2,41 @@ package cluster
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
 	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	"github.com/openmcp-project/controller-utils/pkg/clusters"
 	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
 	commonapi "github.com/openmcp-project/openmcp-operator/api/common"
+	openmcpconst "github.com/openmcp-project/openmcp-operator/api/constants"
 	accesslib "github.com/openmcp-project/openmcp-operator/lib/clusteraccess/advanced"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	gatewayv1alpha1 "github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
 	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
 )
 
 var (
@@ -53,6 +67,25 @@ var (
 				Namespace: "bar",
 			},
 		},
+		{
+			ClusterRefs: []gatewayv1alpha1.ClusterRef{
+				{
+					Name:      "alpha",
+					Namespace: "bar",
+				},
+				{
+					Name: "beta",
+				},
+			},
+		},
+		{
+			ExcludeSelector: &gatewayv1alpha1.ClusterSelector{
+				MatchLabels: map[string]string{
+					"gateway": "false",
+				},
+				MatchPurpose: "platform",
+			},
+		},
 	}
 
 	reqSample = reconcile.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "test"}}
@@ -73,6 +106,50 @@ func Test_shouldReconcile(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			desc: "should not reconcile cluster matching both a selector and an exclude selector",
+			cluster: &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"gateway": "false",
+					},
+				},
+				Spec: clustersv1alpha1.ClusterSpec{
+					Purposes: []string{"platform"},
+				},
+			},
+			expected: false,
+		},
+		{
+			desc: "should not reconcile cluster matching both a ref and an exclude selector",
+			cluster: &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "bar",
+					Labels: map[string]string{
+						"gateway": "false",
+					},
+				},
+				Spec: clustersv1alpha1.ClusterSpec{
+					Purposes: []string{"platform"},
+				},
+			},
+			expected: false,
+		},
+		{
+			desc: "should reconcile cluster matching the exclude selector only partially",
+			cluster: &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"gateway": "maybe",
+					},
+				},
+				Spec: clustersv1alpha1.ClusterSpec{
+					Purposes: []string{"platform"},
+				},
+			},
+			expected: true,
+		},
 		{
 			desc: "should reconcile cluster with matching labels",
 			cluster: &clustersv1alpha1.Cluster{
@@ -147,6 +224,36 @@ func Test_shouldReconcile(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			desc: "should reconcile cluster with matching ref in list",
+			cluster: &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "alpha",
+					Namespace: "bar",
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should reconcile cluster with matching ref in list in default namespace",
+			cluster: &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "beta",
+					Namespace: "default",
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should not reconcile cluster with wrong ref in list",
+			cluster: &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "beta",
+					Namespace: "bar",
+				},
+			},
+			expected: false,
+		},
 		{
 			desc: "should reconcile cluster with wrong ref but has finalizer",
 			cluster: &clustersv1alpha1.Cluster{
@@ -182,12 +289,51 @@ func Test_shouldReconcile(t *testing.T) {
 				ProviderName:    "gateway",
 			}
 
-			actual := r.shouldReconcile(tC.cluster)
+			actual := r.shouldReconcile(t.Context(), tC.cluster)
 			assert.Equal(t, tC.expected, actual)
 		})
 	}
 }
 
+func Test_shouldReconcile_orphanedGateway(t *testing.T) {
+	cluster := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "orphan",
+			Namespace: "test",
+		},
+	}
+	platformClient := fake.NewClientBuilder().
+		WithScheme(schemes.Platform).
+		WithObjects(
+			&gatewayv1alpha1.GatewayServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gateway",
+				},
+				Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+					Clusters: terms,
+				},
+			},
+		).
+		Build()
+
+	r := &ClusterReconciler{
+		PlatformCluster: clusters.NewTestClusterFromClient("test", platformClient),
+		ProviderName:    "gateway",
+	}
+	assert.False(t, r.shouldReconcile(t.Context(), cluster))
+
+	// the cluster neither matches nor has a finalizer, but the gateway is still installed
+	err := platformClient.Create(t.Context(), &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "orphan.gateway",
+			Namespace: "test",
+		},
+	})
+	if assert.NoError(t, err) {
+		assert.True(t, r.shouldReconcile(t.Context(), cluster))
+	}
+}
+
 func Test_isReferencedImagePullSecret(t *testing.T) {
 	testCases := []struct {
 		desc       string
@@ -256,6 +402,101 @@ func Test_isReferencedImagePullSecret(t *testing.T) {
 	}
 }
 
+func Test_isReferencedSecret(t *testing.T) {
+	cfg := &gatewayv1alpha1.GatewayServiceConfig{
+		Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+			EnvoyGateway: gatewayv1alpha1.EnvoyGatewayConfig{
+				Images: &gatewayv1alpha1.ImagesConfig{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull-secret"}},
+				},
+			},
+			Gateway: &gatewayv1alpha1.GatewayConfig{
+				SecurityPolicy: &gatewayv1alpha1.SecurityPolicyConfig{
+					OIDC: &gatewayv1alpha1.OIDCConfig{
+						ClientSecretRef: corev1.LocalObjectReference{Name: "oidc-client"},
+					},
+				},
+			},
+		},
+	}
+	assert.True(t, isReferencedSecret(cfg, "pull-secret"))
+	assert.True(t, isReferencedSecret(cfg, "oidc-client"))
+	assert.False(t, isReferencedSecret(cfg, "other"))
+
+	cfg.Spec.Gateway = nil
+	assert.False(t, isReferencedSecret(cfg, "oidc-client"))
+}
+
+func Test_gatewayServiceConfigToRequests(t *testing.T) {
+	const providerName = "gateway"
+	newCluster := func(name string, purpose string, finalizers ...string) *clustersv1alpha1.Cluster {
+		return &clustersv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "test-ns",
+				Finalizers: finalizers,
+			},
+			Spec: clustersv1alpha1.ClusterSpec{
+				Purposes: []string{purpose},
+			},
+		}
+	}
+	cfg := &gatewayv1alpha1.GatewayServiceConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: providerName,
+		},
+		Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+			Clusters: []gatewayv1alpha1.ClusterTerm{
+				{Selector: &gatewayv1alpha1.ClusterSelector{MatchPurpose: "platform"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		obj      client.Object
+		expected []string
+	}{
+		{
+			desc:     "should enqueue matching clusters and clusters with the gateway installed",
+			obj:      cfg,
+			expected: []string{"matching", "no-longer-matching"},
+		},
+		{
+			desc: "should ignore config of other providers",
+			obj: &gatewayv1alpha1.GatewayServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "other",
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			platformClient := fake.NewClientBuilder().
+				WithScheme(schemes.Platform).
+				WithObjects(
+					cfg,
+					newCluster("matching", "platform"),
+					newCluster("no-longer-matching", "workload", gatewayv1alpha1.GatewayFinalizerOnCluster),
+					newCluster("not-matching", "workload"),
+				).
+				Build()
+			r := &ClusterReconciler{
+				PlatformCluster: clusters.NewTestClusterFromClient("platform", platformClient),
+				ProviderName:    providerName,
+			}
+
+			requests := r.gatewayServiceConfigToRequests(t.Context(), tC.obj)
+			names := []string{}
+			for _, req := range requests {
+				names = append(names, req.Name)
+			}
+			assert.ElementsMatch(t, tC.expected, names)
+		})
+	}
+}
+
 func Test_mapSecretToClusters(t *testing.T) {
 	const (
 		secretName   = "my-pull-secret"
@@ -420,7 +661,7 @@ func mapSecretToClusterRequests(ctx context.Context, r *ClusterReconciler, secre
 		return nil
 	}
 
-	if !isReferencedImagePullSecret(cfg, secret.Name) {
+	if !isReferencedSecret(cfg, secret.Name) {
 		return nil
 	}
 
@@ -431,7 +672,7 @@ func mapSecretToClusterRequests(ctx context.Context, r *ClusterReconciler, secre
 
 	var requests []reconcile.Request
 	for _, cluster := range clusterList.Items {
-		if r.shouldReconcile(&cluster) {
+		if r.shouldReconcile(ctx, &cluster) {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      cluster.Name,
@@ -495,27 +736,7 @@ func Test_ClusterReconciler_Reconcile(t *testing.T) {
 				WithScheme(schemes.Platform).
 				Build()
 
-			cr := &ClusterReconciler{
-				PlatformCluster:   clusters.NewTestClusterFromClient("platform", platformClient),
-				eventRecorder:     events.NewFakeRecorder(100),
-				ProviderName:      "gateway",
-				ProviderNamespace: "test",
-				ClusterAccessReconciler: accesslib.NewClusterAccessReconciler(platformClient, ControllerName).
-					WithFakeClientGenerator(func(ctx context.Context, kcfgData []byte, scheme *runtime.Scheme, additionalData ...any) (client.Client, error) {
-						return clusterClient, nil
-					}).Register(accesslib.ExistingCluster("test", "", accesslib.IdentityReferenceGenerator).
-					WithTokenAccess(&clustersv1alpha1.TokenConfig{
-						RoleRefs: []commonapi.RoleRef{
-							{
-								Kind: "ClusterRole",
-								Name: "cluster-admin",
-							},
-						},
-					}).
-					WithNamespaceGenerator(accesslib.RequestNamespaceGenerator).
-					WithScheme(schemes.Target).
-					Build()),
-			}
+			cr := newTestClusterReconciler(platformClient, clusterClient, events.NewFakeRecorder(100))
 
 			ctx := logr.NewContext(t.Context(), logr.New(nil))
 			res, err := cr.Reconcile(ctx, tC.req)
@@ -528,3 +749,1170 @@ func Test_ClusterReconciler_Reconcile(t *testing.T) {
 		})
 	}
 }
+
+func Test_ClusterReconciler_reconcile_withoutGatewayServiceConfig(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        *gatewayv1alpha1.GatewayServiceConfig
+		finalizers    []string
+		deleting      bool
+		expectedEvent string
+	}{
+		{
+			desc:       "should leave the cluster untouched when the config is missing",
+			finalizers: []string{gatewayv1alpha1.GatewayFinalizerOnCluster},
+		},
+		{
+			desc:          "should warn about the blocked deletion when the config is missing",
+			finalizers:    []string{gatewayv1alpha1.GatewayFinalizerOnCluster},
+			deleting:      true,
+			expectedEvent: reasonConfigMissing,
+		},
+		{
+			desc: "should ignore the cluster when the config is empty",
+			config: &gatewayv1alpha1.GatewayServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gateway",
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cluster := &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       reqSample.Name,
+					Namespace:  reqSample.Namespace,
+					Finalizers: tC.finalizers,
+				},
+			}
+			if tC.deleting {
+				cluster.DeletionTimestamp = ptr.To(metav1.Now())
+			}
+			objs := []client.Object{cluster}
+			if tC.config != nil {
+				objs = append(objs, tC.config)
+			}
+			platformClient := fake.NewClientBuilder().
+				WithObjects(objs...).
+				WithScheme(schemes.Platform).
+				Build()
+			clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+			recorder := events.NewFakeRecorder(100)
+			cr := newTestClusterReconciler(platformClient, clusterClient, recorder)
+			ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+			res, err := cr.reconcile(ctx, reqSample)
+			assert.NoError(t, err)
+			assert.Equal(t, controllerruntime.Result{}, res)
+
+			actual := &clustersv1alpha1.Cluster{}
+			if assert.NoError(t, platformClient.Get(ctx, reqSample.NamespacedName, actual)) {
+				assert.Equal(t, tC.finalizers, actual.Finalizers)
+			}
+			if tC.expectedEvent == "" {
+				assert.Empty(t, recorder.Events)
+			} else if assert.Len(t, recorder.Events, 1) {
+				assert.Contains(t, <-recorder.Events, "Warning "+tC.expectedEvent)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_Reconcile_health(t *testing.T) {
+	cluster := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reqSample.Name,
+			Namespace: reqSample.Namespace,
+		},
+	}
+	platformClient := fake.NewClientBuilder().
+		WithObjects(
+			&gatewayv1alpha1.GatewayServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gateway",
+				},
+				Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+					Clusters: terms,
+				},
+			},
+			cluster,
+		).
+		WithScheme(schemes.Platform).
+		Build()
+	clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+	cr := newTestClusterReconciler(platformClient, clusterClient, events.NewFakeRecorder(100))
+	cr.Health = &ReconcileHealth{}
+	ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+	// a cluster which is not selected is not counted
+	cr.Health.Track(reqSample.NamespacedName)
+	_, err := cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+	assert.NotContains(t, cr.Health.failingSince, reqSample.NamespacedName)
+
+	// a deleted cluster is not counted
+	cr.Health.Track(reqSample.NamespacedName)
+	assert.NoError(t, platformClient.Delete(ctx, cluster))
+	_, err = cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+	assert.NotContains(t, cr.Health.failingSince, reqSample.NamespacedName)
+
+	// retried transient errors are counted as failures
+	timeoutClient := interceptor.NewClient(fake.NewClientBuilder().WithScheme(schemes.Platform).Build(), interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return apierrors.NewTimeoutError("request timed out", 1)
+		},
+	})
+	cr = newTestClusterReconciler(timeoutClient, clusterClient, events.NewFakeRecorder(100))
+	cr.Health = &ReconcileHealth{}
+	cr.Health.Track(reqSample.NamespacedName)
+	res, err := cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+	assert.NotZero(t, res.RequeueAfter)
+	assert.False(t, cr.Health.failingSince[reqSample.NamespacedName].IsZero(), "expected timeout to be recorded as failure")
+}
+
+func Test_ClusterReconciler_Reconcile_forgetsDeletedCluster(t *testing.T) {
+	platformClient := fake.NewClientBuilder().WithScheme(schemes.Platform).Build()
+	c := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reqSample.Name,
+			Namespace: reqSample.Namespace,
+		},
+	}
+	cr := newTestClusterReconciler(platformClient, fake.NewClientBuilder().Build(), events.NewFakeRecorder(100))
+	cr.Health = &ReconcileHealth{}
+	ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+	// collect state for the cluster as if it had been reconciled before
+	cr.Health.Track(reqSample.NamespacedName)
+	cr.startWaitingForAccess(reqSample)
+	cr.recordWaitingForGatewayCRDs(c, errors.New("no matches for kind \"GatewayClass\""))
+	cr.recordRemainingResources(c, utils.NewRemainingResourcesError(time.Second, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: "default"}}))
+	cr.recordAccessEstablished(c, &clustersv1alpha1.AccessRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "access", Namespace: "default"},
+		Status:     clustersv1alpha1.AccessRequestStatus{SecretRef: &commonapi.LocalObjectReference{Name: "kubeconfig"}},
+	})
+	cr.recordGatewayAddresses(c, []string{"192.0.2.1"})
+	cr.recordPlatformClusterRefused(c)
+	series := testutil.CollectAndCount(waitingForGatewayCRDsTotal)
+
+	// the cluster doesn't exist anymore
+	_, err := cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+	assert.NotContains(t, cr.Health.failingSince, reqSample.NamespacedName)
+	assert.Empty(t, cr.waitingForAccessSince)
+	assert.Empty(t, cr.waitingForCRDsEvents)
+	assert.Empty(t, cr.remainingResourcesEvents)
+	assert.Empty(t, cr.accessEstablishedEvents)
+	assert.Empty(t, cr.gatewayAddressEvents)
+	assert.Empty(t, cr.platformClusterRefusedEvents)
+	assert.Equal(t, series-1, testutil.CollectAndCount(waitingForGatewayCRDsTotal))
+}
+
+func newTestClusterReconciler(platformClient, clusterClient client.Client, recorder events.EventRecorder) *ClusterReconciler {
+	return &ClusterReconciler{
+		PlatformCluster:   clusters.NewTestClusterFromClient("platform", platformClient),
+		eventRecorder:     recorder,
+		ProviderName:      "gateway",
+		ProviderNamespace: "test",
+		ClusterAccessReconciler: accesslib.NewClusterAccessReconciler(platformClient, ControllerName).
+			WithFakeClientGenerator(func(ctx context.Context, kcfgData []byte, scheme *runtime.Scheme, additionalData ...any) (client.Client, error) {
+				return clusterClient, nil
+			}).Register(accesslib.ExistingCluster(clusterId, "", accesslib.IdentityReferenceGenerator).
+			WithTokenAccess(&clustersv1alpha1.TokenConfig{
+				RoleRefs: []commonapi.RoleRef{
+					{
+						Kind: "ClusterRole",
+						Name: "cluster-admin",
+					},
+				},
+			}).
+			WithNamespaceGenerator(accesslib.RequestNamespaceGenerator).
+			WithScheme(schemes.Target).
+			Build()),
+	}
+}
+
+func Test_ClusterReconciler_recordGatewayAddresses(t *testing.T) {
+	c := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "addresses",
+			Namespace: reqSample.Namespace,
+		},
+	}
+	recorder := events.NewFakeRecorder(100)
+	cr := newTestClusterReconciler(fake.NewClientBuilder().Build(), fake.NewClientBuilder().Build(), recorder)
+	drain := func() []string {
+		var reasons []string
+		for len(recorder.Events) > 0 {
+			reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+		}
+		return reasons
+	}
+
+	// a missing address is reported once
+	for range 3 {
+		cr.recordGatewayAddresses(c, nil)
+	}
+	assert.Equal(t, []string{reasonGatewayAddressMissing, reasonGatewayInstalled}, drain())
+
+	// the addresses are reported once they are assigned
+	for range 3 {
+		cr.recordGatewayAddresses(c, []string{"192.0.2.1"})
+	}
+	assert.Equal(t, []string{reasonGatewayProgrammed, reasonGatewayInstalled}, drain())
+
+	// changed addresses are reported again
+	cr.recordGatewayAddresses(c, []string{"192.0.2.2"})
+	assert.Equal(t, []string{reasonGatewayProgrammed, reasonGatewayInstalled}, drain())
+
+	// the addresses are reported again after the gateway was uninstalled
+	cr.forgetCluster(client.ObjectKeyFromObject(c))
+	cr.recordGatewayAddresses(c, []string{"192.0.2.2"})
+	assert.Equal(t, []string{reasonGatewayProgrammed, reasonGatewayInstalled}, drain())
+}
+
+func Test_ClusterReconciler_recordWaitingForGatewayCRDs(t *testing.T) {
+	c := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "waiting-for-crds",
+			Namespace: reqSample.Namespace,
+		},
+	}
+	recorder := events.NewFakeRecorder(100)
+	cr := newTestClusterReconciler(fake.NewClientBuilder().Build(), fake.NewClientBuilder().Build(), recorder)
+	counter := waitingForGatewayCRDsTotal.WithLabelValues(client.ObjectKeyFromObject(c).String())
+	errCRDNotFound := fmt.Errorf("no matches for kind \"GatewayClass\"")
+
+	// the first occurrence is reported, subsequent retries are throttled
+	for range 3 {
+		cr.recordWaitingForGatewayCRDs(c, errCRDNotFound)
+	}
+	assert.Equal(t, 3.0, testutil.ToFloat64(counter))
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, reasonWaitingForCRDs)
+	}
+
+	// the event is emitted again once the interval has passed
+	cr.waitingForCRDsEvents[client.ObjectKeyFromObject(c)] = time.Now().Add(-defaultWaitingForCRDsEventInterval)
+	cr.recordWaitingForGatewayCRDs(c, errCRDNotFound)
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// the event is emitted immediately again after the gateway could be configured
+	cr.resetWaitingForGatewayCRDs(c)
+	cr.recordWaitingForGatewayCRDs(c, errCRDNotFound)
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, 5.0, testutil.ToFloat64(counter))
+}
+
+func Test_ClusterReconciler_recordRemainingResources(t *testing.T) {
+	c := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "remaining-resources",
+			Namespace: reqSample.Namespace,
+		},
+	}
+	recorder := events.NewFakeRecorder(100)
+	cr := newTestClusterReconciler(fake.NewClientBuilder().Build(), fake.NewClientBuilder().Build(), recorder)
+	gateway := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"}}
+	proxy := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: "default"}}
+
+	// other errors are not reported
+	cr.recordRemainingResources(c, errors.New("unexpected"))
+	assert.Empty(t, recorder.Events)
+
+	// the first occurrence is reported, retries with the same resources are not
+	for range 3 {
+		cr.recordRemainingResources(c, utils.NewRemainingResourcesError(time.Second, gateway, proxy))
+	}
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, reasonRemainingResources)
+	}
+
+	// the order of the resources doesn't matter
+	cr.recordRemainingResources(c, utils.NewRemainingResourcesError(time.Second, proxy, gateway))
+	assert.Empty(t, recorder.Events)
+
+	// a change of the remaining resources is reported
+	cr.recordRemainingResources(c, utils.NewRemainingResourcesError(time.Second, proxy))
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, "Service/default/proxy")
+	}
+
+	// the event is emitted again after the gateway was uninstalled
+	cr.forgetCluster(client.ObjectKeyFromObject(c))
+	cr.recordRemainingResources(c, utils.NewRemainingResourcesError(time.Second, proxy))
+	assert.Len(t, recorder.Events, 1)
+}
+
+func Test_ClusterReconciler_isPlatformCluster(t *testing.T) {
+	kubeSystem := func(uid types.UID) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: uid}}
+	}
+	testCases := []struct {
+		desc         string
+		platformObjs []client.Object
+		clusterObjs  []client.Object
+		expected     bool
+	}{
+		{
+			desc:         "should match the same kube-system namespace",
+			platformObjs: []client.Object{kubeSystem("platform")},
+			clusterObjs:  []client.Object{kubeSystem("platform")},
+			expected:     true,
+		},
+		{
+			desc:         "should not match other cluster",
+			platformObjs: []client.Object{kubeSystem("platform")},
+			clusterObjs:  []client.Object{kubeSystem("workload")},
+		},
+		{
+			desc:        "should not match without kube-system namespace in the platform cluster",
+			clusterObjs: []client.Object{kubeSystem("")},
+		},
+		{
+			desc:         "should not match without kube-system namespace in the cluster",
+			platformObjs: []client.Object{kubeSystem("platform")},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r := &ClusterReconciler{
+				PlatformCluster: clusters.NewTestClusterFromClient("platform", fake.NewClientBuilder().WithObjects(tC.platformObjs...).Build()),
+			}
+			actual, err := r.isPlatformCluster(t.Context(), fake.NewClientBuilder().WithObjects(tC.clusterObjs...).Build())
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_Reconcile_platformCluster(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		allowed       bool
+		expectRefused bool
+	}{
+		{
+			desc:          "should refuse platform cluster by default",
+			expectRefused: true,
+		},
+		{
+			desc:    "should reconcile platform cluster if allowed",
+			allowed: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			kubeSystem := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "platform"}}
+			platformClient := fake.NewClientBuilder().
+				WithObjects(
+					&gatewayv1alpha1.GatewayServiceConfig{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "gateway",
+						},
+						Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+							Clusters:             terms,
+							AllowPlatformCluster: tC.allowed,
+							EnvoyGateway: gatewayv1alpha1.EnvoyGatewayConfig{
+								Chart: gatewayv1alpha1.EnvoyGatewayChart{
+									URL: "oci://docker.io/envoyproxy/gateway-helm",
+									Tag: "1.5.4",
+								},
+							},
+							DNS: gatewayv1alpha1.DNSConfig{
+								BaseDomain: "example.com",
+							},
+						},
+					},
+					&clustersv1alpha1.Cluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      reqSample.Name,
+							Namespace: reqSample.Namespace,
+						},
+						Spec: clustersv1alpha1.ClusterSpec{
+							Purposes: []string{"platform"},
+						},
+					},
+					kubeSystem,
+				).
+				WithStatusSubresource(&clustersv1alpha1.AccessRequest{}).
+				WithScheme(schemes.Platform).
+				Build()
+			// the access to the cluster leads to the platform cluster itself
+			clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).WithObjects(kubeSystem.DeepCopy()).Build()
+			recorder := events.NewFakeRecorder(100)
+			cr := newTestClusterReconciler(platformClient, clusterClient, recorder)
+			ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+			// the cluster is identified via the access to it
+			_, err := cr.Reconcile(ctx, reqSample)
+			assert.NoError(t, err)
+			grantClusterAccess(t, ctx, cr, platformClient)
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+
+			for range 2 {
+				res, err := cr.Reconcile(ctx, reqSample)
+				assert.NoError(t, err)
+				if tC.expectRefused {
+					assert.Equal(t, controllerruntime.Result{}, res)
+				}
+			}
+
+			hr := &helmv2.HelmRelease{}
+			err = platformClient.Get(t.Context(), types.NamespacedName{Name: reqSample.Name + ".gateway", Namespace: reqSample.Namespace}, hr)
+			if !tC.expectRefused {
+				assert.NoError(t, err, "expected the gateway to be installed")
+				return
+			}
+			assert.True(t, apierrors.IsNotFound(err), "expected the gateway not to be installed")
+			refusals := 0
+			for len(recorder.Events) > 0 {
+				if strings.Contains(<-recorder.Events, reasonPlatformCluster) {
+					refusals++
+				}
+			}
+			assert.Equal(t, 1, refusals, "expected a single %s event", reasonPlatformCluster)
+		})
+	}
+}
+
+func Test_ClusterReconciler_resolveChartTag(t *testing.T) {
+	versionFrom := &gatewayv1alpha1.ConfigMapKeyReference{Name: "envoy-gateway-version", Key: "tag"}
+	newConfigMap := func(tag string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      versionFrom.Name,
+				Namespace: "test",
+			},
+			Data: map[string]string{"tag": tag},
+		}
+	}
+	testCases := []struct {
+		desc          string
+		chart         gatewayv1alpha1.EnvoyGatewayChart
+		objs          []client.Object
+		expectedTag   string
+		expectedErr   error
+		expectedEvent string
+	}{
+		{
+			desc:        "should keep inline tag without VersionFrom",
+			chart:       gatewayv1alpha1.EnvoyGatewayChart{Tag: "1.5.4"},
+			expectedTag: "1.5.4",
+		},
+		{
+			desc:        "should read tag from ConfigMap",
+			chart:       gatewayv1alpha1.EnvoyGatewayChart{VersionFrom: versionFrom},
+			objs:        []client.Object{newConfigMap("1.6.0\n")},
+			expectedTag: "1.6.0",
+		},
+		{
+			desc:          "should prefer inline tag",
+			chart:         gatewayv1alpha1.EnvoyGatewayChart{Tag: "1.5.4", VersionFrom: versionFrom},
+			objs:          []client.Object{newConfigMap("1.6.0")},
+			expectedTag:   "1.5.4",
+			expectedEvent: reasonChartTagOverridden,
+		},
+		{
+			desc:        "should fail if ConfigMap is missing",
+			chart:       gatewayv1alpha1.EnvoyGatewayChart{VersionFrom: versionFrom},
+			expectedErr: errFailedToResolveChartTag,
+		},
+		{
+			desc:        "should fail if key is empty",
+			chart:       gatewayv1alpha1.EnvoyGatewayChart{VersionFrom: versionFrom},
+			objs:        []client.Object{newConfigMap("")},
+			expectedErr: errFailedToResolveChartTag,
+		},
+		{
+			desc: "should validate resolved tag",
+			chart: gatewayv1alpha1.EnvoyGatewayChart{
+				VersionFrom: versionFrom,
+				AllowedTags: []string{"1.5.4"},
+			},
+			objs:          []client.Object{newConfigMap("1.6.0")},
+			expectedErr:   errInvalidGatewayServiceConfig,
+			expectedEvent: reasonInvalidConfig,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			platformClient := fake.NewClientBuilder().WithObjects(tC.objs...).Build()
+			recorder := events.NewFakeRecorder(100)
+			cr := newTestClusterReconciler(platformClient, fake.NewClientBuilder().Build(), recorder)
+
+			chart := tC.chart
+			err := cr.resolveChartTag(t.Context(), &clustersv1alpha1.Cluster{}, &chart)
+			if tC.expectedErr != nil {
+				assert.ErrorIs(t, err, tC.expectedErr)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedTag, chart.Tag)
+			}
+			if tC.expectedEvent == "" {
+				assert.Empty(t, recorder.Events)
+			} else if assert.Len(t, recorder.Events, 1) {
+				assert.Contains(t, <-recorder.Events, tC.expectedEvent)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_Reconcile_frozen(t *testing.T) {
+	cluster := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reqSample.Name,
+			Namespace: reqSample.Namespace,
+			Annotations: map[string]string{
+				gatewayv1alpha1.FreezeAnnotation: "true",
+			},
+			Finalizers: []string{gatewayv1alpha1.GatewayFinalizerOnCluster},
+		},
+	}
+	platformClient := fake.NewClientBuilder().
+		WithObjects(
+			&gatewayv1alpha1.GatewayServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gateway",
+				},
+				// the cluster is no longer matched, which would uninstall the gateway if it was not frozen
+				Spec: gatewayv1alpha1.GatewayServiceConfigSpec{},
+			},
+			cluster,
+		).
+		WithScheme(schemes.Platform).
+		Build()
+	clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+	recorder := events.NewFakeRecorder(100)
+	cr := newTestClusterReconciler(platformClient, clusterClient, recorder)
+
+	res, err := cr.Reconcile(logr.NewContext(t.Context(), logr.New(nil)), reqSample)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerruntime.Result{}, res)
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, reasonGatewayFrozen)
+	}
+
+	actual := &clustersv1alpha1.Cluster{}
+	if assert.NoError(t, platformClient.Get(t.Context(), reqSample.NamespacedName, actual)) {
+		assert.Contains(t, actual.Finalizers, gatewayv1alpha1.GatewayFinalizerOnCluster)
+	}
+
+	// no AccessRequest is created for a frozen cluster
+	ars := &clustersv1alpha1.AccessRequestList{}
+	if assert.NoError(t, platformClient.List(t.Context(), ars)) {
+		assert.Empty(t, ars.Items)
+	}
+}
+
+func Test_ClusterReconciler_blockMassUninstall(t *testing.T) {
+	managedCluster := func(name string, labels map[string]string) *clustersv1alpha1.Cluster {
+		return &clustersv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  reqSample.Namespace,
+				Labels:     labels,
+				Finalizers: []string{gatewayv1alpha1.GatewayFinalizerOnCluster},
+			},
+		}
+	}
+	testCases := []struct {
+		desc            string
+		threshold       int
+		annotations     map[string]string
+		cluster         *clustersv1alpha1.Cluster
+		expectedBlocked bool
+	}{
+		{
+			desc:    "should not block without threshold",
+			cluster: managedCluster(reqSample.Name, nil),
+		},
+		{
+			desc:            "should block when threshold is exceeded",
+			threshold:       1,
+			cluster:         managedCluster(reqSample.Name, nil),
+			expectedBlocked: true,
+		},
+		{
+			desc:      "should not block when threshold is not exceeded",
+			threshold: 2,
+			cluster:   managedCluster(reqSample.Name, nil),
+		},
+		{
+			desc:      "should not block when confirmed",
+			threshold: 1,
+			annotations: map[string]string{
+				gatewayv1alpha1.ConfirmUninstallAnnotation: "3",
+			},
+			cluster: managedCluster(reqSample.Name, nil),
+		},
+		{
+			desc:      "should block when confirmed for another generation",
+			threshold: 1,
+			annotations: map[string]string{
+				gatewayv1alpha1.ConfirmUninstallAnnotation: "2",
+			},
+			cluster:         managedCluster(reqSample.Name, nil),
+			expectedBlocked: true,
+		},
+		{
+			desc:      "should not block a matching cluster",
+			threshold: 1,
+			cluster:   managedCluster(reqSample.Name, map[string]string{"gateway": "true"}),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			platformClient := fake.NewClientBuilder().
+				WithObjects(
+					&gatewayv1alpha1.GatewayServiceConfig{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "gateway",
+							Generation:  3,
+							Annotations: tC.annotations,
+						},
+						Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+							Clusters: terms,
+						},
+					},
+					tC.cluster,
+					// no longer matched
+					managedCluster("other", nil),
+					// still matched
+					managedCluster("matched", map[string]string{"gateway": "true"}),
+				).
+				WithScheme(schemes.Platform).
+				Build()
+			recorder := events.NewFakeRecorder(100)
+			cr := newTestClusterReconciler(platformClient, nil, recorder)
+			cr.MassUninstallThreshold = tC.threshold
+
+			blocked, err := cr.blockMassUninstall(logr.NewContext(t.Context(), logr.New(nil)), tC.cluster)
+			assert.NoError(t, err)
+			assert.Equal(t, tC.expectedBlocked, blocked)
+			if tC.expectedBlocked && assert.Len(t, recorder.Events, 1) {
+				event := <-recorder.Events
+				assert.Contains(t, event, reasonMassUninstallBlocked)
+				assert.Contains(t, event, gatewayv1alpha1.ConfirmUninstallAnnotation+"=3")
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_Reconcile_logsClusterIdentity(t *testing.T) {
+	cluster := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reqSample.Name,
+			Namespace: reqSample.Namespace,
+			Annotations: map[string]string{
+				gatewayv1alpha1.FreezeAnnotation: "true",
+			},
+			Finalizers: []string{gatewayv1alpha1.GatewayFinalizerOnCluster},
+		},
+	}
+	platformClient := fake.NewClientBuilder().
+		WithObjects(cluster).
+		WithScheme(schemes.Platform).
+		Build()
+	clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+	cr := newTestClusterReconciler(platformClient, clusterClient, events.NewFakeRecorder(100))
+
+	var lines []string
+	log := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: 10})
+
+	_, err := cr.Reconcile(logr.NewContext(t.Context(), log), reqSample)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, lines) {
+		for _, line := range lines {
+			assert.Contains(t, line, fmt.Sprintf(`"cluster"="%s"`, reqSample.String()))
+		}
+	}
+}
+
+func Test_ClusterReconciler_Reconcile_removesReconcileOperationAnnotation(t *testing.T) {
+	for _, annotation := range []string{gatewayv1alpha1.OperationAnnotation, openmcpconst.OperationAnnotation} {
+		t.Run(annotation, func(t *testing.T) {
+			cluster := &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      reqSample.Name,
+					Namespace: reqSample.Namespace,
+					Annotations: map[string]string{
+						annotation: openmcpconst.OperationAnnotationValueReconcile,
+					},
+				},
+				Spec: clustersv1alpha1.ClusterSpec{
+					Purposes: []string{"platform"},
+				},
+			}
+			platformClient := fake.NewClientBuilder().
+				WithObjects(
+					&gatewayv1alpha1.GatewayServiceConfig{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "gateway",
+						},
+						Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+							Clusters: terms,
+							EnvoyGateway: gatewayv1alpha1.EnvoyGatewayConfig{
+								Chart: gatewayv1alpha1.EnvoyGatewayChart{
+									URL: "oci://docker.io/envoyproxy/gateway-helm",
+									Tag: "1.5.4",
+								},
+							},
+							DNS: gatewayv1alpha1.DNSConfig{
+								BaseDomain: "example.com",
+							},
+						},
+					},
+					cluster,
+				).
+				WithStatusSubresource(&clustersv1alpha1.AccessRequest{}).
+				WithScheme(schemes.Platform).
+				Build()
+			clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+			cr := newTestClusterReconciler(platformClient, clusterClient, events.NewFakeRecorder(100))
+			ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+			// the annotation is kept while the request cannot be forwarded to Flux
+			_, err := cr.Reconcile(ctx, reqSample)
+			assert.NoError(t, err)
+			actual := &clustersv1alpha1.Cluster{}
+			if assert.NoError(t, platformClient.Get(t.Context(), reqSample.NamespacedName, actual)) {
+				assert.Contains(t, actual.Annotations, annotation)
+			}
+
+			grantClusterAccess(t, ctx, cr, platformClient)
+			_, err = cr.Reconcile(ctx, reqSample)
+			assert.NoError(t, err)
+
+			hr := &helmv2.HelmRelease{}
+			if assert.NoError(t, platformClient.Get(t.Context(), types.NamespacedName{Name: cluster.Name + ".gateway", Namespace: cluster.Namespace}, hr)) {
+				assert.NotEmpty(t, hr.Annotations[fluxmeta.ReconcileRequestAnnotation])
+			}
+			if assert.NoError(t, platformClient.Get(t.Context(), reqSample.NamespacedName, actual)) {
+				assert.NotContains(t, actual.Annotations, annotation)
+			}
+		})
+	}
+}
+
+// grantClusterAccess grants the AccessRequest of reqSample, which has to be created by a reconciliation before.
+func grantClusterAccess(t *testing.T, ctx context.Context, cr *ClusterReconciler, platformClient client.Client) *clustersv1alpha1.AccessRequest {
+	t.Helper()
+	ar, err := cr.ClusterAccessReconciler.AccessRequest(ctx, reqSample, clusterId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubeconfig",
+			Namespace: ar.Namespace,
+		},
+		Data: map[string][]byte{
+			clustersv1alpha1.SecretKeyKubeconfig: []byte("kubeconfig"),
+		},
+	}
+	assert.NoError(t, platformClient.Create(ctx, kubeconfig))
+	ar.Status.Phase = clustersv1alpha1.REQUEST_GRANTED
+	ar.Status.SecretRef = &commonapi.LocalObjectReference{Name: kubeconfig.Name}
+	assert.NoError(t, platformClient.Status().Update(ctx, ar))
+	return ar
+}
+
+func Test_ClusterReconciler_Reconcile_clusterAccessTimeout(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		clusterAccess *gatewayv1alpha1.ClusterAccessConfig
+		expectTimeout bool
+	}{
+		{
+			desc: "should keep waiting for cluster access without timeout",
+		},
+		{
+			desc: "should stop waiting for cluster access after timeout",
+			clusterAccess: &gatewayv1alpha1.ClusterAccessConfig{
+				Timeout: &metav1.Duration{Duration: time.Nanosecond},
+			},
+			expectTimeout: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			platformClient := fake.NewClientBuilder().
+				WithObjects(
+					&gatewayv1alpha1.GatewayServiceConfig{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "gateway",
+						},
+						Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+							Clusters:      terms,
+							ClusterAccess: tC.clusterAccess,
+						},
+					},
+					&clustersv1alpha1.Cluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      reqSample.Name,
+							Namespace: reqSample.Namespace,
+						},
+						Spec: clustersv1alpha1.ClusterSpec{
+							Purposes: []string{"platform"},
+						},
+					},
+				).
+				WithScheme(schemes.Platform).
+				Build()
+			clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+			recorder := events.NewFakeRecorder(100)
+			cr := newTestClusterReconciler(platformClient, clusterClient, recorder)
+
+			// the AccessRequest is never granted
+			res, err := cr.Reconcile(logr.NewContext(t.Context(), logr.New(nil)), reqSample)
+			assert.NoError(t, err)
+			if !tC.expectTimeout {
+				assert.Positive(t, res.RequeueAfter)
+				assert.Less(t, res.RequeueAfter, defaultDriftInterval)
+
+				// retries while still waiting don't repeat the event
+				_, err = cr.Reconcile(logr.NewContext(t.Context(), logr.New(nil)), reqSample)
+				assert.NoError(t, err)
+				if assert.Len(t, recorder.Events, 1) {
+					assert.Contains(t, <-recorder.Events, reasonWaitingForAccess)
+				}
+				return
+			}
+			assert.Equal(t, defaultDriftInterval, res.RequeueAfter)
+			if assert.Len(t, recorder.Events, 1) {
+				assert.Contains(t, <-recorder.Events, reasonAccessTimeout)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_checkClusterAccessTimeout(t *testing.T) {
+	timeout := &gatewayv1alpha1.ClusterAccessConfig{Timeout: &metav1.Duration{Duration: time.Minute}}
+	testCases := []struct {
+		desc        string
+		cfg         *gatewayv1alpha1.ClusterAccessConfig
+		waiting     time.Duration
+		reset       bool
+		expectedErr error
+	}{
+		{
+			desc:    "should not time out without timeout",
+			waiting: time.Hour,
+		},
+		{
+			desc:    "should not time out within the timeout",
+			cfg:     timeout,
+			waiting: time.Second,
+		},
+		{
+			desc:        "should time out after waiting for longer than the timeout",
+			cfg:         timeout,
+			waiting:     time.Hour,
+			expectedErr: errClusterAccessTimeout,
+		},
+		{
+			desc:    "should measure from the start of the current wait",
+			cfg:     timeout,
+			waiting: time.Hour,
+			reset:   true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			platformClient := fake.NewClientBuilder().WithScheme(schemes.Platform).Build()
+			cr := newTestClusterReconciler(platformClient, nil, events.NewFakeRecorder(100))
+			ctx := logr.NewContext(t.Context(), logr.New(nil))
+			cr.waitingForAccessSince = map[types.NamespacedName]time.Time{
+				reqSample.NamespacedName: time.Now().Add(-tC.waiting),
+			}
+			if tC.reset {
+				// access was available in between
+				cr.resetWaitingForAccess(reqSample)
+			}
+
+			since, _ := cr.startWaitingForAccess(reqSample)
+			err := cr.checkClusterAccessTimeout(ctx, reqSample, since, tC.cfg)
+			if tC.expectedErr != nil {
+				assert.ErrorIs(t, err, tC.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_Reconcile_clusterAccessEstablished(t *testing.T) {
+	platformClient := fake.NewClientBuilder().
+		WithObjects(
+			&gatewayv1alpha1.GatewayServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "gateway",
+				},
+				Spec: gatewayv1alpha1.GatewayServiceConfigSpec{
+					Clusters: terms,
+					EnvoyGateway: gatewayv1alpha1.EnvoyGatewayConfig{
+						Chart: gatewayv1alpha1.EnvoyGatewayChart{
+							URL: "oci://docker.io/envoyproxy/gateway-helm",
+							Tag: "1.5.4",
+						},
+					},
+					DNS: gatewayv1alpha1.DNSConfig{
+						BaseDomain: "example.com",
+					},
+				},
+			},
+			&clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      reqSample.Name,
+					Namespace: reqSample.Namespace,
+				},
+				Spec: clustersv1alpha1.ClusterSpec{
+					Purposes: []string{"platform"},
+				},
+			},
+		).
+		WithStatusSubresource(&clustersv1alpha1.AccessRequest{}).
+		WithScheme(schemes.Platform).
+		Build()
+	clusterClient := fake.NewClientBuilder().WithScheme(schemes.Target).Build()
+	recorder := events.NewFakeRecorder(100)
+	cr := newTestClusterReconciler(platformClient, clusterClient, recorder)
+	ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+	// the first reconciliation creates the AccessRequest
+	_, err := cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+
+	ar := grantClusterAccess(t, ctx, cr, platformClient)
+
+	_, err = cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+
+	var found bool
+	for len(recorder.Events) > 0 {
+		event := <-recorder.Events
+		if strings.Contains(event, reasonAccessEstablished) {
+			found = true
+			assert.Contains(t, event, fmt.Sprintf("Using kubeconfig Secret %s/kubeconfig of AccessRequest %s", ar.Namespace, ar.Name))
+		}
+	}
+	assert.True(t, found, "expected %s event", reasonAccessEstablished)
+
+	// the event is not repeated as long as the access doesn't change
+	_, err = cr.Reconcile(ctx, reqSample)
+	assert.NoError(t, err)
+	for len(recorder.Events) > 0 {
+		assert.NotContains(t, <-recorder.Events, reasonAccessEstablished)
+	}
+}
+
+func Test_ClusterReconciler_recordAccessEstablished(t *testing.T) {
+	c := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "access-established",
+			Namespace: reqSample.Namespace,
+		},
+	}
+	recorder := events.NewFakeRecorder(100)
+	cr := newTestClusterReconciler(fake.NewClientBuilder().Build(), fake.NewClientBuilder().Build(), recorder)
+	ar := &clustersv1alpha1.AccessRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "access", Namespace: "default"},
+		Status: clustersv1alpha1.AccessRequestStatus{
+			SecretRef: &commonapi.LocalObjectReference{Name: "kubeconfig"},
+		},
+	}
+
+	// the first occurrence is reported, reconciliations with the same access are not
+	for range 3 {
+		cr.recordAccessEstablished(c, ar)
+	}
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, "Using kubeconfig Secret default/kubeconfig of AccessRequest access")
+	}
+
+	// a rotated Secret is reported
+	ar.Status.SecretRef.Name = "kubeconfig-rotated"
+	cr.recordAccessEstablished(c, ar)
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, "default/kubeconfig-rotated")
+	}
+
+	// a recreated AccessRequest is reported
+	ar.Name = "access-recreated"
+	cr.recordAccessEstablished(c, ar)
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, "AccessRequest access-recreated")
+	}
+
+	// the event is emitted again after the gateway was uninstalled
+	cr.forgetCluster(client.ObjectKeyFromObject(c))
+	cr.recordAccessEstablished(c, ar)
+	assert.Len(t, recorder.Events, 1)
+}
+
+func Test_ClusterReconciler_updateFinalizer(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		conflicts   int
+		expectedErr bool
+	}{
+		{
+			desc: "should add the finalizer",
+		},
+		{
+			desc:      "should retry once on conflict",
+			conflicts: 1,
+		},
+		{
+			desc:        "should return a remaining conflict",
+			conflicts:   2,
+			expectedErr: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cluster := &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      reqSample.Name,
+					Namespace: reqSample.Namespace,
+				},
+			}
+			conflicts := tC.conflicts
+			platformClient := fake.NewClientBuilder().
+				WithObjects(cluster.DeepCopy()).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						if conflicts > 0 {
+							conflicts--
+							return apierrors.NewConflict(clustersv1alpha1.GroupVersion.WithResource("clusters").GroupResource(), obj.GetName(), errors.New("object has been modified"))
+						}
+						return c.Update(ctx, obj, opts...)
+					},
+				}).
+				WithScheme(schemes.Platform).
+				Build()
+			cr := newTestClusterReconciler(platformClient, nil, events.NewFakeRecorder(100))
+			ctx := logr.NewContext(t.Context(), logr.New(nil))
+			if !assert.NoError(t, platformClient.Get(ctx, reqSample.NamespacedName, cluster)) {
+				return
+			}
+
+			err := cr.updateFinalizer(ctx, cluster, controllerutil.AddFinalizer)
+			if tC.expectedErr {
+				assert.True(t, apierrors.IsConflict(err))
+				return
+			}
+			assert.NoError(t, err)
+
+			actual := &clustersv1alpha1.Cluster{}
+			if assert.NoError(t, platformClient.Get(ctx, reqSample.NamespacedName, actual)) {
+				assert.Contains(t, actual.Finalizers, gatewayv1alpha1.GatewayFinalizerOnCluster)
+			}
+		})
+	}
+}
+
+func Test_ClusterReconciler_updateAddressAnnotation(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		enabled            bool
+		annotations        map[string]string
+		addresses          []string
+		expectedAnnotation string
+	}{
+		{
+			desc:               "should write the addresses",
+			enabled:            true,
+			addresses:          []string{"203.0.113.10", "2001:db8::1"},
+			expectedAnnotation: "203.0.113.10,2001:db8::1",
+		},
+		{
+			desc:               "should update changed addresses",
+			enabled:            true,
+			annotations:        map[string]string{gatewayv1alpha1.AddressAnnotation: "203.0.113.10"},
+			addresses:          []string{"203.0.113.20"},
+			expectedAnnotation: "203.0.113.20",
+		},
+		{
+			desc:      "should not write the addresses when disabled",
+			addresses: []string{"203.0.113.10"},
+		},
+		{
+			desc:        "should remove the annotation when disabled",
+			annotations: map[string]string{gatewayv1alpha1.AddressAnnotation: "203.0.113.10"},
+			addresses:   []string{"203.0.113.10"},
+		},
+		{
+			desc:        "should remove the annotation without addresses",
+			enabled:     true,
+			annotations: map[string]string{gatewayv1alpha1.AddressAnnotation: "203.0.113.10"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cluster := &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        reqSample.Name,
+					Namespace:   reqSample.Namespace,
+					Annotations: tC.annotations,
+				},
+			}
+			platformClient := fake.NewClientBuilder().
+				WithObjects(cluster).
+				WithScheme(schemes.Platform).
+				Build()
+			cr := newTestClusterReconciler(platformClient, nil, events.NewFakeRecorder(100))
+			cr.AnnotateAddress = tC.enabled
+			ctx := logr.NewContext(t.Context(), logr.New(nil))
+
+			assert.NoError(t, cr.updateAddressAnnotation(ctx, cluster, tC.addresses))
+
+			actual := &clustersv1alpha1.Cluster{}
+			if assert.NoError(t, platformClient.Get(ctx, reqSample.NamespacedName, actual)) {
+				if tC.expectedAnnotation == "" {
+					assert.NotContains(t, actual.Annotations, gatewayv1alpha1.AddressAnnotation)
+				} else {
+					assert.Equal(t, tC.expectedAnnotation, actual.Annotations[gatewayv1alpha1.AddressAnnotation])
+				}
+			}
+		})
+	}
+}
+
+func Test_Timings(t *testing.T) {
+	defaults := Timings{}
+	assert.Equal(t, defaultDriftInterval, defaults.getDriftInterval())
+	assert.Equal(t, defaultReleaseProgressInterval, defaults.getReleaseProgressInterval())
+	assert.Equal(t, defaultWaitingForCRDsEventInterval, defaults.getWaitingForCRDsEventInterval())
+
+	custom := Timings{
+		DriftInterval:               time.Minute,
+		ReleaseProgressInterval:     time.Second,
+		WaitingForCRDsEventInterval: time.Millisecond,
+	}
+	assert.Equal(t, time.Minute, custom.getDriftInterval())
+	assert.Equal(t, time.Second, custom.getReleaseProgressInterval())
+	assert.Equal(t, time.Millisecond, custom.getWaitingForCRDsEventInterval())
+}
+
+func Test_CacheByObject(t *testing.T) {
+	byObject := CacheByObject("provider")
+	for obj, opts := range byObject {
+		if _, ok := obj.(*corev1.ConfigMap); ok {
+			assert.Equal(t, map[string]cache.Config{"provider": {}}, opts.Namespaces)
+			return
+		}
+	}
+	t.Fatal("expected the ConfigMap cache to be restricted")
+}
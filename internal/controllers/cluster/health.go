@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openmcp-project/platform-service-gateway/pkg/envoy"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+const (
+	// defaultHealthWindow is the duration for which the reconciliation of a cluster has to fail to be counted as failing.
+	defaultHealthWindow = 30 * time.Minute
+	// defaultHealthFailureThreshold is the ratio of failing clusters from which the controller is reported as unhealthy.
+	defaultHealthFailureThreshold = 1.0
+)
+
+// ReconcileHealth tracks the outcome of the latest reconciliation of each managed cluster and reports the controller as unhealthy
+// once the reconciliations of too many clusters have been failing continuously for the whole window.
+// A single successful reconciliation resets the failure of a cluster, so that the check doesn't flap on transient errors.
+// Only clusters passed to Track are counted, until they are removed with Forget. All methods are no-ops on a nil ReconcileHealth.
+type ReconcileHealth struct {
+	// Window is the duration for which the reconciliation of a cluster has to fail to be counted. Default: 30m
+	Window time.Duration
+	// FailureThreshold is the ratio of failing clusters in (0, 1] from which the check fails. Default: 1, i.e. all clusters
+	FailureThreshold float64
+
+	// failingSince stores when the reconciliation of each cluster started failing, or the zero time if it succeeded.
+	failingSince map[types.NamespacedName]time.Time
+	mu           sync.Mutex
+	now          func() time.Time
+}
+
+// Track starts counting the reconciliations of the given cluster, which is managed by the controller.
+func (h *ReconcileHealth) Track(cluster types.NamespacedName) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failingSince == nil {
+		h.failingSince = map[types.NamespacedName]time.Time{}
+	}
+	if _, ok := h.failingSince[cluster]; !ok {
+		h.failingSince[cluster] = time.Time{}
+	}
+}
+
+// Forget stops counting the reconciliations of the given cluster, e.g. because it was deleted or is no longer managed.
+func (h *ReconcileHealth) Forget(cluster types.NamespacedName) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failingSince, cluster)
+}
+
+// Record records the outcome of a reconciliation of the given cluster. It is ignored if the cluster isn't tracked.
+func (h *ReconcileHealth) Record(cluster types.NamespacedName, err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.failingSince[cluster]; !ok {
+		return
+	}
+	if err == nil {
+		h.failingSince[cluster] = time.Time{}
+		return
+	}
+	if h.failingSince[cluster].IsZero() {
+		h.failingSince[cluster] = h.getNow()
+	}
+}
+
+// Check implements a healthz.Checker which fails if the ratio of failing clusters reaches the FailureThreshold.
+// It succeeds as long as no cluster has been reconciled.
+func (h *ReconcileHealth) Check(_ *http.Request) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.failingSince) == 0 {
+		return nil
+	}
+	window := cmp.Or(h.Window, defaultHealthWindow)
+	failing := 0
+	for _, since := range h.failingSince {
+		if !since.IsZero() && h.getNow().Sub(since) >= window {
+			failing++
+		}
+	}
+	ratio := float64(failing) / float64(len(h.failingSince))
+	if failing > 0 && ratio >= cmp.Or(h.FailureThreshold, defaultHealthFailureThreshold) {
+		return fmt.Errorf("reconciliation of %d of %d clusters (%.0f%%) has been failing for more than %s", failing, len(h.failingSince), ratio*100, window)
+	}
+	return nil
+}
+
+// healthError returns the error of a reconciliation as it is recorded by the health check.
+// Waits for expected conditions, i.e. for access to the cluster, the drain timeout or the deletion of resources,
+// are not counted as failures. Other retryable errors, e.g. timeouts of API calls, are.
+func healthError(err error) error {
+	if errors.Is(err, errClusterAccessNotYetAvailable) || errors.Is(err, envoy.ErrDrainInProgress) || utils.IsRemainingResourcesError(err) {
+		return nil
+	}
+	return err
+}
+
+func (h *ReconcileHealth) getNow() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
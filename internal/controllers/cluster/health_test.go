@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openmcp-project/platform-service-gateway/pkg/envoy"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+func Test_ReconcileHealth_Check(t *testing.T) {
+	clusterA := types.NamespacedName{Namespace: "foo", Name: "a"}
+	clusterB := types.NamespacedName{Namespace: "foo", Name: "b"}
+	untracked := types.NamespacedName{Namespace: "foo", Name: "c"}
+	errFailed := errors.New("failed")
+
+	testCases := []struct {
+		desc        string
+		threshold   float64
+		record      func(h *ReconcileHealth, wait func(time.Duration))
+		expectedErr string
+	}{
+		{
+			desc:   "should be healthy without failed reconciliations",
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {},
+		},
+		{
+			desc: "should be healthy while failures are shorter than the window",
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {
+				h.Record(clusterA, errFailed)
+				h.Record(clusterB, errFailed)
+				wait(10 * time.Minute)
+			},
+		},
+		{
+			desc: "should be unhealthy if all clusters fail for the window",
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {
+				h.Record(clusterA, errFailed)
+				h.Record(clusterB, errFailed)
+				wait(20 * time.Minute)
+				// repeated failures don't move the start of the failure
+				h.Record(clusterA, errFailed)
+				wait(20 * time.Minute)
+			},
+			expectedErr: "reconciliation of 2 of 2 clusters (100%) has been failing for more than 30m0s",
+		},
+		{
+			desc: "should be healthy if one cluster succeeds",
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {
+				h.Record(clusterA, errFailed)
+				h.Record(clusterB, nil)
+				wait(time.Hour)
+			},
+		},
+		{
+			desc: "should reset a failure after a successful reconciliation",
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {
+				h.Record(clusterA, errFailed)
+				wait(time.Hour)
+				h.Record(clusterA, nil)
+				h.Record(clusterA, errFailed)
+				wait(time.Minute)
+			},
+		},
+		{
+			desc: "should not count forgotten and untracked clusters",
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {
+				h.Record(clusterA, errFailed)
+				h.Forget(clusterB)
+				h.Record(clusterB, nil)
+				h.Record(untracked, nil)
+				wait(time.Hour)
+			},
+			expectedErr: "reconciliation of 1 of 1 clusters (100%) has been failing for more than 30m0s",
+		},
+		{
+			desc:      "should be unhealthy if the failure ratio reaches the threshold",
+			threshold: 0.5,
+			record: func(h *ReconcileHealth, wait func(time.Duration)) {
+				h.Record(clusterA, errFailed)
+				h.Record(clusterB, nil)
+				wait(time.Hour)
+			},
+			expectedErr: "reconciliation of 1 of 2 clusters (50%) has been failing for more than 30m0s",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			now := time.Now()
+			h := &ReconcileHealth{
+				FailureThreshold: tC.threshold,
+				now:              func() time.Time { return now },
+			}
+			h.Track(clusterA)
+			h.Track(clusterB)
+			tC.record(h, func(d time.Duration) { now = now.Add(d) })
+
+			err := h.Check(nil)
+			if tC.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tC.expectedErr)
+			}
+		})
+	}
+}
+
+func Test_healthError(t *testing.T) {
+	errFailed := errors.New("failed")
+	testCases := []struct {
+		desc     string
+		err      error
+		expected error
+	}{
+		{
+			desc: "should ignore success",
+		},
+		{
+			desc: "should ignore waiting for cluster access",
+			err:  utils.NewRetryableError(errClusterAccessNotYetAvailable, time.Second),
+		},
+		{
+			desc: "should ignore waiting for the drain timeout",
+			err:  utils.NewRetryableError(envoy.ErrDrainInProgress, time.Second),
+		},
+		{
+			desc: "should ignore waiting for remaining resources",
+			err:  utils.NewRemainingResourcesError(time.Second),
+		},
+		{
+			desc:     "should count retryable errors",
+			err:      utils.NewRetryableError(errFailed, time.Second),
+			expected: errFailed,
+		},
+		{
+			desc:     "should count other errors",
+			err:      errFailed,
+			expected: errFailed,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := healthError(tC.err)
+			if tC.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tC.expected)
+		})
+	}
+}
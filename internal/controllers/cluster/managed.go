@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	gatewayv1alpha1 "github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/pkg/envoy"
+)
+
+// ManagedCluster is a Cluster on which the gateway is installed.
+type ManagedCluster struct {
+	// Cluster is the identity of the Cluster.
+	Cluster types.NamespacedName
+	// BaseDomains are the domains under which the gateway of the Cluster is reachable, starting with the primary one.
+	BaseDomains []string
+}
+
+// ListManagedClusters returns all Clusters which carry the gateway finalizer, sorted by their identity.
+// The base domains are computed with the given DNS config, which should be the one of the GatewayServiceConfig.
+func ListManagedClusters(ctx context.Context, platformClient client.Client, dnsConfig gatewayv1alpha1.DNSConfig) ([]ManagedCluster, error) {
+	list := &clustersv1alpha1.ClusterList{}
+	if err := platformClient.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list Clusters: %w", err)
+	}
+
+	managed := []ManagedCluster{}
+	for i := range list.Items {
+		c := &list.Items[i]
+		if !controllerutil.ContainsFinalizer(c, gatewayv1alpha1.GatewayFinalizerOnCluster) {
+			continue
+		}
+		key := client.ObjectKeyFromObject(c)
+		domains, err := envoy.EffectiveBaseDomains(c, dnsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute base domains of Cluster '%s': %w", key, err)
+		}
+		managed = append(managed, ManagedCluster{
+			Cluster:     key,
+			BaseDomains: domains,
+		})
+	}
+	slices.SortFunc(managed, func(a, b ManagedCluster) int {
+		return strings.Compare(a.Cluster.String(), b.Cluster.String())
+	})
+	return managed, nil
+}
@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"testing"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gatewayv1alpha1 "github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
+)
+
+func Test_ListManagedClusters(t *testing.T) {
+	newCluster := func(namespace, name string, finalizers ...string) *clustersv1alpha1.Cluster {
+		return &clustersv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  namespace,
+				Finalizers: finalizers,
+			},
+		}
+	}
+	platformClient := fake.NewClientBuilder().
+		WithObjects(
+			newCluster("workload", "b", gatewayv1alpha1.GatewayFinalizerOnCluster),
+			newCluster("platform", "a", gatewayv1alpha1.GatewayFinalizerOnCluster),
+			newCluster("workload", "unmanaged", "other-finalizer"),
+			newCluster("workload", "plain"),
+		).
+		WithScheme(schemes.Platform).
+		Build()
+	dnsConfig := gatewayv1alpha1.DNSConfig{
+		BaseDomain:            "example.com",
+		AdditionalBaseDomains: []string{"example.org"},
+	}
+
+	managed, err := ListManagedClusters(t.Context(), platformClient, dnsConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, []ManagedCluster{
+		{
+			Cluster:     types.NamespacedName{Namespace: "platform", Name: "a"},
+			BaseDomains: []string{"a.platform.example.com", "a.platform.example.org"},
+		},
+		{
+			Cluster:     types.NamespacedName{Namespace: "workload", Name: "b"},
+			BaseDomains: []string{"b.workload.example.com", "b.workload.example.org"},
+		},
+	}, managed)
+
+	// an invalid subdomain template is reported with the identity of the Cluster
+	dnsConfig.SubdomainTemplate = "{{ .Cluster.Name }}_invalid"
+	_, err = ListManagedClusters(t.Context(), platformClient, dnsConfig)
+	assert.ErrorContains(t, err, "platform/a")
+
+	// no managed clusters result in an empty list
+	managed, err = ListManagedClusters(t.Context(), fake.NewClientBuilder().WithScheme(schemes.Platform).Build(), gatewayv1alpha1.DNSConfig{})
+	assert.NoError(t, err)
+	assert.Empty(t, managed)
+}
@@ -0,0 +1,18 @@
+package cluster
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// waitingForGatewayCRDsTotal counts the reconciliations which are blocked because the Envoy Gateway CRDs are not installed in a cluster.
+	waitingForGatewayCRDsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "platform_service_gateway_waiting_for_gateway_crds_total",
+		Help: "Total number of reconciliations which were blocked because the Envoy Gateway CRDs are not installed in the cluster.",
+	}, []string{"cluster"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(waitingForGatewayCRDsTotal)
+}
@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/openmcp-project/controller-utils/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/openmcp-project/platform-service-gateway/pkg/envoy"
+)
+
+// defaultOrphanCollectionInterval is the interval in which orphaned Flux resources are collected.
+const defaultOrphanCollectionInterval = 1 * time.Hour
+
+// OrphanCollector periodically deletes the Flux resources of gateways whose Cluster no longer exists,
+// e.g. because the Cluster was force-deleted without running the finalizer. The first collection runs on start.
+type OrphanCollector struct {
+	PlatformClient client.Client
+	Log            logging.Logger
+	// Interval between two collections. Default: 1h
+	Interval time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = &OrphanCollector{}
+
+// Start runs the collection until the given context is cancelled.
+func (o *OrphanCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(cmp.Or(o.Interval, defaultOrphanCollectionInterval))
+	defer ticker.Stop()
+	for {
+		o.collect(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection ensures that only the leader deletes resources.
+func (o *OrphanCollector) NeedLeaderElection() bool {
+	return true
+}
+
+func (o *OrphanCollector) collect(ctx context.Context) {
+	orphans, err := envoy.CollectOrphanedFluxResources(ctx, o.PlatformClient)
+	if err != nil {
+		o.Log.Error(err, "failed to collect orphaned gateway resources")
+		return
+	}
+	for _, key := range orphans {
+		o.Log.Info("Deleting gateway resources of deleted Cluster", "cluster", key.String())
+	}
+}
@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/go-logr/logr"
+	"github.com/openmcp-project/controller-utils/pkg/logging"
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
+)
+
+func Test_OrphanCollector(t *testing.T) {
+	helmRelease := func(cluster string) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster + ".gateway",
+				Namespace: reqSample.Namespace,
+				Labels: map[string]string{
+					"gateway.openmcp.cloud/managed-by": "platform-service-gateway",
+					v1alpha1.ClusterNameLabel:          v1alpha1.ClusterNameLabelValue(cluster),
+					v1alpha1.ClusterNamespaceLabel:     reqSample.Namespace,
+				},
+				Annotations: map[string]string{
+					v1alpha1.ClusterNameAnnotation:      cluster,
+					v1alpha1.ClusterNamespaceAnnotation: reqSample.Namespace,
+				},
+			},
+		}
+	}
+	orphaned := helmRelease("deleted")
+	existing := helmRelease("existing")
+	platformClient := fake.NewClientBuilder().
+		WithScheme(schemes.Platform).
+		WithObjects(
+			orphaned,
+			existing,
+			&clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "existing",
+					Namespace: reqSample.Namespace,
+				},
+			},
+		).
+		Build()
+
+	o := &OrphanCollector{
+		PlatformClient: platformClient,
+		Log:            logging.Wrap(logr.Discard()),
+		Interval:       time.Hour,
+	}
+	assert.True(t, o.NeedLeaderElection())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() {
+		done <- o.Start(ctx)
+	}()
+
+	// the first collection runs on start
+	assert.Eventually(t, func() bool {
+		return apierrors.IsNotFound(platformClient.Get(t.Context(), client.ObjectKeyFromObject(orphaned), &helmv2.HelmRelease{}))
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(existing), &helmv2.HelmRelease{}))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the collector to stop when the context is cancelled")
+	}
+}
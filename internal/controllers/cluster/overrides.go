@@ -0,0 +1,120 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	openmcpconst "github.com/openmcp-project/openmcp-operator/api/constants"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	gatewayv1alpha1 "github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+// gatewayAnnotationPrefix is the prefix of all annotations on a Cluster which are evaluated by this controller.
+const gatewayAnnotationPrefix = "gateway." + openmcpconst.OpenMCPGroupName + "/"
+
+// clusterOverride overrides a part of the EnvoyGatewayConfig with the value of an annotation on a Cluster.
+type clusterOverride struct {
+	annotation string
+	// apply applies the value to the given config and returns an error if the value or the resulting config is invalid.
+	apply func(value string, cfg *gatewayv1alpha1.EnvoyGatewayConfig) error
+}
+
+// clusterOverrides is the curated set of overrides which can be set on a Cluster.
+var clusterOverrides = []clusterOverride{
+	{annotation: gatewayv1alpha1.ProxyReplicasAnnotation, apply: overrideProxyReplicas},
+	{annotation: gatewayv1alpha1.ProxyImageAnnotation, apply: overrideProxyImage},
+	{annotation: gatewayv1alpha1.ChartTagAnnotation, apply: overrideChartTag},
+}
+
+// otherClusterAnnotations are the annotations with the gateway prefix which are no overrides.
+var otherClusterAnnotations = []string{
+	gatewayv1alpha1.OperationAnnotation,
+	gatewayv1alpha1.SuspendAnnotation,
+	gatewayv1alpha1.FreezeAnnotation,
+	gatewayv1alpha1.AddressAnnotation,
+}
+
+// EffectiveEnvoyGatewayConfig returns the Envoy Gateway config of the given GatewayServiceConfig with the override annotations
+// of the given Cluster applied, as it is used to install the gateway. Ignored overrides are reported as warnings.
+func EffectiveEnvoyGatewayConfig(c *clustersv1alpha1.Cluster, cfg *gatewayv1alpha1.GatewayServiceConfig) (*gatewayv1alpha1.EnvoyGatewayConfig, []string) {
+	envoyConfig := cfg.Spec.EnvoyGateway.DeepCopy()
+	warnings := applyClusterOverrides(c, envoyConfig)
+	return envoyConfig, warnings
+}
+
+// applyClusterOverrides applies the override annotations of the given Cluster to the given config.
+// Invalid overrides and unknown annotations with the gateway prefix are ignored, a warning is returned for each of them.
+func applyClusterOverrides(c *clustersv1alpha1.Cluster, cfg *gatewayv1alpha1.EnvoyGatewayConfig) []string {
+	var warnings []string
+	for key := range c.Annotations {
+		if !strings.HasPrefix(key, gatewayAnnotationPrefix) || slices.Contains(otherClusterAnnotations, key) {
+			continue
+		}
+		if !slices.ContainsFunc(clusterOverrides, func(o clusterOverride) bool { return o.annotation == key }) {
+			warnings = append(warnings, fmt.Sprintf("Ignoring unknown annotation %s", key))
+		}
+	}
+	slices.Sort(warnings)
+
+	for _, o := range clusterOverrides {
+		value, ok := c.Annotations[o.annotation]
+		if !ok {
+			continue
+		}
+		// apply to a copy, so that an invalid override leaves the config untouched
+		overridden := cfg.DeepCopy()
+		if err := o.apply(value, overridden); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Ignoring invalid override %s=%q: %s", o.annotation, value, err))
+			continue
+		}
+		*cfg = *overridden
+	}
+	return warnings
+}
+
+func overrideProxyReplicas(value string, cfg *gatewayv1alpha1.EnvoyGatewayConfig) error {
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return errors.New("must be an integer")
+	}
+	if replicas < 0 {
+		return errors.New("must not be negative")
+	}
+	if cfg.Proxy == nil {
+		cfg.Proxy = &gatewayv1alpha1.ProxyConfig{}
+	}
+	if cfg.Proxy.ProviderType == egv1a1.EnvoyProxyProviderTypeHost {
+		return errors.New("not applicable to the Host provider")
+	}
+	cfg.Proxy.Replicas = ptr.To(int32(replicas))
+	return nil
+}
+
+func overrideProxyImage(value string, cfg *gatewayv1alpha1.EnvoyGatewayConfig) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	if cfg.Images == nil {
+		cfg.Images = &gatewayv1alpha1.ImagesConfig{}
+	}
+	cfg.Images.EnvoyProxy = value
+	// e.g. the image has to be pinned by digest if required
+	return cfg.Images.Validate(field.NewPath("images")).ToAggregate()
+}
+
+func overrideChartTag(value string, cfg *gatewayv1alpha1.EnvoyGatewayConfig) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	cfg.Chart.Tag = value
+	// the pinned tag replaces the central chart version
+	cfg.Chart.VersionFrom = nil
+	return cfg.Chart.Validate(field.NewPath("chart")).ToAggregate()
+}
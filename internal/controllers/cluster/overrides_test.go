@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"testing"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	gatewayv1alpha1 "github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+func Test_applyClusterOverrides(t *testing.T) {
+	const (
+		proxyImage       = "docker.io/envoyproxy/envoy:distroless-v1.35.3"
+		pinnedProxyImage = "docker.io/envoyproxy/envoy@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+	testCases := []struct {
+		desc             string
+		annotations      map[string]string
+		config           gatewayv1alpha1.EnvoyGatewayConfig
+		expectedConfig   gatewayv1alpha1.EnvoyGatewayConfig
+		expectedWarnings []string
+	}{
+		{
+			desc: "should leave config untouched without annotations",
+			config: gatewayv1alpha1.EnvoyGatewayConfig{
+				Proxy: &gatewayv1alpha1.ProxyConfig{Replicas: ptr.To[int32](2)},
+			},
+			expectedConfig: gatewayv1alpha1.EnvoyGatewayConfig{
+				Proxy: &gatewayv1alpha1.ProxyConfig{Replicas: ptr.To[int32](2)},
+			},
+		},
+		{
+			desc: "should override proxy replicas and image",
+			annotations: map[string]string{
+				gatewayv1alpha1.ProxyReplicasAnnotation: "3",
+				gatewayv1alpha1.ProxyImageAnnotation:    proxyImage,
+				// other gateway annotations are no overrides
+				gatewayv1alpha1.SuspendAnnotation: "true",
+			},
+			config: gatewayv1alpha1.EnvoyGatewayConfig{
+				Proxy: &gatewayv1alpha1.ProxyConfig{Replicas: ptr.To[int32](2)},
+			},
+			expectedConfig: gatewayv1alpha1.EnvoyGatewayConfig{
+				Proxy:  &gatewayv1alpha1.ProxyConfig{Replicas: ptr.To[int32](3)},
+				Images: &gatewayv1alpha1.ImagesConfig{EnvoyProxy: proxyImage},
+			},
+		},
+		{
+			desc: "should ignore invalid overrides",
+			annotations: map[string]string{
+				gatewayv1alpha1.ProxyReplicasAnnotation: "-1",
+				gatewayv1alpha1.ProxyImageAnnotation:    proxyImage,
+			},
+			config: gatewayv1alpha1.EnvoyGatewayConfig{
+				Images: &gatewayv1alpha1.ImagesConfig{
+					EnvoyProxy:    pinnedProxyImage,
+					EnvoyGateway:  pinnedProxyImage,
+					RequireDigest: true,
+				},
+			},
+			expectedConfig: gatewayv1alpha1.EnvoyGatewayConfig{
+				Images: &gatewayv1alpha1.ImagesConfig{
+					EnvoyProxy:    pinnedProxyImage,
+					EnvoyGateway:  pinnedProxyImage,
+					RequireDigest: true,
+				},
+			},
+			expectedWarnings: []string{
+				`Ignoring invalid override gateway.openmcp.cloud/proxy-replicas="-1": must not be negative`,
+				`Ignoring invalid override gateway.openmcp.cloud/proxy-image="` + proxyImage + `": images.proxy: Invalid value: "` + proxyImage + `": must be pinned by a sha256 digest when digests are required`,
+			},
+		},
+		{
+			desc: "should reject replicas for the Host provider",
+			annotations: map[string]string{
+				gatewayv1alpha1.ProxyReplicasAnnotation: "2",
+			},
+			config: gatewayv1alpha1.EnvoyGatewayConfig{
+				Proxy: &gatewayv1alpha1.ProxyConfig{ProviderType: egv1a1.EnvoyProxyProviderTypeHost},
+			},
+			expectedConfig: gatewayv1alpha1.EnvoyGatewayConfig{
+				Proxy: &gatewayv1alpha1.ProxyConfig{ProviderType: egv1a1.EnvoyProxyProviderTypeHost},
+			},
+			expectedWarnings: []string{
+				`Ignoring invalid override gateway.openmcp.cloud/proxy-replicas="2": not applicable to the Host provider`,
+			},
+		},
+		{
+			desc: "should pin the chart tag",
+			annotations: map[string]string{
+				gatewayv1alpha1.ChartTagAnnotation: "1.6.0",
+			},
+			config: gatewayv1alpha1.EnvoyGatewayConfig{
+				Chart: gatewayv1alpha1.EnvoyGatewayChart{
+					VersionFrom: &gatewayv1alpha1.ConfigMapKeyReference{Name: "versions", Key: "envoy-gateway"},
+				},
+			},
+			expectedConfig: gatewayv1alpha1.EnvoyGatewayConfig{
+				Chart: gatewayv1alpha1.EnvoyGatewayChart{Tag: "1.6.0"},
+			},
+		},
+		{
+			desc: "should ignore an invalid chart tag",
+			annotations: map[string]string{
+				gatewayv1alpha1.ChartTagAnnotation: "1.6.0+build",
+			},
+			config: gatewayv1alpha1.EnvoyGatewayConfig{
+				Chart: gatewayv1alpha1.EnvoyGatewayChart{Tag: "1.5.4"},
+			},
+			expectedConfig: gatewayv1alpha1.EnvoyGatewayConfig{
+				Chart: gatewayv1alpha1.EnvoyGatewayChart{Tag: "1.5.4"},
+			},
+			expectedWarnings: []string{
+				`Ignoring invalid override gateway.openmcp.cloud/chart-tag="1.6.0+build": chart.tag: Invalid value: "1.6.0+build": must be a valid OCI tag`,
+			},
+		},
+		{
+			desc: "should warn about unknown annotations",
+			annotations: map[string]string{
+				"gateway.openmcp.cloud/proxy-replica": "2",
+				"example.com/proxy-replicas":          "2",
+			},
+			expectedWarnings: []string{
+				"Ignoring unknown annotation gateway.openmcp.cloud/proxy-replica",
+			},
+		},
+		{
+			desc: "should not warn about annotations which are no overrides",
+			annotations: map[string]string{
+				gatewayv1alpha1.FreezeAnnotation:  "false",
+				gatewayv1alpha1.AddressAnnotation: "203.0.113.10",
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			c := &clustersv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tC.annotations,
+				},
+			}
+			warnings := applyClusterOverrides(c, &tC.config)
+			assert.Equal(t, tC.expectedWarnings, warnings)
+			assert.Equal(t, tC.expectedConfig, tC.config)
+		})
+	}
+}
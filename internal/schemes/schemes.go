@@ -10,6 +10,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	providerscheme "github.com/openmcp-project/platform-service-gateway/api/install"
 )
@@ -30,5 +31,6 @@ func init() {
 	// Install APIs into Target scheme
 	utilruntime.Must(clientgoscheme.AddToScheme(Target))
 	utilruntime.Must(gatewayv1.Install(Target))
+	utilruntime.Must(gatewayv1beta1.Install(Target))
 	utilruntime.Must(egv1a1.AddToScheme(Target))
 }
@@ -0,0 +1,46 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-gateway-openmcp-cloud-v1alpha1-gatewayserviceconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=gateway.openmcp.cloud,resources=gatewayserviceconfigs,verbs=create;update,versions=v1alpha1,name=vgatewayserviceconfig.gateway.openmcp.cloud,admissionReviewVersions=v1
+
+// GatewayServiceConfigValidator rejects invalid GatewayServiceConfigs at admission time,
+// because a broken config affects the gateways of all clusters.
+type GatewayServiceConfigValidator struct{}
+
+var _ admission.Validator[*v1alpha1.GatewayServiceConfig] = &GatewayServiceConfigValidator{}
+
+// SetupGatewayServiceConfigWebhookWithManager registers the validating webhook for GatewayServiceConfigs.
+func SetupGatewayServiceConfigWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &v1alpha1.GatewayServiceConfig{}).
+		WithValidator(&GatewayServiceConfigValidator{}).
+		Complete()
+}
+
+func (v *GatewayServiceConfigValidator) ValidateCreate(_ context.Context, obj *v1alpha1.GatewayServiceConfig) (admission.Warnings, error) {
+	return nil, validate(obj)
+}
+
+func (v *GatewayServiceConfigValidator) ValidateUpdate(_ context.Context, _, newObj *v1alpha1.GatewayServiceConfig) (admission.Warnings, error) {
+	return nil, validate(newObj)
+}
+
+// ValidateDelete allows the deletion of any GatewayServiceConfig.
+func (v *GatewayServiceConfigValidator) ValidateDelete(_ context.Context, _ *v1alpha1.GatewayServiceConfig) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validate(obj *v1alpha1.GatewayServiceConfig) error {
+	if err := obj.Spec.Validate(); err != nil {
+		return fmt.Errorf("invalid GatewayServiceConfig '%s': %w", obj.Name, err)
+	}
+	return nil
+}
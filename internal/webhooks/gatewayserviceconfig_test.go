@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+func newConfig() *v1alpha1.GatewayServiceConfig {
+	return &v1alpha1.GatewayServiceConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gateway",
+		},
+		Spec: v1alpha1.GatewayServiceConfigSpec{
+			EnvoyGateway: v1alpha1.EnvoyGatewayConfig{
+				Chart: v1alpha1.EnvoyGatewayChart{
+					URL: "oci://ghcr.io/openmcp-project/charts/envoy-gateway",
+					Tag: "1.5.4",
+				},
+			},
+			Clusters: []v1alpha1.ClusterTerm{
+				{
+					Selector: &v1alpha1.ClusterSelector{MatchPurpose: "platform"},
+				},
+			},
+			DNS: v1alpha1.DNSConfig{
+				BaseDomain: "dev.openmcp.example.com",
+			},
+		},
+	}
+}
+
+func TestGatewayServiceConfigValidator(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		mutate      func(cfg *v1alpha1.GatewayServiceConfig)
+		expectedErr string
+	}{
+		{
+			desc:   "should accept valid config",
+			mutate: func(cfg *v1alpha1.GatewayServiceConfig) {},
+		},
+		{
+			desc: "should reject missing base domain",
+			mutate: func(cfg *v1alpha1.GatewayServiceConfig) {
+				cfg.Spec.DNS.BaseDomain = ""
+			},
+			expectedErr: "spec.dns.baseDomain: Required value",
+		},
+		{
+			desc: "should reject empty chart tag",
+			mutate: func(cfg *v1alpha1.GatewayServiceConfig) {
+				cfg.Spec.EnvoyGateway.Chart.Tag = ""
+			},
+			expectedErr: "spec.envoyGateway.chart.tag: Required value",
+		},
+		{
+			desc: "should reject conflicting cluster terms",
+			mutate: func(cfg *v1alpha1.GatewayServiceConfig) {
+				cfg.Spec.Clusters[0].ExcludeSelector = &v1alpha1.ClusterSelector{MatchPurpose: "platform"}
+			},
+			expectedErr: "spec.clusters[0].excludeSelector: Invalid value",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			v := &GatewayServiceConfigValidator{}
+			oldCfg := newConfig()
+			cfg := newConfig()
+			tC.mutate(cfg)
+
+			_, createErr := v.ValidateCreate(t.Context(), cfg)
+			_, updateErr := v.ValidateUpdate(t.Context(), oldCfg, cfg)
+			if tC.expectedErr == "" {
+				assert.NoError(t, createErr)
+				assert.NoError(t, updateErr)
+				return
+			}
+			for _, err := range []error{createErr, updateErr} {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tC.expectedErr)
+				}
+			}
+
+			// invalid configs can always be deleted
+			_, err := v.ValidateDelete(t.Context(), cfg)
+			assert.NoError(t, err)
+		})
+	}
+}
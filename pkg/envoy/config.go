@@ -2,78 +2,237 @@ package envoy
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
 	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
 )
 
 var (
-	errFailedToDeleteObject = errors.New("failed to delete object")
+	// ErrUnsupportedGatewayAPIVersion occurs if the Gateway API CRDs in a cluster don't serve the version used by the controller.
+	ErrUnsupportedGatewayAPIVersion = errors.New("unsupported Gateway API version")
+
+	errFailedToDeleteObject    = errors.New("failed to delete object")
+	errGatewayClassNotAccepted = errors.New("GatewayClass has not been accepted yet")
+	errGatewayClassRecreated   = errors.New("gateway class is recreated because its controller name changed")
+	errInvalidClusterDomain    = errors.New("invalid cluster domain")
+	errCertificateNotFound     = errors.New("certificate secret not found")
+	errNamespaceNotFound       = errors.New("namespace not found")
+	errUnknownListener         = errors.New("unknown listener")
+	errServicePortConflict     = errors.New("port is already used by listener")
 )
 
 const (
 	gatewayClassName           = "envoy-gateway"
 	gatewayClassControllerName = "gateway.envoyproxy.io/gatewayclass-controller"
 	gatewayName                = "default"
-	gatewayNamespace           = "openmcp-system"
+	defaultListenerName        = v1alpha1.DefaultListenerName
+	defaultGatewayNamespace    = "openmcp-system"
 	tlsPortAnnotation          = "gateway.openmcp.cloud/tls-port"
 	baseDomainAnnotation       = "dns.openmcp.cloud/base-domain"
+	contentHashAnnotation      = "gateway.openmcp.cloud/content-hash"
+	defaultSubdomainTemplate   = "{{.Cluster.Name}}.{{.Cluster.Namespace}}"
+
+	// privilegedPortLimit and privilegedPortShift mirror how Envoy Gateway maps privileged listener ports to container ports.
+	privilegedPortLimit = 1024
+	privilegedPortShift = 10000
+
+	// defaultDeletionRetryInterval is the interval in which objects pending deletion are checked again.
+	defaultDeletionRetryInterval = 10 * time.Second
 )
 
 func (g *Gateway) Configure(ctx context.Context) error {
 	gatewayclass := getGatewayClass()
-	envoyProxy := getEnvoyProxy()
-	gateway := getGateway()
+	envoyProxy := g.getEnvoyProxy()
+	gateway := g.getGateway()
 
 	ops := []applyOperation{
-		ensureNamespace(gatewayNamespace, nil),
 		{
 			obj: gatewayclass,
-			f:   reconcileGatewayClassFunc(gatewayclass),
+			f:   g.reconcileGatewayClassFunc(gatewayclass),
 		},
 		{
 			obj: envoyProxy,
 			f:   g.reconcileEnvoyProxyFunc(envoyProxy),
 		},
-		{
+	}
+	if g.createNamespaces() {
+		ops = append([]applyOperation{ensureNamespace(g.getGatewayNamespace(), nil, nil)}, ops...)
+	} else if err := g.checkNamespacesExist(ctx, g.getGatewayNamespace()); err != nil {
+		return err
+	}
+
+	// the controller name of a GatewayClass is immutable, so the GatewayClass has to be recreated if it changed
+	err := g.ensureGatewayClassControllerName(ctx, gatewayclass)
+	if err == nil {
+		// the GatewayClass and the EnvoyProxy don't depend on each other, so all failures are reported at once
+		err = createOrUpdateAll(ctx, g.ClusterClient, ops...)
+	}
+	if err == nil {
+		// the Gateway is only programmed once the Envoy Gateway controller has accepted the GatewayClass
+		err = g.ensureGatewayClassAccepted(ctx, gatewayclass)
+	}
+	if err == nil {
+		err = createOrUpdate(ctx, g.ClusterClient, applyOperation{
 			obj: gateway,
 			f:   g.reconcileGatewayFunc(gateway),
-		},
+		})
+	}
+	if err == nil {
+		err = g.reconcileRoutes(ctx)
+	}
+	if err == nil {
+		err = g.reconcilePolicies(ctx)
+	}
+	if err == nil {
+		// the Gateway is applied before, because cert-manager may create the certificates based on it
+		err = g.checkCertificates(ctx, gateway.Spec.Listeners)
 	}
-
-	err := createOrUpdate(ctx, g.ClusterClient, ops...)
 	if utils.IsCRDNotFoundError(err) {
-		return utils.NewRetryableError(err, 10*time.Second)
+		if versionErr := g.checkGatewayAPIVersion(); versionErr != nil {
+			return versionErr
+		}
+		return utils.NewRetryableError(err, g.Timings.getCRDRetryInterval())
 	}
-	return err
+	if err != nil {
+		return err
+	}
+
+	return g.reconcileMonitoring(ctx)
 }
 
+// checkGatewayAPIVersion returns an error wrapping ErrUnsupportedGatewayAPIVersion
+// if the Gateway API CRDs are installed in the cluster, but not in the version used by the controller.
+// Missing CRDs are not reported, because they are expected to be installed by the Envoy Gateway chart eventually.
+func (g *Gateway) checkGatewayAPIVersion() error {
+	mappings, err := g.ClusterClient.RESTMapper().RESTMappings(schema.GroupKind{Group: gatewayv1.GroupName, Kind: "Gateway"})
+	if err != nil || len(mappings) == 0 {
+		return nil
+	}
+	versions := make([]string, 0, len(mappings))
+	for _, mapping := range mappings {
+		if mapping.GroupVersionKind.Version == gatewayv1.GroupVersion.Version {
+			return nil
+		}
+		versions = append(versions, mapping.GroupVersionKind.Version)
+	}
+	return fmt.Errorf("%w: the cluster serves %s in version(s) %s, but %s is required",
+		ErrUnsupportedGatewayAPIVersion, gatewayv1.GroupName, strings.Join(versions, ", "), gatewayv1.GroupVersion.Version)
+}
+
+// Cleanup deletes the gateway resources on the cluster. The Gateway, which owns the listeners of the Envoy proxies,
+// is only deleted once the drain timeout of the cleanup config has elapsed, see waitForDrain.
 func (g *Gateway) Cleanup(ctx context.Context) error {
-	gateway := getGateway()
-	envoyProxy := getEnvoyProxy()
+	if err := g.waitForDrain(ctx, g.getHelmRelease()); err != nil {
+		return err
+	}
+
+	gateway := g.getGateway()
+	envoyProxy := g.getEnvoyProxy()
 	gatewayclass := getGatewayClass()
 
-	return ensureDeletionOfObjects(ctx, g.ClusterClient,
+	routeObjs, err := g.listManagedRouteObjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	objs := routeObjs
+	objs = append(objs, g.getPolicies()...)
+	objs = append(objs,
 		gateway,
 		envoyProxy,
 		gatewayclass,
 	)
+	objs = append(objs, g.getMonitors()...)
+
+	serviceAccount, err := g.getManagedProxyServiceAccount(ctx)
+	if err != nil {
+		return err
+	}
+	if serviceAccount != nil {
+		objs = append(objs, serviceAccount)
+	}
+	return ensureDeletionOfObjects(ctx, g.ClusterClient, g.getDeletionOptions(), objs...)
+}
+
+func (g *Gateway) getGatewayNamespace() string {
+	if g.GatewayNamespace != "" {
+		return g.GatewayNamespace
+	}
+	return defaultGatewayNamespace
 }
 
 // ----- GatewayClass -----
 
+// ensureGatewayClassAccepted returns a *RetryableError if the given GatewayClass has not been accepted yet.
+func (g *Gateway) ensureGatewayClassAccepted(ctx context.Context, obj *gatewayv1.GatewayClass) error {
+	if err := g.ClusterClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return err
+	}
+	if !meta.IsStatusConditionTrue(obj.Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted)) {
+		return utils.NewRetryableError(errGatewayClassNotAccepted, g.Timings.getGatewayClassRetryInterval())
+	}
+	return nil
+}
+
+// ensureGatewayClassControllerName deletes the given GatewayClass if it exists with a different controller name
+// and returns a *RetryableError, so that the GatewayClass is recreated with the desired controller name.
+// A GatewayClass which is stuck in deletion, e.g. left behind by a previous installation, is recreated as well.
+func (g *Gateway) ensureGatewayClassControllerName(ctx context.Context, obj *gatewayv1.GatewayClass) error {
+	existing := &gatewayv1.GatewayClass{}
+	if err := g.ClusterClient.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !existing.DeletionTimestamp.IsZero() {
+		// the gateway controller keeps the GatewayClass as long as Gateways reference it, which includes the managed Gateway,
+		// so the deletion can only finish if the finalizer is removed
+		if controllerutil.RemoveFinalizer(existing, gatewayv1.GatewayClassFinalizerGatewaysExist) {
+			if err := g.ClusterClient.Update(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+		return utils.NewRetryableError(errGatewayClassRecreated, g.Timings.getGatewayClassRetryInterval())
+	}
+	if existing.Spec.ControllerName == g.getGatewayClassControllerName() {
+		return nil
+	}
+	if err := deleteObjects(ctx, g.ClusterClient, existing); err != nil {
+		return err
+	}
+	return utils.NewRetryableError(errGatewayClassRecreated, g.Timings.getGatewayClassRetryInterval())
+}
+
+func (g *Gateway) getGatewayClassControllerName() gatewayv1.GatewayController {
+	if g.EnvoyConfig.ControllerName != "" {
+		return gatewayv1.GatewayController(g.EnvoyConfig.ControllerName)
+	}
+	return gatewayClassControllerName
+}
+
 func getGatewayClass() *gatewayv1.GatewayClass {
 	return &gatewayv1.GatewayClass{
 		ObjectMeta: metav1.ObjectMeta{
@@ -82,133 +241,635 @@ func getGatewayClass() *gatewayv1.GatewayClass {
 	}
 }
 
-//nolint:unparam
-func reconcileGatewayClassFunc(obj *gatewayv1.GatewayClass) func() error {
-	obj.Spec.ControllerName = gatewayClassControllerName
-	return nil
+func (g *Gateway) reconcileGatewayClassFunc(obj *gatewayv1.GatewayClass) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+		obj.Spec.ControllerName = g.getGatewayClassControllerName()
+		return nil
+	}
 }
 
 // ----- Gateway -----
 
-func getGateway() *gatewayv1.Gateway {
+// GatewayAddresses returns the addresses assigned to the Gateway.
+// It returns no addresses if the Gateway has not been programmed yet.
+func (g *Gateway) GatewayAddresses(ctx context.Context) ([]string, error) {
+	gateway := g.getGateway()
+	if err := g.ClusterClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+		return nil, err
+	}
+	if !meta.IsStatusConditionTrue(gateway.Status.Conditions, string(gatewayv1.GatewayConditionProgrammed)) {
+		return nil, nil
+	}
+
+	addresses := make([]string, 0, len(gateway.Status.Addresses))
+	for _, address := range gateway.Status.Addresses {
+		addresses = append(addresses, address.Value)
+	}
+	return addresses, nil
+}
+
+func (g *Gateway) getGateway() *gatewayv1.Gateway {
 	return &gatewayv1.Gateway{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      gatewayName,
-			Namespace: gatewayNamespace,
+			Namespace: g.getGatewayNamespace(),
 		},
 	}
 }
 
 func (g *Gateway) reconcileGatewayFunc(obj *gatewayv1.Gateway) func() error {
 	return func() error {
+		g.applyCommonMetadata(obj)
 		obj.Spec.GatewayClassName = gatewayClassName
-		obj.Spec.Listeners = []gatewayv1.Listener{
+		baseDomains, err := g.generateBaseDomains()
+		if err != nil {
+			return err
+		}
+		obj.Spec.Listeners = g.getListeners()
+		if g.GatewayConfig != nil && g.GatewayConfig.RestrictToBaseDomain {
+			obj.Spec.Listeners = restrictListenersToDomains(obj.Spec.Listeners, baseDomains)
+		}
+		if obj.Spec.Infrastructure == nil {
+			obj.Spec.Infrastructure = &gatewayv1.GatewayInfrastructure{}
+		}
+		obj.Spec.Infrastructure.ParametersRef = &gatewayv1.LocalParametersReference{
+			Group: "gateway.envoyproxy.io",
+			Kind:  "EnvoyProxy",
+			Name:  gatewayName,
+		}
+
+		// only set the annotations owned by this controller, other annotations (e.g. set by users or external-dns) are preserved
+		if port, ok := getPrimaryTLSPort(obj.Spec.Listeners); ok {
+			metav1.SetMetaDataAnnotation(&obj.ObjectMeta, tlsPortAnnotation, strconv.Itoa(int(port)))
+		} else {
+			delete(obj.Annotations, tlsPortAnnotation)
+		}
+		// the base domain annotation lists all domains of the cluster, separated by commas, starting with the primary one
+		annotation := g.getBaseDomainAnnotation()
+		metav1.SetMetaDataAnnotation(&obj.ObjectMeta, annotation, strings.Join(baseDomains, ","))
+		if annotation != baseDomainAnnotation {
+			// the default annotation would be picked up by DNS tooling which is not meant to handle the gateway anymore
+			delete(obj.Annotations, baseDomainAnnotation)
+		}
+
+		return nil
+	}
+}
+
+func (g *Gateway) getBaseDomainAnnotation() string {
+	if g.DNSConfig.BaseDomainAnnotation != "" {
+		return g.DNSConfig.BaseDomainAnnotation
+	}
+	return baseDomainAnnotation
+}
+
+// getListeners returns the listeners of the gateway.
+// Without configured listeners, the gateway has a single TLS passthrough listener on the TLS port.
+func (g *Gateway) getListeners() []gatewayv1.Listener {
+	if g.GatewayConfig == nil || len(g.GatewayConfig.Listeners) == 0 {
+		return []gatewayv1.Listener{
 			{
-				Name:     "tls",
+				Name:     defaultListenerName,
 				Port:     g.getTLSPort(),
 				Protocol: gatewayv1.TLSProtocolType,
 				TLS: &gatewayv1.ListenerTLSConfig{
 					Mode: ptr.To(gatewayv1.TLSModePassthrough),
 				},
 				AllowedRoutes: &gatewayv1.AllowedRoutes{
-					Namespaces: &gatewayv1.RouteNamespaces{
-						From: ptr.To(gatewayv1.NamespacesFromAll),
-					},
+					Namespaces: g.getAllowedRouteNamespaces(),
 				},
 			},
 		}
-		if obj.Spec.Infrastructure == nil {
-			obj.Spec.Infrastructure = &gatewayv1.GatewayInfrastructure{}
+	}
+
+	listeners := make([]gatewayv1.Listener, 0, len(g.GatewayConfig.Listeners))
+	for _, cfg := range g.GatewayConfig.Listeners {
+		listener := gatewayv1.Listener{
+			Name:     cfg.Name,
+			Port:     cfg.Port,
+			Protocol: cfg.EffectiveProtocol(),
+			AllowedRoutes: &gatewayv1.AllowedRoutes{
+				Namespaces: g.getAllowedRouteNamespaces(),
+			},
 		}
-		obj.Spec.Infrastructure.ParametersRef = &gatewayv1.LocalParametersReference{
-			Group: "gateway.envoyproxy.io",
-			Kind:  "EnvoyProxy",
-			Name:  gatewayName,
+		if mode := cfg.EffectiveTLSMode(); mode != "" {
+			listener.TLS = &gatewayv1.ListenerTLSConfig{
+				Mode:            ptr.To(mode),
+				CertificateRefs: cfg.CertificateRefs,
+			}
+		}
+		if cfg.AllowedRoutes != nil {
+			listener.AllowedRoutes.Namespaces = getRouteNamespaces(cfg.AllowedRoutes)
 		}
+		listeners = append(listeners, listener)
+		listeners = append(listeners, getHostnameListeners(listener, cfg)...)
+	}
+	return listeners
+}
 
-		baseDomain := g.generateBaseDomain()
-		metav1.SetMetaDataAnnotation(&obj.ObjectMeta, tlsPortAnnotation, strconv.Itoa(int(g.getTLSPort())))
-		metav1.SetMetaDataAnnotation(&obj.ObjectMeta, baseDomainAnnotation, baseDomain)
+// getHostnameListeners returns a copy of the given listener for each hostname with its own certificates.
+// The given listener serves all other hostnames with its default certificates.
+func getHostnameListeners(listener gatewayv1.Listener, cfg v1alpha1.ListenerConfig) []gatewayv1.Listener {
+	listeners := make([]gatewayv1.Listener, 0, len(cfg.HostnameCertificateRefs))
+	for i, refs := range cfg.HostnameCertificateRefs {
+		l := *listener.DeepCopy()
+		l.Name = cfg.HostnameListenerName(i)
+		l.Hostname = ptr.To(refs.Hostname)
+		l.TLS.CertificateRefs = refs.CertificateRefs
+		listeners = append(listeners, l)
+	}
+	return listeners
+}
 
-		return nil
+// checkCertificates returns a *RetryableError if a Secret referenced by the certificates of the given listeners doesn't exist yet,
+// e.g. because cert-manager has not issued the certificate yet. This covers the default certificates of a listener
+// as well as the certificates of the listeners generated for specific hostnames.
+func (g *Gateway) checkCertificates(ctx context.Context, listeners []gatewayv1.Listener) error {
+	for _, listener := range listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, ref := range listener.TLS.CertificateRefs {
+			if ptr.Deref(ref.Group, "") != "" || ptr.Deref(ref.Kind, "Secret") != "Secret" {
+				// other kinds of certificate references are resolved by Envoy Gateway
+				continue
+			}
+			key := client.ObjectKey{
+				Namespace: string(ptr.Deref(ref.Namespace, gatewayv1.Namespace(g.getGatewayNamespace()))),
+				Name:      string(ref.Name),
+			}
+			err := g.ClusterClient.Get(ctx, key, &corev1.Secret{})
+			if apierrors.IsNotFound(err) {
+				return utils.NewRetryableError(fmt.Errorf("%w: %s for listener %s", errCertificateNotFound, key, listener.Name), g.Timings.getCertificateRetryInterval())
+			}
+			if err != nil {
+				return err
+			}
+		}
 	}
+	return nil
 }
 
-func (g *Gateway) generateBaseDomain() string {
-	return fmt.Sprintf("%s.%s.%s", g.Cluster.Name, g.Cluster.Namespace, g.DNSConfig.BaseDomain)
+// restrictListenersToDomains sets the hostname of the listeners to the wildcard of the given domains.
+// The listeners are repeated for each additional domain, because a listener only accepts a single hostname.
+// TCP listeners don't support hostnames, so they are kept as they are. Listeners for specific hostnames are only kept
+// if their hostname is within one of the given domains, e.g. the hostname certificates of another cluster are dropped.
+func restrictListenersToDomains(listeners []gatewayv1.Listener, domains []string) []gatewayv1.Listener {
+	restricted := make([]gatewayv1.Listener, 0, len(listeners)*len(domains))
+	for _, listener := range listeners {
+		if listener.Hostname != nil {
+			if slices.ContainsFunc(domains, func(domain string) bool { return v1alpha1.HostnameInDomain(*listener.Hostname, domain) }) {
+				restricted = append(restricted, listener)
+			}
+			continue
+		}
+		if listener.Protocol == gatewayv1.TCPProtocolType {
+			restricted = append(restricted, listener)
+			continue
+		}
+		for i, domain := range domains {
+			l := *listener.DeepCopy()
+			l.Hostname = ptr.To(gatewayv1.Hostname("*." + domain))
+			l.Name = v1alpha1.DomainListenerName(listener.Name, i)
+			restricted = append(restricted, l)
+		}
+	}
+	return restricted
+}
+
+// getPrimaryTLSPort returns the port of the first TLS listener, which is published in the tlsPortAnnotation.
+func getPrimaryTLSPort(listeners []gatewayv1.Listener) (gatewayv1.PortNumber, bool) {
+	for _, listener := range listeners {
+		if listener.Protocol == gatewayv1.TLSProtocolType {
+			return listener.Port, true
+		}
+	}
+	return 0, false
+}
+
+func (g *Gateway) getAllowedRouteNamespaces() *gatewayv1.RouteNamespaces {
+	if g.GatewayConfig == nil {
+		return getRouteNamespaces(nil)
+	}
+	return getRouteNamespaces(g.GatewayConfig.AllowedRoutes)
+}
+
+func getRouteNamespaces(allowedRoutes *v1alpha1.AllowedRoutesConfig) *gatewayv1.RouteNamespaces {
+	if allowedRoutes == nil || allowedRoutes.From == "" {
+		return &gatewayv1.RouteNamespaces{
+			From: ptr.To(gatewayv1.NamespacesFromAll),
+		}
+	}
+	namespaces := &gatewayv1.RouteNamespaces{
+		From: ptr.To(allowedRoutes.From),
+	}
+	if allowedRoutes.From == gatewayv1.NamespacesFromSelector {
+		namespaces.Selector = allowedRoutes.Selector.DeepCopy()
+	}
+	return namespaces
+}
+
+// generateBaseDomains returns the primary base domain of the cluster followed by the additional ones.
+func (g *Gateway) generateBaseDomains() ([]string, error) {
+	return EffectiveBaseDomains(g.Cluster, g.DNSConfig)
+}
+
+// EffectiveBaseDomains returns the domains under which the given cluster is reachable, starting with the primary one.
+// Each domain consists of the rendered subdomain of the cluster followed by one of the configured base domains.
+func EffectiveBaseDomains(cluster *clustersv1alpha1.Cluster, dnsConfig v1alpha1.DNSConfig) ([]string, error) {
+	subdomain, err := generateSubdomain(cluster, dnsConfig)
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]string, 0, 1+len(dnsConfig.AdditionalBaseDomains))
+	for _, baseDomain := range append([]string{dnsConfig.BaseDomain}, dnsConfig.AdditionalBaseDomains...) {
+		domain := fmt.Sprintf("%s.%s", subdomain, baseDomain)
+		if msgs := validation.IsDNS1123Subdomain(domain); len(msgs) > 0 {
+			return nil, fmt.Errorf("%w: %q: %s", errInvalidClusterDomain, domain, strings.Join(msgs, ", "))
+		}
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// subdomainTemplateData is the data the DNSConfig.SubdomainTemplate is rendered with.
+type subdomainTemplateData struct {
+	Cluster subdomainTemplateCluster
+}
+
+type subdomainTemplateCluster struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// generateSubdomain renders the subdomain of the cluster, which is prepended to each base domain.
+func generateSubdomain(cluster *clustersv1alpha1.Cluster, dnsConfig v1alpha1.DNSConfig) (string, error) {
+	text := dnsConfig.SubdomainTemplate
+	if text == "" {
+		text = defaultSubdomainTemplate
+	}
+	tmpl, err := v1alpha1.ParseSubdomainTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse subdomain template: %w", err)
+	}
+	data := subdomainTemplateData{
+		Cluster: subdomainTemplateCluster{
+			Name:        cluster.Name,
+			Namespace:   cluster.Namespace,
+			Labels:      cluster.Labels,
+			Annotations: cluster.Annotations,
+		},
+	}
+	sb := &strings.Builder{}
+	if err := tmpl.Execute(sb, data); err != nil {
+		return "", fmt.Errorf("failed to render subdomain template: %w", err)
+	}
+	// empty values, e.g. of missing labels, must not result in empty domain labels
+	labels := slices.DeleteFunc(strings.Split(sb.String(), "."), func(label string) bool { return label == "" })
+	subdomain := strings.Join(labels, ".")
+	if subdomain == "" {
+		return "", fmt.Errorf("%w: subdomain template rendered an empty subdomain", errInvalidClusterDomain)
+	}
+	return subdomain, nil
 }
 
 func (g *Gateway) getTLSPort() int32 {
 	if g.GatewayConfig != nil && g.GatewayConfig.TLSPort != 0 {
 		return g.GatewayConfig.TLSPort
 	}
-	return 9443
+	return v1alpha1.DefaultTLSPort
 }
 
 // ----- EnvoyProxy -----
 
-func getEnvoyProxy() *egv1a1.EnvoyProxy {
+func (g *Gateway) getEnvoyProxy() *egv1a1.EnvoyProxy {
 	return &egv1a1.EnvoyProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      gatewayName,
-			Namespace: gatewayNamespace,
+			Namespace: g.getGatewayNamespace(),
 		},
 	}
 }
 
 func (g *Gateway) reconcileEnvoyProxyFunc(obj *egv1a1.EnvoyProxy) func() error {
 	return func() error {
-		var image *string
-		var imagePullSecrets []corev1.LocalObjectReference
+		g.applyCommonMetadata(obj)
+		obj.Spec.IPFamily = g.EnvoyConfig.IPFamily
+		obj.Spec.Shutdown = g.getShutdownConfig()
+
+		telemetry, err := g.getProxyTelemetry()
+		if err != nil {
+			return err
+		}
+		obj.Spec.Telemetry = telemetry
+
+		bootstrap, err := g.getProxyBootstrap()
+		if err != nil {
+			return err
+		}
+		obj.Spec.Bootstrap = bootstrap
+		obj.Spec.Logging = g.getProxyLogging()
+		obj.Spec.Concurrency = g.getProxyConcurrency()
 
-		if img := g.EnvoyConfig.Images; img != nil {
-			imagePullSecrets = img.ImagePullSecrets
-			if img.EnvoyProxy != "" {
-				image = &img.EnvoyProxy
+		if g.getProxyProviderType() == egv1a1.EnvoyProxyProviderTypeHost {
+			// kubernetes-specific options are not applicable to the host provider
+			obj.Spec.Provider = &egv1a1.EnvoyProxyProvider{
+				Type: egv1a1.EnvoyProxyProviderTypeHost,
+				Host: &egv1a1.EnvoyProxyHostProvider{},
 			}
+			return nil
 		}
 
-		obj.Spec.IPFamily = g.EnvoyConfig.IPFamily
 		obj.Spec.Provider = &egv1a1.EnvoyProxyProvider{
-			Type: egv1a1.EnvoyProxyProviderTypeKubernetes,
-			Kubernetes: &egv1a1.EnvoyProxyKubernetesProvider{
-				EnvoyDeployment: &egv1a1.KubernetesDeploymentSpec{
-					Pod: &egv1a1.KubernetesPodSpec{
-						ImagePullSecrets: imagePullSecrets,
-					},
-					Container: &egv1a1.KubernetesContainerSpec{
-						Image: image,
-					},
-				},
+			Type:       egv1a1.EnvoyProxyProviderTypeKubernetes,
+			Kubernetes: g.getKubernetesProvider(),
+		}
+		servicePatch, err := g.getEnvoyServicePatch(obj)
+		if err != nil {
+			return err
+		}
+		if servicePatch != nil {
+			if obj.Spec.Provider.Kubernetes.EnvoyService == nil {
+				obj.Spec.Provider.Kubernetes.EnvoyService = &egv1a1.KubernetesServiceSpec{}
+			}
+			obj.Spec.Provider.Kubernetes.EnvoyService.Patch = servicePatch
+		}
+		return nil
+	}
+}
+
+// getShutdownConfig returns the shutdown config of the proxy.
+// It returns nil if nothing is configured, so that the Envoy Gateway defaults apply.
+func (g *Gateway) getShutdownConfig() *egv1a1.ShutdownConfig {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.Shutdown == nil {
+		return nil
+	}
+	shutdown := g.EnvoyConfig.Proxy.Shutdown
+	cfg := &egv1a1.ShutdownConfig{}
+	if shutdown.DrainTimeout != nil {
+		cfg.DrainTimeout = ptr.To(formatDuration(shutdown.DrainTimeout.Duration))
+	}
+	if shutdown.MinDrainDuration != nil {
+		cfg.MinDrainDuration = ptr.To(formatDuration(shutdown.MinDrainDuration.Duration))
+	}
+	if cfg.DrainTimeout == nil && cfg.MinDrainDuration == nil {
+		return nil
+	}
+	return cfg
+}
+
+// getProxyConcurrency returns the number of worker threads of the proxy, nil to use the Envoy default.
+func (g *Gateway) getProxyConcurrency() *int32 {
+	if g.EnvoyConfig.Proxy == nil {
+		return nil
+	}
+	return g.EnvoyConfig.Proxy.Concurrency
+}
+
+// getProxyLogging returns the log levels of the proxy. Components without a configured level use the Envoy Gateway defaults.
+func (g *Gateway) getProxyLogging() egv1a1.ProxyLogging {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.Logging == nil {
+		return egv1a1.ProxyLogging{}
+	}
+	cfg := g.EnvoyConfig.Proxy.Logging
+	levels := map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{}
+	maps.Copy(levels, cfg.Components)
+	if cfg.Level != "" {
+		levels[egv1a1.LogComponentDefault] = cfg.Level
+	}
+	if len(levels) == 0 {
+		return egv1a1.ProxyLogging{}
+	}
+	return egv1a1.ProxyLogging{Level: levels}
+}
+
+// getProxyBootstrap returns the bootstrap customization of the proxy or nil if none is configured.
+func (g *Gateway) getProxyBootstrap() (*egv1a1.ProxyBootstrap, error) {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.Bootstrap == nil {
+		return nil, nil
+	}
+	cfg := g.EnvoyConfig.Proxy.Bootstrap
+	bootstrap := &egv1a1.ProxyBootstrap{
+		Type: ptr.To(cfg.Type),
+	}
+	if cfg.Type != egv1a1.BootstrapTypeJSONPatch {
+		bootstrap.Value = ptr.To(cfg.Value)
+		return bootstrap, nil
+	}
+	patches, err := v1alpha1.ParseBootstrapJSONPatches(cfg.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap JSON patches: %w", err)
+	}
+	bootstrap.JSONPatches = patches
+	return bootstrap, nil
+}
+
+func (g *Gateway) getProxyProviderType() egv1a1.EnvoyProxyProviderType {
+	if g.EnvoyConfig.Proxy != nil && g.EnvoyConfig.Proxy.ProviderType != "" {
+		return g.EnvoyConfig.Proxy.ProviderType
+	}
+	return egv1a1.EnvoyProxyProviderTypeKubernetes
+}
+
+func (g *Gateway) getKubernetesProvider() *egv1a1.EnvoyProxyKubernetesProvider {
+	var image *string
+	var imagePullSecrets []corev1.LocalObjectReference
+	var replicas *int32
+	var priorityClassName *string
+
+	if img := g.EnvoyConfig.Images; img != nil {
+		imagePullSecrets = img.ImagePullSecrets
+		if img.EnvoyProxy != "" {
+			image = &img.EnvoyProxy
+		}
+	}
+	if g.EnvoyConfig.Proxy != nil {
+		replicas = g.EnvoyConfig.Proxy.Replicas
+		priorityClassName = g.EnvoyConfig.Proxy.PriorityClassName
+	}
+
+	return &egv1a1.EnvoyProxyKubernetesProvider{
+		EnvoyDeployment: &egv1a1.KubernetesDeploymentSpec{
+			Replicas: replicas,
+			// the pull secrets are set on the pod, so they also apply to init containers and to the
+			// shutdown-manager sidecar, which Envoy Gateway adds to the pod using its own image
+			Pod: &egv1a1.KubernetesPodSpec{
+				ImagePullSecrets:  imagePullSecrets,
+				PriorityClassName: priorityClassName,
 			},
+			Container: &egv1a1.KubernetesContainerSpec{
+				Image: image,
+			},
+		},
+		EnvoyService:        g.getEnvoyService(),
+		EnvoyPDB:            g.getEnvoyPDB(),
+		EnvoyServiceAccount: g.getEnvoyServiceAccount(),
+	}
+}
+
+// getEnvoyService returns the configured options of the proxy Service or nil, so that the Envoy Gateway defaults apply.
+func (g *Gateway) getEnvoyService() *egv1a1.KubernetesServiceSpec {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.Service == nil {
+		return nil
+	}
+	cfg := g.EnvoyConfig.Proxy.Service
+	service := &egv1a1.KubernetesServiceSpec{
+		LoadBalancerSourceRanges: cfg.LoadBalancerSourceRanges,
+	}
+	if cfg.ExternalTrafficPolicy != "" {
+		service.ExternalTrafficPolicy = ptr.To(cfg.ExternalTrafficPolicy)
+	}
+	if cfg.LoadBalancerClass != "" {
+		service.LoadBalancerClass = ptr.To(cfg.LoadBalancerClass)
+	}
+	if cfg.LoadBalancerIP != "" {
+		service.LoadBalancerIP = ptr.To(cfg.LoadBalancerIP)
+	}
+	if reflect.ValueOf(*service).IsZero() {
+		return nil
+	}
+	return service
+}
+
+// getEnvoyServicePatch returns a strategic merge patch of the proxy Service which adds the configured ports,
+// or nil if none are configured. Each port targets the container port of its listener.
+func (g *Gateway) getEnvoyServicePatch(envoyProxy *egv1a1.EnvoyProxy) (*egv1a1.KubernetesPatchSpec, error) {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.Service == nil || len(g.EnvoyConfig.Proxy.Service.Ports) == 0 {
+		return nil, nil
+	}
+	listeners := g.getListeners()
+	listenerPorts := map[gatewayv1.SectionName]gatewayv1.PortNumber{}
+	for _, listener := range listeners {
+		listenerPorts[listener.Name] = listener.Port
+	}
+	ports := make([]map[string]any, 0, len(g.EnvoyConfig.Proxy.Service.Ports))
+	for _, port := range g.EnvoyConfig.Proxy.Service.Ports {
+		listenerPort, ok := listenerPorts[port.Listener]
+		if !ok {
+			return nil, fmt.Errorf("%w %q in port %q of the Envoy Proxy service", errUnknownListener, port.Listener, port.Name)
 		}
+		// ports are merged by their number, so the port would replace the one Envoy Gateway derives from the listener
+		if i := slices.IndexFunc(listeners, func(l gatewayv1.Listener) bool { return l.Port == port.Port }); i >= 0 {
+			return nil, fmt.Errorf("%w %q in port %q of the Envoy Proxy service", errServicePortConflict, listeners[i].Name, port.Name)
+		}
+		ports = append(ports, map[string]any{
+			"name":       port.Name,
+			"port":       port.Port,
+			"protocol":   string(corev1.ProtocolTCP),
+			"targetPort": getContainerPort(envoyProxy, listenerPort),
+		})
+	}
+	raw, err := json.Marshal(map[string]any{"spec": map[string]any{"ports": ports}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Envoy Proxy service patch: %w", err)
+	}
+	return &egv1a1.KubernetesPatchSpec{
+		Type:  ptr.To(egv1a1.StrategicMerge),
+		Value: apiextensionsv1.JSON{Raw: raw},
+	}, nil
+}
+
+// getContainerPort returns the port of the proxy container which serves the given listener port.
+// Unless disabled in the EnvoyProxy, Envoy Gateway shifts privileged ports into the unprivileged range,
+// so that the proxy doesn't need the capability to bind them.
+func getContainerPort(envoyProxy *egv1a1.EnvoyProxy, listenerPort gatewayv1.PortNumber) gatewayv1.PortNumber {
+	if envoyProxy.NeedToSwitchPorts() && listenerPort < privilegedPortLimit {
+		return listenerPort + privilegedPortShift
+	}
+	return listenerPort
+}
 
+// getEnvoyServiceAccount returns the configured ServiceAccount of the proxy pods or nil, so that Envoy Gateway creates one.
+func (g *Gateway) getEnvoyServiceAccount() *egv1a1.KubernetesServiceAccountSpec {
+	if sa := g.getProxyServiceAccount(); sa != nil {
+		return &egv1a1.KubernetesServiceAccountSpec{Name: ptr.To(sa.Name)}
+	}
+	return nil
+}
+
+func (g *Gateway) getEnvoyPDB() *egv1a1.KubernetesPodDisruptionBudgetSpec {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.PodDisruptionBudget == nil {
 		return nil
 	}
+	pdb := g.EnvoyConfig.Proxy.PodDisruptionBudget
+	return &egv1a1.KubernetesPodDisruptionBudgetSpec{
+		MinAvailable:   pdb.MinAvailable,
+		MaxUnavailable: pdb.MaxUnavailable,
+	}
 }
 
 // ----- Namespace -----
 
-func ensureNamespace(namespace string, c client.Client) applyOperation {
-	return applyOperation{
-		obj: &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: namespace,
-			},
+func ensureNamespace(namespace string, labels map[string]string, c client.Client) applyOperation {
+	obj := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
 		},
-		c: c,
+	}
+	return applyOperation{
+		obj: obj,
+		f:   reconcileNamespaceFunc(obj, labels),
+		c:   c,
+	}
+}
+
+// createNamespaces returns whether the namespaces on the target cluster are created, which is the default.
+func (g *Gateway) createNamespaces() bool {
+	return ptr.Deref(g.CreateNamespaces, true)
+}
+
+// checkNamespacesExist returns a *RetryableError if any of the given namespaces doesn't exist on the target cluster.
+// It is used instead of ensureNamespace if the namespaces are not created by the controller.
+func (g *Gateway) checkNamespacesExist(ctx context.Context, namespaces ...string) error {
+	for _, namespace := range namespaces {
+		err := g.ClusterClient.Get(ctx, client.ObjectKey{Name: namespace}, &corev1.Namespace{})
+		if apierrors.IsNotFound(err) {
+			return utils.NewRetryableError(fmt.Errorf("%w: %s is not created because createNamespaces is false", errNamespaceNotFound, namespace), g.Timings.getNamespaceRetryInterval())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileNamespaceFunc adds the given labels to the namespace.
+// Labels which are not managed by this controller are left untouched.
+func reconcileNamespaceFunc(obj *corev1.Namespace, labels map[string]string) func() error {
+	return func() error {
+		for k, v := range labels {
+			metav1.SetMetaDataLabel(&obj.ObjectMeta, k, v)
+		}
+		return nil
 	}
 }
 
 // ----- Utils -----
 
+// deletionOptions configure how ensureDeletionOfObjects deletes objects.
+type deletionOptions struct {
+	// propagationPolicy is passed to the delete calls, the default policy of the objects is used if nil.
+	propagationPolicy *metav1.DeletionPropagation
+	// requeueAfter is the retry interval of the returned RemainingResourcesError.
+	requeueAfter time.Duration
+}
+
 // ensureDeletionOfObjects tries to delete the given objects. It returns a *RetryableError as long as any of the objects still exists.
 // The function should be called with the same parameters until it returns nil.
 // Any unexpected errors are returned as is.
-func ensureDeletionOfObjects(ctx context.Context, c client.Client, objs ...client.Object) error {
+func ensureDeletionOfObjects(ctx context.Context, c client.Client, opts deletionOptions, objs ...client.Object) error {
+	deleteOpts := []client.DeleteOption{}
+	if opts.propagationPolicy != nil {
+		deleteOpts = append(deleteOpts, client.PropagationPolicy(*opts.propagationPolicy))
+	}
+
 	remaining := []client.Object{}
 	for _, obj := range objs {
-		err := c.Delete(ctx, obj)
+		// fetch the object first to report the deletion timestamp of remaining objects
+		err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
 		if apierrors.IsNotFound(err) || utils.IsCRDNotFoundError(err) {
 			// object not found or CRD does not exist (anymore)
 			continue
@@ -216,18 +877,91 @@ func ensureDeletionOfObjects(ctx context.Context, c client.Client, objs ...clien
 		if err != nil {
 			return errors.Join(errFailedToDeleteObject, err)
 		}
+
+		err = c.Delete(ctx, obj, deleteOpts...)
+		if apierrors.IsNotFound(err) || utils.IsCRDNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Join(errFailedToDeleteObject, err)
+		}
 		// object may still exist
 		remaining = append(remaining, obj)
 	}
 
 	if len(remaining) > 0 {
-		return utils.NewRemainingResourcesError(10*time.Second, objs...)
+		requeueAfter := opts.requeueAfter
+		if requeueAfter <= 0 {
+			requeueAfter = defaultDeletionRetryInterval
+		}
+		return utils.NewRemainingResourcesError(requeueAfter, remaining...)
 	}
 
 	// all objects have been deleted
 	return nil
 }
 
+// deleteObjects deletes the given objects. Objects which do not exist or whose CRD is not installed are ignored.
+// In contrast to ensureDeletionOfObjects, it does not wait for the objects to be gone.
+func deleteObjects(ctx context.Context, c client.Client, objs ...client.Object) error {
+	for _, obj := range objs {
+		if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil && !utils.IsCRDNotFoundError(err) {
+			return errors.Join(errFailedToDeleteObject, err)
+		}
+	}
+	return nil
+}
+
+// applyCommonMetadata merges the configured common labels and annotations into the metadata of the given object.
+// It has to be called before any controller-managed labels or annotations are set, so that these take precedence.
+func (g *Gateway) applyCommonMetadata(obj client.Object) {
+	if g.CommonMetadata == nil {
+		return
+	}
+	if len(g.CommonMetadata.Labels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, g.CommonMetadata.Labels)
+		obj.SetLabels(labels)
+	}
+	if len(g.CommonMetadata.Annotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		maps.Copy(annotations, g.CommonMetadata.Annotations)
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// formatDuration formats the given duration in the Gateway API duration format (GEP-2257), e.g. "1h30m".
+// Sub-millisecond precision is truncated.
+func formatDuration(d time.Duration) gatewayv1.Duration {
+	units := []struct {
+		unit   time.Duration
+		suffix string
+	}{
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+		{time.Millisecond, "ms"},
+	}
+
+	var sb strings.Builder
+	for _, u := range units {
+		if v := d / u.unit; v > 0 {
+			fmt.Fprintf(&sb, "%d%s", v, u.suffix)
+			d -= v * u.unit
+		}
+	}
+	if sb.Len() == 0 {
+		return "0s"
+	}
+	return gatewayv1.Duration(sb.String())
+}
+
 type applyOperation struct {
 	// obj is the object to be created or updated.
 	// Parameters other than name and namespace must be set using the mutate function.
@@ -245,15 +979,75 @@ type applyOperation struct {
 // If an object did exist, MutateFn will be called, and if it changed the
 // object, it will be updated.
 // Otherwise, it will be left unchanged.
+//
+// A hash of the content of each object is stored in the contentHashAnnotation.
 func createOrUpdate(ctx context.Context, c client.Client, ops ...applyOperation) error {
 	for _, op := range ops {
-		opC := c
-		if op.c != nil {
-			opC = op.c
-		}
-		if _, err := controllerutil.CreateOrUpdate(ctx, opC, op.obj, op.f); err != nil {
+		if err := applyOp(ctx, c, op); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// createOrUpdateAll applies all operations, even if some of them fail, and returns the joined errors of the failed operations.
+// It is meant for independent objects, so that a single failure doesn't hide the failures of the remaining objects.
+func createOrUpdateAll(ctx context.Context, c client.Client, ops ...applyOperation) error {
+	var errs []error
+	for _, op := range ops {
+		if err := applyOp(ctx, c, op); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyOp creates or updates the object of the operation. Errors contain the identifier of the object.
+func applyOp(ctx context.Context, c client.Client, op applyOperation) error {
+	if op.c != nil {
+		c = op.c
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, op.obj, hashedMutateFunc(op)); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", utils.ObjectIdentifier(op.obj), err)
+	}
+	return nil
+}
+
+// hashedMutateFunc wraps the mutate function of the given operation and annotates the mutated object with the hash of its content.
+// Objects without the annotation (e.g. created by older versions of this controller) or with a different hash are updated,
+// objects whose hash and content match the desired state are left untouched by controllerutil.CreateOrUpdate.
+func hashedMutateFunc(op applyOperation) controllerutil.MutateFn {
+	return func() error {
+		if err := op.f(); err != nil {
+			return err
+		}
+		hash, err := contentHash(op.obj)
+		if err != nil {
+			return err
+		}
+		annotations := op.obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[contentHashAnnotation] = hash
+		op.obj.SetAnnotations(annotations)
+		return nil
+	}
+}
+
+// contentHash computes a hash of the content of the given object, i.e. everything except its type, metadata and status.
+func contentHash(obj client.Object) (string, error) {
+	// unstructured objects are converted to their own content, which must not be modified
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+	if err != nil {
+		return "", fmt.Errorf("failed to convert %T %s: %w", obj, client.ObjectKeyFromObject(obj), err)
+	}
+	for _, field := range []string{"apiVersion", "kind", "metadata", "status"} {
+		delete(content, field)
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal content of %T %s: %w", obj, client.ObjectKeyFromObject(obj), err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
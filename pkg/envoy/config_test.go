@@ -1,16 +1,31 @@
 package envoy
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
 	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
 )
 
@@ -47,7 +62,7 @@ func Test_Gateway_Configure(t *testing.T) {
 					&egv1a1.EnvoyProxy{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      gatewayName,
-							Namespace: gatewayNamespace,
+							Namespace: defaultGatewayNamespace,
 						},
 						Spec: egv1a1.EnvoyProxySpec{
 							Provider: &egv1a1.EnvoyProxyProvider{
@@ -58,7 +73,7 @@ func Test_Gateway_Configure(t *testing.T) {
 					&gatewayv1.Gateway{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      gatewayName,
-							Namespace: gatewayNamespace,
+							Namespace: defaultGatewayNamespace,
 						},
 						Spec: gatewayv1.GatewaySpec{
 							GatewayClassName: "bar",
@@ -85,7 +100,7 @@ func Test_Gateway_Configure(t *testing.T) {
 				assert.EqualValues(t, gatewayClassControllerName, gatewayclass.Spec.ControllerName)
 			}
 
-			envoyProxy := getEnvoyProxy()
+			envoyProxy := g.getEnvoyProxy()
 			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(envoyProxy), envoyProxy)
 			if assert.NoError(t, err) {
 				assert.Equal(t, egv1a1.EnvoyProxyProviderTypeKubernetes, envoyProxy.Spec.Provider.Type)
@@ -96,7 +111,7 @@ func Test_Gateway_Configure(t *testing.T) {
 				}
 			}
 
-			gateway := getGateway()
+			gateway := g.getGateway()
 			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
 			if assert.NoError(t, err) {
 				assert.EqualValues(t, gatewayClassName, gateway.Spec.GatewayClassName)
@@ -108,36 +123,64 @@ func Test_Gateway_Configure(t *testing.T) {
 	}
 }
 
-func Test_Gateway_Cleanup(t *testing.T) {
+func Test_Gateway_Configure_correctsDrift(t *testing.T) {
+	ts := testSetup{}
+	clusterClient, _, g := ts.build()
+	if !assert.NoError(t, g.Configure(t.Context())) {
+		return
+	}
+
+	// manual edits keep the content hash annotation of the last apply
+	gateway := g.getGateway()
+	if !assert.NoError(t, clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)) {
+		return
+	}
+	gateway.Spec.Listeners = nil
+	if !assert.NoError(t, clusterClient.Update(t.Context(), gateway)) {
+		return
+	}
+
+	if !assert.NoError(t, g.Configure(t.Context())) {
+		return
+	}
+	gateway = g.getGateway()
+	if assert.NoError(t, clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)) {
+		assert.NotEmpty(t, gateway.Spec.Listeners)
+	}
+}
+
+func Test_Gateway_Configure_podPullSecrets(t *testing.T) {
+	const shutdownManagerImg = "example.com/envoyproxy/gateway@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	pullSecrets := []corev1.LocalObjectReference{{Name: "my-secret"}, {Name: "other-secret"}}
 	testCases := []struct {
-		desc string
+		desc               string
+		shutdownManagerImg string
 		testSetup
-		retries     int
-		expectedErr error
 	}{
 		{
-			desc: "should clean up when objects are already gone",
+			desc: "should set pull secrets on the pod",
 		},
 		{
-			desc:    "should clean up when objects are present",
-			retries: 1,
+			desc:               "should set pull secrets on the pod when a separate sidecar image is configured",
+			shutdownManagerImg: shutdownManagerImg,
 			testSetup: testSetup{
 				clusterInitObjs: []client.Object{
-					&gatewayv1.GatewayClass{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: gatewayClassName,
-						},
-					},
 					&egv1a1.EnvoyProxy{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      gatewayName,
-							Namespace: gatewayNamespace,
+							Namespace: defaultGatewayNamespace,
 						},
-					},
-					&gatewayv1.Gateway{
-						ObjectMeta: metav1.ObjectMeta{
-							Name:      gatewayName,
-							Namespace: gatewayNamespace,
+						Spec: egv1a1.EnvoyProxySpec{
+							Provider: &egv1a1.EnvoyProxyProvider{
+								Type: egv1a1.EnvoyProxyProviderTypeKubernetes,
+								Kubernetes: &egv1a1.EnvoyProxyKubernetesProvider{
+									EnvoyDeployment: &egv1a1.KubernetesDeploymentSpec{
+										InitContainers: []corev1.Container{
+											{Name: "init", Image: "example.com/init:v1"},
+										},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -146,34 +189,1668 @@ func Test_Gateway_Cleanup(t *testing.T) {
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			clusterClient, _, g := tC.build()
+			tC.imagePullSecrets = pullSecrets
+			for _, ps := range pullSecrets {
+				tC.platformInitObjs = append(tC.platformInitObjs, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      ps.Name,
+						Namespace: testCluster.Namespace,
+					},
+					Type: corev1.SecretTypeDockerConfigJson,
+					Data: map[string][]byte{
+						corev1.DockerConfigJsonKey: []byte(`{}`),
+					},
+				})
+			}
+			clusterClient, platformClient, g := tC.build()
+			g.EnvoyConfig.Images.ShutdownManager = tC.shutdownManagerImg
 
-			// Run Cleanup and expect it to return
-			// a RemainingResourcesError.
-			for i := 0; i < tC.retries; i++ {
-				err := g.Cleanup(t.Context())
-				assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+			err := g.Configure(t.Context())
+			if !assert.NoError(t, err) {
+				return
 			}
 
-			// Final run of Cleanup
-			err := g.Cleanup(t.Context())
-			if tC.expectedErr != nil {
-				assert.ErrorIs(t, err, tC.expectedErr)
+			envoyProxy := g.getEnvoyProxy()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(envoyProxy), envoyProxy)
+			if assert.NoError(t, err) {
+				deployment := envoyProxy.Spec.Provider.Kubernetes.EnvoyDeployment
+				if assert.NotNil(t, deployment.Pod) {
+					assert.Equal(t, pullSecrets, deployment.Pod.ImagePullSecrets)
+				}
+				// only the proxy container is rendered, stale additional containers are removed
+				assert.Empty(t, deployment.InitContainers)
+				if assert.NotNil(t, deployment.Container) {
+					assert.Equal(t, ptr.To(testEnvoyProxyImg), deployment.Container.Image)
+				}
+			}
+
+			// the sidecar image is rendered into the Envoy Gateway configuration
+			if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
 				return
 			}
-			assert.NoError(t, err)
+			hr := g.getHelmRelease()
+			if !assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+				return
+			}
+			values := map[string]any{}
+			if !assert.NoError(t, json.Unmarshal(hr.Spec.Values.Raw, &values)) {
+				return
+			}
+			global := values["global"].(map[string]any)
+			assert.Len(t, global["imagePullSecrets"], len(pullSecrets))
+			config, _ := values["config"].(map[string]any)
+			if tC.shutdownManagerImg == "" {
+				assert.NotContains(t, config, "envoyGateway")
+				return
+			}
+			assert.Equal(t, map[string]any{
+				"kubernetes": map[string]any{
+					"shutdownManager": map[string]any{
+						"image": tC.shutdownManagerImg,
+					},
+				},
+			}, config["envoyGateway"].(map[string]any)["provider"])
+		})
+	}
+}
 
-			gc := getGatewayClass()
-			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gc), gc)
-			assert.True(t, apierrors.IsNotFound(err), "GatewayClass still exists")
+func Test_Gateway_Configure_controllerName(t *testing.T) {
+	const controllerName = "example.com/gatewayclass-controller"
+	ts := testSetup{
+		clusterInitObjs: []client.Object{
+			&gatewayv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: gatewayClassName,
+				},
+				Spec: gatewayv1.GatewayClassSpec{
+					ControllerName: gatewayClassControllerName,
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+	g.EnvoyConfig.ControllerName = controllerName
 
-			ep := getEnvoyProxy()
-			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(ep), ep)
-			assert.True(t, apierrors.IsNotFound(err), "EnvoyProxy still exists")
+	// the existing GatewayClass is deleted because the controller name is immutable
+	err := g.Configure(t.Context())
+	assert.ErrorIs(t, err, errGatewayClassRecreated)
+	assert.ErrorIs(t, err, &utils.RetryableError{})
 
-			gw := getGateway()
-			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gw), gw)
-			assert.True(t, apierrors.IsNotFound(err), "Gateway still exists")
+	gatewayclass := getGatewayClass()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+	assert.True(t, apierrors.IsNotFound(err), "GatewayClass should have been deleted")
+
+	// the GatewayClass is recreated with the configured controller name
+	err = g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, controllerName, gatewayclass.Spec.ControllerName)
+	}
+}
+
+func Test_Gateway_Configure_gatewayClassInDeletion(t *testing.T) {
+	ts := testSetup{
+		clusterInitObjs: []client.Object{
+			&gatewayv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              gatewayClassName,
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					// kept by the gateway controller while the Gateway references the GatewayClass
+					Finalizers: []string{gatewayv1.GatewayClassFinalizerGatewaysExist},
+				},
+				Spec: gatewayv1.GatewayClassSpec{
+					ControllerName: "example.com/previous-controller",
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+
+	// the finalizer is removed, so that the deletion can finish
+	err := g.Configure(t.Context())
+	assert.ErrorIs(t, err, errGatewayClassRecreated)
+	assert.ErrorIs(t, err, &utils.RetryableError{})
+
+	gatewayclass := getGatewayClass()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+	assert.True(t, apierrors.IsNotFound(err), "GatewayClass should have been deleted")
+
+	// the GatewayClass is recreated with the default controller name
+	err = g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, gatewayClassControllerName, gatewayclass.Spec.ControllerName)
+		assert.True(t, gatewayclass.DeletionTimestamp.IsZero())
+	}
+}
+
+func Test_Gateway_Configure_gatewayClassNotAccepted(t *testing.T) {
+	ts := testSetup{
+		gatewayClassNotAccepted: true,
+	}
+	clusterClient, _, g := ts.build()
+	g.Timings.GatewayClassRetryInterval = time.Millisecond
+
+	err := g.Configure(t.Context())
+	assert.ErrorIs(t, err, errGatewayClassNotAccepted)
+	retryable := &utils.RetryableError{}
+	if assert.ErrorAs(t, err, &retryable) {
+		assert.Equal(t, time.Millisecond, retryable.RequeueAfter)
+	}
+
+	gatewayclass := getGatewayClass()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gatewayclass), gatewayclass)
+	assert.NoError(t, err)
+
+	gateway := g.getGateway()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+	assert.True(t, apierrors.IsNotFound(err), "Gateway should not be created before the GatewayClass is accepted")
+}
+
+func Test_Gateway_GatewayAddresses(t *testing.T) {
+	newGateway := func(programmed bool, addresses ...string) *gatewayv1.Gateway {
+		gw := &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      gatewayName,
+				Namespace: defaultGatewayNamespace,
+			},
+		}
+		if programmed {
+			gw.Status.Conditions = []metav1.Condition{
+				{
+					Type:   string(gatewayv1.GatewayConditionProgrammed),
+					Status: metav1.ConditionTrue,
+					Reason: string(gatewayv1.GatewayReasonProgrammed),
+				},
+			}
+		}
+		for _, address := range addresses {
+			gw.Status.Addresses = append(gw.Status.Addresses, gatewayv1.GatewayStatusAddress{
+				Type:  ptr.To(gatewayv1.IPAddressType),
+				Value: address,
+			})
+		}
+		return gw
+	}
+
+	testCases := []struct {
+		desc              string
+		gateway           *gatewayv1.Gateway
+		expectedAddresses []string
+	}{
+		{
+			desc:    "should return no addresses when gateway is not programmed",
+			gateway: newGateway(false, "192.0.2.1"),
+		},
+		{
+			desc:    "should return no addresses when gateway is programmed without addresses",
+			gateway: newGateway(true),
+		},
+		{
+			desc:              "should return addresses of programmed gateway",
+			gateway:           newGateway(true, "192.0.2.1", "2001:db8::1"),
+			expectedAddresses: []string{"192.0.2.1", "2001:db8::1"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{
+				clusterInitObjs: []client.Object{tC.gateway},
+			}
+			_, _, g := ts.build()
+
+			addresses, err := g.GatewayAddresses(t.Context())
+			if assert.NoError(t, err) {
+				assert.ElementsMatch(t, tC.expectedAddresses, addresses)
+			}
+		})
+	}
+}
+
+func Test_Gateway_Configure_commonMetadata(t *testing.T) {
+	ts := testSetup{
+		commonMetadata: &v1alpha1.CommonMetadata{
+			Labels: map[string]string{
+				"cost-center": "1234",
+			},
+			Annotations: map[string]string{
+				"owner":              "team-a",
+				baseDomainAnnotation: "should-be-overwritten",
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+	g.DNSConfig.BaseDomain = "example.com"
+
+	err := g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, obj := range []client.Object{getGatewayClass(), g.getEnvoyProxy(), g.getGateway()} {
+		err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(obj), obj)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1234", obj.GetLabels()["cost-center"])
+			assert.Equal(t, "team-a", obj.GetAnnotations()["owner"])
+		}
+	}
+
+	gateway := g.getGateway()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fmt.Sprintf("%s.%s.example.com", g.Cluster.Name, g.Cluster.Namespace), gateway.Annotations[baseDomainAnnotation])
+	}
+}
+
+func Test_Gateway_Configure_preservesGatewayAnnotations(t *testing.T) {
+	const externalDNSAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	ts := testSetup{
+		clusterInitObjs: []client.Object{
+			&gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      gatewayName,
+					Namespace: defaultGatewayNamespace,
+					Annotations: map[string]string{
+						externalDNSAnnotation: "foo.example.com",
+						baseDomainAnnotation:  "outdated.example.com",
+					},
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+	g.DNSConfig.BaseDomain = "example.com"
+
+	err := g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	gateway := g.getGateway()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "foo.example.com", gateway.Annotations[externalDNSAnnotation])
+		assert.Equal(t, fmt.Sprintf("%s.%s.example.com", g.Cluster.Name, g.Cluster.Namespace), gateway.Annotations[baseDomainAnnotation])
+		assert.NotEmpty(t, gateway.Annotations[tlsPortAnnotation])
+	}
+}
+
+func Test_Gateway_getAllowedRouteNamespaces(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"gateway-access": "true"}}
+	testCases := []struct {
+		desc          string
+		gatewayConfig *v1alpha1.GatewayConfig
+		expected      *gatewayv1.RouteNamespaces
+	}{
+		{
+			desc:     "should allow all namespaces by default",
+			expected: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromAll)},
+		},
+		{
+			desc: "should allow same namespace",
+			gatewayConfig: &v1alpha1.GatewayConfig{
+				AllowedRoutes: &v1alpha1.AllowedRoutesConfig{From: gatewayv1.NamespacesFromSame},
+			},
+			expected: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromSame)},
+		},
+		{
+			desc: "should allow selected namespaces",
+			gatewayConfig: &v1alpha1.GatewayConfig{
+				AllowedRoutes: &v1alpha1.AllowedRoutesConfig{From: gatewayv1.NamespacesFromSelector, Selector: selector},
+			},
+			expected: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromSelector), Selector: selector},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = tC.gatewayConfig
+
+			err := g.Configure(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			gateway := g.getGateway()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+			if assert.NoError(t, err) && assert.Len(t, gateway.Spec.Listeners, 1) {
+				assert.Equal(t, tC.expected, gateway.Spec.Listeners[0].AllowedRoutes.Namespaces)
+			}
+		})
+	}
+}
+
+func Test_Gateway_Configure_listeners(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"gateway-access": "true"}}
+	allNamespaces := &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromAll)}}
+	testCases := []struct {
+		desc            string
+		gatewayConfig   *v1alpha1.GatewayConfig
+		expected        []gatewayv1.Listener
+		expectedTLSPort string
+	}{
+		{
+			desc: "should default to a single TLS passthrough listener",
+			expected: []gatewayv1.Listener{
+				{
+					Name:          "tls",
+					Port:          9443,
+					Protocol:      gatewayv1.TLSProtocolType,
+					TLS:           &gatewayv1.ListenerTLSConfig{Mode: ptr.To(gatewayv1.TLSModePassthrough)},
+					AllowedRoutes: allNamespaces,
+				},
+			},
+			expectedTLSPort: "9443",
+		},
+		{
+			desc: "should render configured listeners",
+			gatewayConfig: &v1alpha1.GatewayConfig{
+				TLSPort: 9443,
+				Listeners: []v1alpha1.ListenerConfig{
+					{Name: "http", Port: 8080, Protocol: gatewayv1.HTTPProtocolType},
+					{Name: "internal", Port: 8443},
+					{
+						Name:            "https",
+						Port:            443,
+						Protocol:        gatewayv1.HTTPSProtocolType,
+						CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "tls-cert"}},
+						AllowedRoutes:   &v1alpha1.AllowedRoutesConfig{From: gatewayv1.NamespacesFromSelector, Selector: selector},
+					},
+				},
+			},
+			expected: []gatewayv1.Listener{
+				{
+					Name:          "http",
+					Port:          8080,
+					Protocol:      gatewayv1.HTTPProtocolType,
+					AllowedRoutes: allNamespaces,
+				},
+				{
+					Name:          "internal",
+					Port:          8443,
+					Protocol:      gatewayv1.TLSProtocolType,
+					TLS:           &gatewayv1.ListenerTLSConfig{Mode: ptr.To(gatewayv1.TLSModePassthrough)},
+					AllowedRoutes: allNamespaces,
+				},
+				{
+					Name:     "https",
+					Port:     443,
+					Protocol: gatewayv1.HTTPSProtocolType,
+					TLS: &gatewayv1.ListenerTLSConfig{
+						Mode:            ptr.To(gatewayv1.TLSModeTerminate),
+						CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "tls-cert"}},
+					},
+					AllowedRoutes: &gatewayv1.AllowedRoutes{
+						Namespaces: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromSelector), Selector: selector},
+					},
+				},
+			},
+			expectedTLSPort: "8443",
+		},
+		{
+			desc: "should render a TCP listener without TLS config instead of the TLS passthrough listener",
+			gatewayConfig: &v1alpha1.GatewayConfig{
+				Listeners: []v1alpha1.ListenerConfig{
+					{Name: "tcp", Port: 9443, Protocol: gatewayv1.TCPProtocolType},
+				},
+			},
+			expected: []gatewayv1.Listener{
+				{
+					Name:          "tcp",
+					Port:          9443,
+					Protocol:      gatewayv1.TCPProtocolType,
+					AllowedRoutes: allNamespaces,
+				},
+			},
+		},
+		{
+			desc: "should not publish a TLS port without TLS listener",
+			gatewayConfig: &v1alpha1.GatewayConfig{
+				Listeners: []v1alpha1.ListenerConfig{
+					{Name: "http", Port: 8080, Protocol: gatewayv1.HTTPProtocolType},
+				},
+			},
+			expected: []gatewayv1.Listener{
+				{
+					Name:          "http",
+					Port:          8080,
+					Protocol:      gatewayv1.HTTPProtocolType,
+					AllowedRoutes: allNamespaces,
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{
+				clusterInitObjs: []client.Object{
+					&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls-cert", Namespace: defaultGatewayNamespace}},
+				},
+			}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = tC.gatewayConfig
+
+			err := g.Configure(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			gateway := g.getGateway()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, gateway.Spec.Listeners)
+				assert.Equal(t, tC.expectedTLSPort, gateway.Annotations[tlsPortAnnotation])
+			}
+		})
+	}
+}
+
+func Test_Gateway_Configure_hostnameCertificates(t *testing.T) {
+	ts := testSetup{}
+	clusterClient, _, g := ts.build()
+	g.GatewayConfig = &v1alpha1.GatewayConfig{
+		RestrictToBaseDomain: true,
+		Listeners: []v1alpha1.ListenerConfig{
+			{
+				Name:            "https",
+				Port:            443,
+				Protocol:        gatewayv1.HTTPSProtocolType,
+				CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "default-cert"}},
+				HostnameCertificateRefs: []v1alpha1.HostnameCertificateRefs{
+					{Hostname: "api.foo.bar.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-cert"}}},
+					// hostnames of other clusters are not rendered
+					{Hostname: "api.other.bar.example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "other-cert"}}},
+				},
+			},
+		},
+	}
+
+	// the Gateway is applied, but Configure is retried until the default certificate exists
+	err := g.Configure(t.Context())
+	assert.ErrorIs(t, err, errCertificateNotFound)
+	assert.ErrorIs(t, err, &utils.RetryableError{})
+	assert.ErrorContains(t, err, "default-cert")
+
+	gateway := g.getGateway()
+	if assert.NoError(t, clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)) && assert.Len(t, gateway.Spec.Listeners, 2) {
+		assert.Equal(t, ptr.To(gatewayv1.Hostname("*.foo.bar.example.com")), gateway.Spec.Listeners[0].Hostname)
+		assert.Equal(t, []gatewayv1.SecretObjectReference{{Name: "default-cert"}}, gateway.Spec.Listeners[0].TLS.CertificateRefs)
+
+		assert.Equal(t, gatewayv1.SectionName("https-sni-0"), gateway.Spec.Listeners[1].Name)
+		assert.Equal(t, ptr.To(gatewayv1.Hostname("api.foo.bar.example.com")), gateway.Spec.Listeners[1].Hostname)
+		assert.Equal(t, []gatewayv1.SecretObjectReference{{Name: "api-cert"}}, gateway.Spec.Listeners[1].TLS.CertificateRefs)
+	}
+
+	for _, name := range []string{"default-cert", "api-cert"} {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: defaultGatewayNamespace,
+			},
+		}
+		err = g.Configure(t.Context())
+		assert.ErrorIs(t, err, errCertificateNotFound)
+		assert.ErrorContains(t, err, name)
+		assert.NoError(t, clusterClient.Create(t.Context(), secret))
+	}
+	assert.NoError(t, g.Configure(t.Context()))
+}
+
+func Test_Gateway_Configure_restrictToBaseDomain(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		gatewayConfig *v1alpha1.GatewayConfig
+		dns           v1alpha1.DNSConfig
+		expected      map[gatewayv1.SectionName]*gatewayv1.Hostname
+	}{
+		{
+			desc:     "should accept any hostname by default",
+			dns:      v1alpha1.DNSConfig{BaseDomain: "example.com"},
+			expected: map[gatewayv1.SectionName]*gatewayv1.Hostname{"tls": nil},
+		},
+		{
+			desc:          "should restrict listener to the cluster domain",
+			gatewayConfig: &v1alpha1.GatewayConfig{RestrictToBaseDomain: true},
+			dns:           v1alpha1.DNSConfig{BaseDomain: "example.com"},
+			expected: map[gatewayv1.SectionName]*gatewayv1.Hostname{
+				"tls": ptr.To(gatewayv1.Hostname("*.foo.bar.example.com")),
+			},
+		},
+		{
+			desc:          "should add listeners for additional base domains",
+			gatewayConfig: &v1alpha1.GatewayConfig{RestrictToBaseDomain: true},
+			dns:           v1alpha1.DNSConfig{BaseDomain: "example.com", AdditionalBaseDomains: []string{"legacy.example.com"}},
+			expected: map[gatewayv1.SectionName]*gatewayv1.Hostname{
+				"tls":   ptr.To(gatewayv1.Hostname("*.foo.bar.example.com")),
+				"tls-1": ptr.To(gatewayv1.Hostname("*.foo.bar.legacy.example.com")),
+			},
+		},
+		{
+			desc: "should not restrict TCP listeners",
+			gatewayConfig: &v1alpha1.GatewayConfig{
+				RestrictToBaseDomain: true,
+				Listeners: []v1alpha1.ListenerConfig{
+					{Name: "tls", Port: 9443},
+					{Name: "tcp", Port: 9000, Protocol: gatewayv1.TCPProtocolType},
+				},
+			},
+			dns: v1alpha1.DNSConfig{BaseDomain: "example.com"},
+			expected: map[gatewayv1.SectionName]*gatewayv1.Hostname{
+				"tls": ptr.To(gatewayv1.Hostname("*.foo.bar.example.com")),
+				"tcp": nil,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = tC.gatewayConfig
+			g.DNSConfig = tC.dns
+
+			err := g.Configure(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			gateway := g.getGateway()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+			if !assert.NoError(t, err) {
+				return
+			}
+			hostnames := map[gatewayv1.SectionName]*gatewayv1.Hostname{}
+			for _, listener := range gateway.Spec.Listeners {
+				hostnames[listener.Name] = listener.Hostname
+			}
+			assert.Equal(t, tC.expected, hostnames)
+		})
+	}
+}
+
+func Test_Gateway_Configure_additionalBaseDomains(t *testing.T) {
+	ts := testSetup{}
+	clusterClient, _, g := ts.build()
+	g.DNSConfig = v1alpha1.DNSConfig{
+		BaseDomain:            "example.com",
+		AdditionalBaseDomains: []string{"legacy.example.com"},
+	}
+
+	err := g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	gateway := g.getGateway()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+	if assert.NoError(t, err) {
+		expected := fmt.Sprintf("%[1]s.%[2]s.example.com,%[1]s.%[2]s.legacy.example.com", g.Cluster.Name, g.Cluster.Namespace)
+		assert.Equal(t, expected, gateway.Annotations[baseDomainAnnotation])
+	}
+}
+
+func Test_Gateway_Configure_baseDomainAnnotation(t *testing.T) {
+	const annotation = "external-dns.alpha.kubernetes.io/hostname"
+	ts := testSetup{
+		clusterInitObjs: []client.Object{
+			&gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      gatewayName,
+					Namespace: defaultGatewayNamespace,
+					Annotations: map[string]string{
+						baseDomainAnnotation: "outdated.example.com",
+					},
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+	g.DNSConfig.BaseDomainAnnotation = annotation
+
+	err := g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	gateway := g.getGateway()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fmt.Sprintf("%s.%s.example.com", g.Cluster.Name, g.Cluster.Namespace), gateway.Annotations[annotation])
+		assert.NotContains(t, gateway.Annotations, baseDomainAnnotation)
+	}
+}
+
+func Test_Gateway_generateBaseDomains(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		template    string
+		labels      map[string]string
+		annotations map[string]string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			desc:     "should use name and namespace by default",
+			expected: []string{"foo.bar.example.com", "foo.bar.legacy.example.com"},
+		},
+		{
+			desc:     "should render labels",
+			template: `{{ index .Cluster.Labels "team" }}.{{ .Cluster.Name }}`,
+			labels:   map[string]string{"team": "platform"},
+			expected: []string{"platform.foo.example.com", "platform.foo.legacy.example.com"},
+		},
+		{
+			desc:        "should render annotations",
+			template:    `{{ .Cluster.Annotations.region }}.{{ .Cluster.Name }}`,
+			annotations: map[string]string{"region": "eu"},
+			expected:    []string{"eu.foo.example.com", "eu.foo.legacy.example.com"},
+		},
+		{
+			desc:     "should render missing labels empty",
+			template: `{{ index .Cluster.Labels "team" }}.{{ .Cluster.Labels.stage }}.{{ .Cluster.Name }}`,
+			expected: []string{"foo.example.com", "foo.legacy.example.com"},
+		},
+		{
+			desc:     "should drop consecutive empty labels",
+			template: `a.{{ .Cluster.Labels.team }}.{{ .Cluster.Labels.stage }}.b`,
+			expected: []string{"a.b.example.com", "a.b.legacy.example.com"},
+		},
+		{
+			desc:        "should reject empty subdomain",
+			template:    `{{ index .Cluster.Labels "team" }}`,
+			expectedErr: errInvalidClusterDomain,
+		},
+		{
+			desc:        "should reject invalid domain",
+			template:    `{{ index .Cluster.Labels "team" }}.{{ .Cluster.Name }}`,
+			labels:      map[string]string{"team": "Platform_Team"},
+			expectedErr: errInvalidClusterDomain,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			_, _, g := ts.build()
+			g.Cluster = g.Cluster.DeepCopy()
+			g.Cluster.Labels = tC.labels
+			g.Cluster.Annotations = tC.annotations
+			g.DNSConfig = v1alpha1.DNSConfig{
+				BaseDomain:            "example.com",
+				AdditionalBaseDomains: []string{"legacy.example.com"},
+				SubdomainTemplate:     tC.template,
+			}
+
+			domains, err := g.generateBaseDomains()
+			if tC.expectedErr != nil {
+				assert.ErrorIs(t, err, tC.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, domains)
+			}
+		})
+	}
+}
+
+func Test_EffectiveBaseDomains(t *testing.T) {
+	cluster := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "bar",
+			Labels:    map[string]string{"team": "platform"},
+		},
+	}
+	dnsConfig := v1alpha1.DNSConfig{
+		BaseDomain:            "example.com",
+		AdditionalBaseDomains: []string{"legacy.example.com"},
+		SubdomainTemplate:     `{{ index .Cluster.Labels "team" }}.{{ .Cluster.Name }}`,
+	}
+
+	domains, err := EffectiveBaseDomains(cluster, dnsConfig)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"platform.foo.example.com", "platform.foo.legacy.example.com"}, domains)
+	}
+
+	// the gateway must expose the cluster under the same domains
+	ts := testSetup{}
+	_, _, g := ts.build()
+	g.Cluster = cluster
+	g.DNSConfig = dnsConfig
+	gatewayDomains, err := g.generateBaseDomains()
+	if assert.NoError(t, err) {
+		assert.Equal(t, domains, gatewayDomains)
+	}
+}
+
+func Test_Gateway_Configure_customNamespaces(t *testing.T) {
+	ts := testSetup{
+		gatewayNamespace:    "custom-gateway",
+		deploymentNamespace: "custom-envoy-gateway",
+	}
+	clusterClient, _, g := ts.build()
+	g.EnvoyConfig.Monitoring = &v1alpha1.MonitoringConfig{
+		ServiceMonitor: &v1alpha1.ServiceMonitorConfig{
+			Enabled: true,
+		},
+	}
+
+	err := g.Configure(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ns := &corev1.Namespace{}
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "custom-gateway"}, ns)
+	assert.NoError(t, err)
+
+	objs := []client.Object{g.getEnvoyProxy(), g.getGateway()}
+	objs = append(objs, g.getMonitors()...)
+	for _, obj := range objs {
+		assert.Equal(t, "custom-gateway", obj.GetNamespace())
+		err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(obj), obj)
+		assert.NoError(t, err)
+	}
+}
+
+func Test_Gateway_Cleanup(t *testing.T) {
+	testCases := []struct {
+		desc string
+		testSetup
+		retries     int
+		expectedErr error
+	}{
+		{
+			desc: "should clean up when objects are already gone",
+		},
+		{
+			desc:    "should clean up when objects are present",
+			retries: 1,
+			testSetup: testSetup{
+				clusterInitObjs: []client.Object{
+					&gatewayv1.GatewayClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: gatewayClassName,
+						},
+					},
+					&egv1a1.EnvoyProxy{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      gatewayName,
+							Namespace: defaultGatewayNamespace,
+						},
+					},
+					&gatewayv1.Gateway{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      gatewayName,
+							Namespace: defaultGatewayNamespace,
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			clusterClient, _, g := tC.build()
+
+			// Run Cleanup and expect it to return
+			// a RemainingResourcesError.
+			for i := 0; i < tC.retries; i++ {
+				err := g.Cleanup(t.Context())
+				assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+			}
+
+			// Final run of Cleanup
+			err := g.Cleanup(t.Context())
+			if tC.expectedErr != nil {
+				assert.ErrorIs(t, err, tC.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			gc := getGatewayClass()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gc), gc)
+			assert.True(t, apierrors.IsNotFound(err), "GatewayClass still exists")
+
+			ep := g.getEnvoyProxy()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(ep), ep)
+			assert.True(t, apierrors.IsNotFound(err), "EnvoyProxy still exists")
+
+			gw := g.getGateway()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gw), gw)
+			assert.True(t, apierrors.IsNotFound(err), "Gateway still exists")
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_pdb(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		proxy       *v1alpha1.ProxyConfig
+		expectedPDB *egv1a1.KubernetesPodDisruptionBudgetSpec
+	}{
+		{
+			desc: "should not render PDB when proxy config is unset",
+		},
+		{
+			desc:  "should not render PDB when PDB config is unset",
+			proxy: &v1alpha1.ProxyConfig{},
+		},
+		{
+			desc: "should render PDB with minAvailable",
+			proxy: &v1alpha1.ProxyConfig{
+				PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetConfig{
+					MinAvailable: ptr.To(intstr.FromInt32(1)),
+				},
+			},
+			expectedPDB: &egv1a1.KubernetesPodDisruptionBudgetSpec{
+				MinAvailable: ptr.To(intstr.FromInt32(1)),
+			},
+		},
+		{
+			desc: "should render PDB with maxUnavailable",
+			proxy: &v1alpha1.ProxyConfig{
+				PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetConfig{
+					MaxUnavailable: ptr.To(intstr.FromString("50%")),
+				},
+			},
+			expectedPDB: &egv1a1.KubernetesPodDisruptionBudgetSpec{
+				MaxUnavailable: ptr.To(intstr.FromString("50%")),
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: tC.proxy,
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedPDB, envoyProxy.Spec.Provider.Kubernetes.EnvoyPDB)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_priorityClassName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		proxy    *v1alpha1.ProxyConfig
+		expected *string
+	}{
+		{
+			desc: "should not set the priority class when proxy config is unset",
+		},
+		{
+			desc:  "should not set the priority class when it is unset",
+			proxy: &v1alpha1.ProxyConfig{},
+		},
+		{
+			desc: "should set the priority class of the pod",
+			proxy: &v1alpha1.ProxyConfig{
+				PriorityClassName: ptr.To("system-cluster-critical"),
+			},
+			expected: ptr.To("system-cluster-critical"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: tC.proxy,
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, envoyProxy.Spec.Provider.Kubernetes.EnvoyDeployment.Pod.PriorityClassName)
+				assert.Nil(t, envoyProxy.Spec.Provider.Kubernetes.EnvoyDeployment.Patch)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_concurrency(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		proxy               *v1alpha1.ProxyConfig
+		expectedConcurrency *int32
+	}{
+		{
+			desc: "should use the Envoy default when proxy config is unset",
+		},
+		{
+			desc:  "should use the Envoy default when concurrency is unset",
+			proxy: &v1alpha1.ProxyConfig{},
+		},
+		{
+			desc: "should set the concurrency",
+			proxy: &v1alpha1.ProxyConfig{
+				Concurrency: ptr.To[int32](4),
+			},
+			expectedConcurrency: ptr.To[int32](4),
+		},
+		{
+			desc: "should set the concurrency with the Host provider",
+			proxy: &v1alpha1.ProxyConfig{
+				ProviderType: egv1a1.EnvoyProxyProviderTypeHost,
+				Concurrency:  ptr.To[int32](2),
+			},
+			expectedConcurrency: ptr.To[int32](2),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: tC.proxy,
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedConcurrency, envoyProxy.Spec.Concurrency)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_service(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		proxy           *v1alpha1.ProxyConfig
+		expectedService *egv1a1.KubernetesServiceSpec
+	}{
+		{
+			desc: "should not render service when proxy config is unset",
+		},
+		{
+			desc:  "should not render service when external traffic policy is unset",
+			proxy: &v1alpha1.ProxyConfig{Service: &v1alpha1.ProxyServiceConfig{}},
+		},
+		{
+			desc: "should render external traffic policy",
+			proxy: &v1alpha1.ProxyConfig{
+				Service: &v1alpha1.ProxyServiceConfig{ExternalTrafficPolicy: egv1a1.ServiceExternalTrafficPolicyLocal},
+			},
+			expectedService: &egv1a1.KubernetesServiceSpec{
+				ExternalTrafficPolicy: ptr.To(egv1a1.ServiceExternalTrafficPolicyLocal),
+			},
+		},
+		{
+			desc: "should render load balancer options",
+			proxy: &v1alpha1.ProxyConfig{
+				Service: &v1alpha1.ProxyServiceConfig{
+					LoadBalancerClass:        "example.com/internal",
+					LoadBalancerIP:           "203.0.113.10",
+					LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				},
+			},
+			expectedService: &egv1a1.KubernetesServiceSpec{
+				LoadBalancerClass:        ptr.To("example.com/internal"),
+				LoadBalancerIP:           ptr.To("203.0.113.10"),
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: tC.proxy,
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedService, envoyProxy.Spec.Provider.Kubernetes.EnvoyService)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_servicePorts(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		gateway         *v1alpha1.GatewayConfig
+		service         *v1alpha1.ProxyServiceConfig
+		expectedService *egv1a1.KubernetesServiceSpec
+		expectedErr     error
+	}{
+		{
+			desc: "should expose the default listener on an additional port",
+			service: &v1alpha1.ProxyServiceConfig{
+				Ports: []v1alpha1.ProxyServicePort{{Name: "https", Port: 443, Listener: "tls"}},
+			},
+			expectedService: &egv1a1.KubernetesServiceSpec{
+				Patch: &egv1a1.KubernetesPatchSpec{
+					Type:  ptr.To(egv1a1.StrategicMerge),
+					Value: apiextensionsv1.JSON{Raw: []byte(`{"spec":{"ports":[{"name":"https","port":443,"protocol":"TCP","targetPort":9443}]}}`)},
+				},
+			},
+		},
+		{
+			desc: "should target the shifted container port of a privileged listener port",
+			gateway: &v1alpha1.GatewayConfig{
+				Listeners: []v1alpha1.ListenerConfig{{Name: "web", Port: 80, Protocol: gatewayv1.HTTPProtocolType}},
+			},
+			service: &v1alpha1.ProxyServiceConfig{
+				ExternalTrafficPolicy: egv1a1.ServiceExternalTrafficPolicyLocal,
+				Ports:                 []v1alpha1.ProxyServicePort{{Name: "http-alt", Port: 8080, Listener: "web"}},
+			},
+			expectedService: &egv1a1.KubernetesServiceSpec{
+				ExternalTrafficPolicy: ptr.To(egv1a1.ServiceExternalTrafficPolicyLocal),
+				Patch: &egv1a1.KubernetesPatchSpec{
+					Type:  ptr.To(egv1a1.StrategicMerge),
+					Value: apiextensionsv1.JSON{Raw: []byte(`{"spec":{"ports":[{"name":"http-alt","port":8080,"protocol":"TCP","targetPort":10080}]}}`)},
+				},
+			},
+		},
+		{
+			desc: "should fail for an unknown listener",
+			service: &v1alpha1.ProxyServiceConfig{
+				Ports: []v1alpha1.ProxyServicePort{{Name: "https", Port: 443, Listener: "web"}},
+			},
+			expectedErr: errUnknownListener,
+		},
+		{
+			desc: "should fail for a port of another listener",
+			gateway: &v1alpha1.GatewayConfig{
+				Listeners: []v1alpha1.ListenerConfig{
+					{Name: "web", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+					{Name: "web-alt", Port: 8080, Protocol: gatewayv1.HTTPProtocolType},
+				},
+			},
+			service: &v1alpha1.ProxyServiceConfig{
+				Ports: []v1alpha1.ProxyServicePort{{Name: "http-alt", Port: 8080, Listener: "web"}},
+			},
+			expectedErr: errServicePortConflict,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				GatewayConfig: tC.gateway,
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: &v1alpha1.ProxyConfig{Service: tC.service},
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if tC.expectedErr != nil {
+				assert.ErrorIs(t, err, tC.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedService, envoyProxy.Spec.Provider.Kubernetes.EnvoyService)
+			}
+		})
+	}
+}
+
+func Test_getContainerPort(t *testing.T) {
+	envoyProxy := &egv1a1.EnvoyProxy{}
+	assert.EqualValues(t, 10443, getContainerPort(envoyProxy, 443))
+	assert.EqualValues(t, 8443, getContainerPort(envoyProxy, 8443))
+
+	// the port shift can be disabled in the EnvoyProxy
+	envoyProxy.Spec.Provider = &egv1a1.EnvoyProxyProvider{
+		Type: egv1a1.EnvoyProxyProviderTypeKubernetes,
+		Kubernetes: &egv1a1.EnvoyProxyKubernetesProvider{
+			UseListenerPortAsContainerPort: ptr.To(true),
+		},
+	}
+	assert.EqualValues(t, 443, getContainerPort(envoyProxy, 443))
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_shutdown(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		proxy            *v1alpha1.ProxyConfig
+		cleanup          *v1alpha1.CleanupConfig
+		expectedShutdown *egv1a1.ShutdownConfig
+	}{
+		{
+			desc: "should not render shutdown config when nothing is configured",
+		},
+		{
+			desc:    "should not use the drain timeout of the cleanup config",
+			cleanup: &v1alpha1.CleanupConfig{DrainTimeout: &metav1.Duration{Duration: 2 * time.Minute}},
+		},
+		{
+			desc: "should render the shutdown config of the proxy",
+			proxy: &v1alpha1.ProxyConfig{
+				Shutdown: &v1alpha1.ProxyShutdownConfig{
+					DrainTimeout:     &metav1.Duration{Duration: 90 * time.Second},
+					MinDrainDuration: &metav1.Duration{Duration: 15 * time.Second},
+				},
+			},
+			expectedShutdown: &egv1a1.ShutdownConfig{
+				DrainTimeout:     ptr.To[gatewayv1.Duration]("1m30s"),
+				MinDrainDuration: ptr.To[gatewayv1.Duration]("15s"),
+			},
+		},
+		{
+			desc: "should keep the drain timeout of the proxy separate from the cleanup config",
+			proxy: &v1alpha1.ProxyConfig{
+				Shutdown: &v1alpha1.ProxyShutdownConfig{
+					DrainTimeout: &metav1.Duration{Duration: 30 * time.Second},
+				},
+			},
+			cleanup: &v1alpha1.CleanupConfig{DrainTimeout: &metav1.Duration{Duration: 2 * time.Minute}},
+			expectedShutdown: &egv1a1.ShutdownConfig{
+				DrainTimeout: ptr.To[gatewayv1.Duration]("30s"),
+			},
+		},
+		{
+			desc: "should render the min drain duration of the proxy alone",
+			proxy: &v1alpha1.ProxyConfig{
+				Shutdown: &v1alpha1.ProxyShutdownConfig{
+					MinDrainDuration: &metav1.Duration{Duration: 5 * time.Second},
+				},
+			},
+			cleanup: &v1alpha1.CleanupConfig{DrainTimeout: &metav1.Duration{Duration: time.Minute}},
+			expectedShutdown: &egv1a1.ShutdownConfig{
+				MinDrainDuration: ptr.To[gatewayv1.Duration]("5s"),
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				CleanupConfig: tC.cleanup,
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: tC.proxy,
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedShutdown, envoyProxy.Spec.Shutdown)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_bootstrap(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		bootstrap         *v1alpha1.ProxyBootstrapConfig
+		expectedBootstrap *egv1a1.ProxyBootstrap
+	}{
+		{
+			desc: "should not render bootstrap when unset",
+		},
+		{
+			desc: "should render merge bootstrap",
+			bootstrap: &v1alpha1.ProxyBootstrapConfig{
+				Type:  egv1a1.BootstrapTypeMerge,
+				Value: "admin: {}",
+			},
+			expectedBootstrap: &egv1a1.ProxyBootstrap{
+				Type:  ptr.To(egv1a1.BootstrapTypeMerge),
+				Value: ptr.To("admin: {}"),
+			},
+		},
+		{
+			desc: "should render JSON patch bootstrap",
+			bootstrap: &v1alpha1.ProxyBootstrapConfig{
+				Type:  egv1a1.BootstrapTypeJSONPatch,
+				Value: "- op: remove\n  path: /admin\n",
+			},
+			expectedBootstrap: &egv1a1.ProxyBootstrap{
+				Type: ptr.To(egv1a1.BootstrapTypeJSONPatch),
+				JSONPatches: []egv1a1.JSONPatchOperation{
+					{Op: "remove", Path: ptr.To("/admin")},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: &v1alpha1.ProxyConfig{
+						Bootstrap: tC.bootstrap,
+					},
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedBootstrap, envoyProxy.Spec.Bootstrap)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_logging(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		logging         *v1alpha1.ProxyLoggingConfig
+		expectedLogging egv1a1.ProxyLogging
+	}{
+		{
+			desc: "should not render log levels when unset",
+		},
+		{
+			desc: "should render default level and component overrides",
+			logging: &v1alpha1.ProxyLoggingConfig{
+				Level: egv1a1.LogLevelInfo,
+				Components: map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{
+					egv1a1.LogComponentUpstream: egv1a1.LogLevelDebug,
+				},
+			},
+			expectedLogging: egv1a1.ProxyLogging{
+				Level: map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{
+					egv1a1.LogComponentDefault:  egv1a1.LogLevelInfo,
+					egv1a1.LogComponentUpstream: egv1a1.LogLevelDebug,
+				},
+			},
+		},
+		{
+			desc: "should leave the default level unset when only components are configured",
+			logging: &v1alpha1.ProxyLoggingConfig{
+				Components: map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{
+					egv1a1.LogComponentHTTP: egv1a1.LogLevelTrace,
+				},
+			},
+			expectedLogging: egv1a1.ProxyLogging{
+				Level: map[egv1a1.ProxyLogComponent]egv1a1.LogLevel{
+					egv1a1.LogComponentHTTP: egv1a1.LogLevelTrace,
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: &v1alpha1.ProxyConfig{
+						Logging: tC.logging,
+					},
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedLogging, envoyProxy.Spec.Logging)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcileEnvoyProxyFunc_providerType(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		proxy            *v1alpha1.ProxyConfig
+		expectedProvider *egv1a1.EnvoyProxyProvider
+	}{
+		{
+			desc: "should default to the Kubernetes provider",
+			expectedProvider: &egv1a1.EnvoyProxyProvider{
+				Type: egv1a1.EnvoyProxyProviderTypeKubernetes,
+				Kubernetes: &egv1a1.EnvoyProxyKubernetesProvider{
+					EnvoyDeployment: &egv1a1.KubernetesDeploymentSpec{
+						Pod:       &egv1a1.KubernetesPodSpec{},
+						Container: &egv1a1.KubernetesContainerSpec{},
+					},
+				},
+			},
+		},
+		{
+			desc: "should render replicas for the Kubernetes provider",
+			proxy: &v1alpha1.ProxyConfig{
+				ProviderType: egv1a1.EnvoyProxyProviderTypeKubernetes,
+				Replicas:     ptr.To[int32](3),
+			},
+			expectedProvider: &egv1a1.EnvoyProxyProvider{
+				Type: egv1a1.EnvoyProxyProviderTypeKubernetes,
+				Kubernetes: &egv1a1.EnvoyProxyKubernetesProvider{
+					EnvoyDeployment: &egv1a1.KubernetesDeploymentSpec{
+						Replicas:  ptr.To[int32](3),
+						Pod:       &egv1a1.KubernetesPodSpec{},
+						Container: &egv1a1.KubernetesContainerSpec{},
+					},
+				},
+			},
+		},
+		{
+			desc: "should render the Host provider",
+			proxy: &v1alpha1.ProxyConfig{
+				ProviderType: egv1a1.EnvoyProxyProviderTypeHost,
+			},
+			expectedProvider: &egv1a1.EnvoyProxyProvider{
+				Type: egv1a1.EnvoyProxyProviderTypeHost,
+				Host: &egv1a1.EnvoyProxyHostProvider{},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: tC.proxy,
+				},
+			}
+			envoyProxy := g.getEnvoyProxy()
+			err := g.reconcileEnvoyProxyFunc(envoyProxy)()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedProvider, envoyProxy.Spec.Provider)
+			}
+		})
+	}
+}
+
+func Test_createOrUpdate_contentHash(t *testing.T) {
+	desiredData := map[string]string{"foo": "bar"}
+	desiredHash, err := contentHash(&corev1.ConfigMap{Data: desiredData})
+	if !assert.NoError(t, err) {
+		return
+	}
+	newConfigMap := func(data map[string]string, annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Data: data,
+		}
+	}
+
+	testCases := []struct {
+		desc           string
+		existing       *corev1.ConfigMap
+		expectedUpdate bool
+	}{
+		{
+			desc:           "should not update object when hash and content match",
+			existing:       newConfigMap(desiredData, map[string]string{contentHashAnnotation: desiredHash}),
+			expectedUpdate: false,
+		},
+		{
+			desc:           "should update object when hash differs",
+			existing:       newConfigMap(desiredData, map[string]string{contentHashAnnotation: "outdated"}),
+			expectedUpdate: true,
+		},
+		{
+			desc:           "should update object when hash is missing",
+			existing:       newConfigMap(desiredData, nil),
+			expectedUpdate: true,
+		},
+		{
+			desc:           "should update object when content differs",
+			existing:       newConfigMap(map[string]string{"foo": "modified"}, map[string]string{contentHashAnnotation: "outdated"}),
+			expectedUpdate: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			updated := false
+			ts := testSetup{
+				clusterInitObjs: []client.Object{tC.existing},
+				clusterInterceptorFuncs: interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						updated = true
+						return c.Update(ctx, obj, opts...)
+					},
+				},
+			}
+			clusterClient, _, _ := ts.build()
+
+			mutations := 0
+			obj := newConfigMap(nil, nil)
+			op := applyOperation{
+				obj: obj,
+				f: func() error {
+					mutations++
+					obj.Data = desiredData
+					return nil
+				},
+			}
+			err := createOrUpdate(t.Context(), clusterClient, op)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tC.expectedUpdate, updated)
+			assert.Equal(t, 1, mutations, "mutate function must run exactly once")
+
+			actual := &corev1.ConfigMap{}
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(tC.existing), actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, desiredData, actual.Data)
+				assert.Equal(t, desiredHash, actual.Annotations[contentHashAnnotation])
+			}
+		})
+	}
+}
+
+func Test_Gateway_checkGatewayAPIVersion(t *testing.T) {
+	gatewayGVK := func(version string) schema.GroupVersionKind {
+		return schema.GroupVersionKind{Group: gatewayv1.GroupName, Version: version, Kind: "Gateway"}
+	}
+	testCases := []struct {
+		desc        string
+		versions    []string
+		expectedErr string
+	}{
+		{
+			desc: "should ignore missing CRDs",
+		},
+		{
+			desc:     "should accept v1",
+			versions: []string{"v1", "v1beta1"},
+		},
+		{
+			desc:        "should reject v1beta1 only",
+			versions:    []string{"v1beta1"},
+			expectedErr: "unsupported Gateway API version: the cluster serves gateway.networking.k8s.io in version(s) v1beta1, but v1 is required",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{
+				{Group: gatewayv1.GroupName, Version: "v1"},
+				{Group: gatewayv1.GroupName, Version: "v1beta1"},
+			})
+			for _, version := range tC.versions {
+				mapper.Add(gatewayGVK(version), meta.RESTScopeNamespace)
+			}
+			ts := testSetup{}
+			_, _, g := ts.build()
+			g.ClusterClient = fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+
+			err := g.checkGatewayAPIVersion()
+			if tC.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrUnsupportedGatewayAPIVersion)
+			assert.EqualError(t, err, tC.expectedErr)
+		})
+	}
+}
+
+func Test_createOrUpdateAll(t *testing.T) {
+	errCreate := errors.New("create failed")
+	newOps := func() []applyOperation {
+		ops := []applyOperation{}
+		for _, name := range []string{"a", "b", "c"} {
+			ops = append(ops, applyOperation{
+				obj: &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "default",
+					},
+				},
+				f: func() error { return nil },
+			})
+		}
+		return ops
+	}
+	testCases := []struct {
+		desc            string
+		apply           func(ctx context.Context, c client.Client, ops ...applyOperation) error
+		expectedErrObjs []string
+		expectedCreated bool
+	}{
+		{
+			desc:            "createOrUpdate should stop at the first failure",
+			apply:           createOrUpdate,
+			expectedErrObjs: []string{"ConfigMap/default/a"},
+			expectedCreated: false,
+		},
+		{
+			desc:            "createOrUpdateAll should report all failures",
+			apply:           createOrUpdateAll,
+			expectedErrObjs: []string{"ConfigMap/default/a", "ConfigMap/default/c"},
+			expectedCreated: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{
+				clusterInterceptorFuncs: interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if obj.GetName() != "b" {
+							return errCreate
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+				},
+			}
+			clusterClient, _, _ := ts.build()
+
+			err := tC.apply(t.Context(), clusterClient, newOps()...)
+			assert.ErrorIs(t, err, errCreate)
+			for _, id := range tC.expectedErrObjs {
+				assert.ErrorContains(t, err, "failed to apply "+id)
+			}
+			assert.Equal(t, len(tC.expectedErrObjs), strings.Count(err.Error(), "failed to apply"))
+
+			err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "b", Namespace: "default"}, &corev1.ConfigMap{})
+			assert.Equal(t, tC.expectedCreated, err == nil)
+		})
+	}
+}
+
+func Test_formatDuration(t *testing.T) {
+	testCases := []struct {
+		duration time.Duration
+		expected gatewayv1.Duration
+	}{
+		{duration: 0, expected: "0s"},
+		{duration: 30 * time.Second, expected: "30s"},
+		{duration: 90 * time.Minute, expected: "1h30m"},
+		{duration: 1500 * time.Millisecond, expected: "1s500ms"},
+		{duration: 2*time.Hour + 5*time.Second, expected: "2h5s"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.duration.String(), func(t *testing.T) {
+			assert.Equal(t, tC.expected, formatDuration(tC.duration))
+		})
+	}
+}
+
+func Test_ensureDeletionOfObjects(t *testing.T) {
+	stuck := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "stuck",
+			Namespace:  "default",
+			Finalizers: []string{"example.com/finalizer"},
+		},
+	}
+	deletable := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deletable",
+			Namespace: "default",
+		},
+	}
+	ts := testSetup{
+		clusterInitObjs: []client.Object{stuck, deletable},
+	}
+	clusterClient, _, _ := ts.build()
+
+	objs := func() []client.Object {
+		return []client.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "deletable", Namespace: "default"}},
+		}
+	}
+
+	// first run triggers the deletion of all objects
+	err := ensureDeletionOfObjects(t.Context(), clusterClient, deletionOptions{}, objs()...)
+	assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+
+	// second run only reports the object which is still pending deletion
+	err = ensureDeletionOfObjects(t.Context(), clusterClient, deletionOptions{}, objs()...)
+	rr := &utils.RemainingResourcesError{}
+	if assert.ErrorAs(t, err, &rr) && assert.Len(t, rr.Objects, 1) {
+		assert.Equal(t, "stuck", rr.Objects[0].GetName())
+		assert.NotNil(t, rr.Objects[0].GetDeletionTimestamp())
+		assert.Contains(t, rr.Error(), "deleting for")
+	}
+}
+
+func Test_ensureDeletionOfObjects_options(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		opts                 deletionOptions
+		expectedPropagation  *metav1.DeletionPropagation
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			desc:                 "should use defaults",
+			expectedRequeueAfter: defaultDeletionRetryInterval,
+		},
+		{
+			desc: "should pass propagation policy and requeue interval",
+			opts: deletionOptions{
+				propagationPolicy: ptr.To(metav1.DeletePropagationForeground),
+				requeueAfter:      time.Minute,
+			},
+			expectedPropagation:  ptr.To(metav1.DeletePropagationForeground),
+			expectedRequeueAfter: time.Minute,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var propagation *metav1.DeletionPropagation
+			ts := testSetup{
+				clusterInitObjs: []client.Object{
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "stuck",
+							Namespace:  "default",
+							Finalizers: []string{"example.com/finalizer"},
+						},
+					},
+				},
+				clusterInterceptorFuncs: interceptor.Funcs{
+					Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+						propagation = (&client.DeleteOptions{}).ApplyOptions(opts).PropagationPolicy
+						return c.Delete(ctx, obj, opts...)
+					},
+				},
+			}
+			clusterClient, _, _ := ts.build()
+
+			err := ensureDeletionOfObjects(t.Context(), clusterClient, tC.opts,
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default"}})
+			retryable := &utils.RetryableError{}
+			if assert.ErrorAs(t, err, &retryable) {
+				assert.Equal(t, tC.expectedRequeueAfter, retryable.RequeueAfter)
+			}
+			assert.Equal(t, tC.expectedPropagation, propagation)
 		})
 	}
 }
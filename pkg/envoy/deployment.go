@@ -1,10 +1,14 @@
 package envoy
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
@@ -13,122 +17,516 @@ import (
 	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
 )
 
 var (
+	// ErrDrainInProgress occurs while the uninstallation waits for the drain timeout to elapse.
+	ErrDrainInProgress = errors.New("waiting for envoy proxies to drain")
+	// ErrReleaseFailed occurs if Flux gave up on the Helm release of Envoy Gateway, e.g. because the installation failed
+	// and its retries are exhausted. Flux only tries again once the HelmRelease or its chart changes.
+	ErrReleaseFailed = errors.New("HelmRelease of Envoy Gateway failed")
+
 	errFailedToGenerateHelmValuesJSON = errors.New("failed to generate Helm values JSON")
 )
 
 const (
-	deploymentNamespace = "envoy-gateway-system"
+	defaultDeploymentNamespace = "envoy-gateway-system"
+	drainStartedAtAnnotation   = "gateway.openmcp.cloud/drain-started-at"
+	helmChartMediaType         = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	defaultChartName           = "gateway-helm"
+	defaultReleaseName         = "eg"
 )
 
 type Gateway struct {
-	Cluster        *clustersv1alpha1.Cluster
-	EnvoyConfig    v1alpha1.EnvoyGatewayConfig
-	GatewayConfig  *v1alpha1.GatewayConfig
-	DNSConfig      v1alpha1.DNSConfig
-	PlatformClient client.Client
-	ClusterClient  client.Client
-	FluxKubeconfig *fluxmeta.KubeConfigReference
+	Cluster             *clustersv1alpha1.Cluster
+	EnvoyConfig         v1alpha1.EnvoyGatewayConfig
+	GatewayConfig       *v1alpha1.GatewayConfig
+	DNSConfig           v1alpha1.DNSConfig
+	CommonMetadata      *v1alpha1.CommonMetadata
+	GatewayNamespace    string
+	DeploymentNamespace string
+	CreateNamespaces    *bool
+	CleanupConfig       *v1alpha1.CleanupConfig
+	Suspend             bool
+	RequestedAt         string
+	Timings             Timings
+	PlatformClient      client.Client
+	ClusterClient       client.Client
+	FluxKubeconfig      *fluxmeta.KubeConfigReference
 }
 
 func (g *Gateway) InstallOrUpdate(ctx context.Context) error {
-	repo := g.getRepo()
+	sourceOp, staleSource := g.getChartSourceOperation()
 	helmRelease := g.getHelmRelease()
 
+	deploymentNamespace := g.getDeploymentNamespace()
 	imagePullSecretOps := g.ensureSecrets(ctx, deploymentNamespace)
 
-	ops := make([]applyOperation, 0, 3+len(imagePullSecretOps))
-	ops = append(ops, ensureNamespace(deploymentNamespace, g.ClusterClient))
+	ops := make([]applyOperation, 0, 4+len(imagePullSecretOps))
+	storageNamespace := g.getStorageNamespace()
+	if g.createNamespaces() {
+		ops = append(ops, ensureNamespace(deploymentNamespace, g.EnvoyConfig.DeploymentNamespaceLabels, g.ClusterClient))
+		if storageNamespace != deploymentNamespace {
+			ops = append(ops, ensureNamespace(storageNamespace, nil, g.ClusterClient))
+		}
+	} else if err := g.checkNamespacesExist(ctx, deploymentNamespace, storageNamespace); err != nil {
+		return err
+	}
 	ops = append(ops, imagePullSecretOps...)
+	if sa := g.getProxyServiceAccount(); sa != nil {
+		ops = append(ops, applyOperation{
+			obj: sa,
+			f:   g.reconcileProxyServiceAccountFunc(sa),
+			c:   g.ClusterClient,
+		})
+	}
 	ops = append(ops,
-		applyOperation{
-			obj: repo,
-			f:   g.reconcileOCIRepositoryFunc(repo),
-		},
+		sourceOp,
 		applyOperation{
 			obj: helmRelease,
-			f:   g.reconcileHelmReleaseFunc(repo.Name, helmRelease),
+			f:   g.reconcileHelmReleaseFunc(sourceOp.obj, helmRelease),
 		},
 	)
 
-	return createOrUpdate(ctx, g.PlatformClient, ops...)
+	if err := createOrUpdate(ctx, g.PlatformClient, ops...); err != nil {
+		return err
+	}
+
+	// remove the source of the previously configured chart type
+	return deleteObjects(ctx, g.PlatformClient, staleSource)
 }
 
 func (g *Gateway) Uninstall(ctx context.Context) error {
 	repo := g.getRepo()
+	helmRepo := g.getHelmRepository()
 	helmRelease := g.getHelmRelease()
 
-	return ensureDeletionOfObjects(ctx, g.PlatformClient, helmRelease, repo)
+	if err := g.resumeHelmRelease(ctx, helmRelease); err != nil {
+		return err
+	}
+
+	// the chart source is only deleted once the HelmRelease is gone, because Flux cannot uninstall a release whose source is missing
+	opts := g.getDeletionOptions()
+	if err := ensureDeletionOfObjects(ctx, g.PlatformClient, opts, helmRelease); err != nil {
+		return err
+	}
+	return ensureDeletionOfObjects(ctx, g.PlatformClient, opts, repo, helmRepo)
+}
+
+// waitForDrain delays the deletion of the gateway resources until the configured drain timeout has elapsed,
+// so that the listeners keep serving in-flight connections. The start of the drain window is recorded in an annotation
+// on the given HelmRelease, which is removed again by InstallOrUpdate.
+// It returns a *RetryableError as long as the drain window has not elapsed.
+func (g *Gateway) waitForDrain(ctx context.Context, obj *helmv2.HelmRelease) error {
+	drainTimeout := g.getDrainTimeout()
+	if drainTimeout == 0 {
+		return nil
+	}
+
+	if err := g.PlatformClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !obj.DeletionTimestamp.IsZero() {
+		// deletion has already been triggered
+		return nil
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, obj.Annotations[drainStartedAtAnnotation])
+	if err != nil {
+		// drain window has not been started yet
+		startedAt = time.Now()
+		patch := client.MergeFrom(obj.DeepCopy())
+		metav1.SetMetaDataAnnotation(&obj.ObjectMeta, drainStartedAtAnnotation, startedAt.UTC().Format(time.RFC3339))
+		if err := g.PlatformClient.Patch(ctx, obj, patch); err != nil {
+			return err
+		}
+	}
+
+	remaining := time.Until(startedAt.Add(drainTimeout))
+	if remaining > 0 {
+		return utils.NewRetryableError(fmt.Errorf("%w: %s remaining", ErrDrainInProgress, remaining.Round(time.Second)), remaining)
+	}
+	return nil
+}
+
+// resumeHelmRelease clears the suspend flag of the given HelmRelease before it is deleted,
+// because Flux skips the uninstallation of suspended releases.
+func (g *Gateway) resumeHelmRelease(ctx context.Context, obj *helmv2.HelmRelease) error {
+	if err := g.PlatformClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !obj.Spec.Suspend || !obj.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	patch := client.MergeFrom(obj.DeepCopy())
+	obj.Spec.Suspend = false
+	return g.PlatformClient.Patch(ctx, obj, patch)
+}
+
+func (g *Gateway) getDrainTimeout() time.Duration {
+	if g.CleanupConfig == nil || g.CleanupConfig.DrainTimeout == nil {
+		return 0
+	}
+	return g.CleanupConfig.DrainTimeout.Duration
+}
+
+func (g *Gateway) getDeletionOptions() deletionOptions {
+	opts := deletionOptions{
+		requeueAfter: g.Timings.getDeletionRetryInterval(),
+	}
+	if g.CleanupConfig == nil {
+		return opts
+	}
+	opts.propagationPolicy = g.CleanupConfig.PropagationPolicy
+	if g.CleanupConfig.RetryInterval != nil {
+		opts.requeueAfter = g.CleanupConfig.RetryInterval.Duration
+	}
+	return opts
+}
+
+func (g *Gateway) getDeploymentNamespace() string {
+	if g.DeploymentNamespace != "" {
+		return g.DeploymentNamespace
+	}
+	return defaultDeploymentNamespace
+}
+
+func (g *Gateway) getReleaseName() string {
+	if g.EnvoyConfig.ReleaseName != "" {
+		return g.EnvoyConfig.ReleaseName
+	}
+	return defaultReleaseName
+}
+
+func (g *Gateway) getStorageNamespace() string {
+	if g.EnvoyConfig.StorageNamespace != "" {
+		return g.EnvoyConfig.StorageNamespace
+	}
+	return g.getDeploymentNamespace()
+}
+
+// fluxResourceName returns the name of the Flux resources which install the gateway on the given Cluster.
+func fluxResourceName(cluster *clustersv1alpha1.Cluster) string {
+	return fmt.Sprintf("%s.gateway", cluster.Name)
+}
+
+// HasFluxResources checks if any of the Flux resources which install the gateway on the given Cluster
+// exist on the platform cluster, e.g. to detect installations which are left behind without a finalizer.
+func HasFluxResources(ctx context.Context, platformClient client.Client, cluster *clustersv1alpha1.Cluster) (bool, error) {
+	g := &Gateway{Cluster: cluster}
+	for _, obj := range []client.Object{g.getHelmRelease(), g.getRepo(), g.getHelmRepository()} {
+		err := platformClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if err == nil {
+			return true, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// finalReleaseFailureReasons are the reasons of the Ready condition of a HelmRelease which report a failed Helm action.
+// Once Flux stops reconciling, such a release isn't retried until it changes.
+var finalReleaseFailureReasons = []string{
+	helmv2.InstallFailedReason,
+	helmv2.UpgradeFailedReason,
+	helmv2.TestFailedReason,
+	helmv2.RollbackFailedReason,
+	helmv2.UninstallFailedReason,
+}
+
+// ReleaseInProgress checks if Flux is still installing or upgrading the Helm release of Envoy Gateway,
+// i.e. the release is reconciling or not ready for its current generation. Suspended releases are never in progress.
+// Failed releases, which are stalled or not ready with a final failure reason, are not in progress either,
+// for them an error wrapping ErrReleaseFailed is returned.
+func (g *Gateway) ReleaseInProgress(ctx context.Context) (bool, error) {
+	hr := g.getHelmRelease()
+	if err := g.PlatformClient.Get(ctx, client.ObjectKeyFromObject(hr), hr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if hr.Spec.Suspend {
+		return false, nil
+	}
+	if meta.IsStatusConditionTrue(hr.Status.Conditions, fluxmeta.ReconcilingCondition) || hr.Status.ObservedGeneration < hr.Generation {
+		return true, nil
+	}
+	if stalled := meta.FindStatusCondition(hr.Status.Conditions, fluxmeta.StalledCondition); stalled != nil && stalled.Status == metav1.ConditionTrue {
+		return false, fmt.Errorf("%w: %s", ErrReleaseFailed, stalled.Message)
+	}
+	ready := meta.FindStatusCondition(hr.Status.Conditions, fluxmeta.ReadyCondition)
+	if ready == nil || ready.Status == metav1.ConditionTrue {
+		return ready == nil, nil
+	}
+	if ready.Status == metav1.ConditionFalse && slices.Contains(finalReleaseFailureReasons, ready.Reason) {
+		return false, fmt.Errorf("%w: %s", ErrReleaseFailed, ready.Message)
+	}
+	return true, nil
 }
 
 func (g *Gateway) getRepo() *sourcev1.OCIRepository {
 	return &sourcev1.OCIRepository{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s.gateway", g.Cluster.Name),
+			Name:      fluxResourceName(g.Cluster),
+			Namespace: g.Cluster.Namespace,
+		},
+	}
+}
+
+func (g *Gateway) getHelmRepository() *sourcev1.HelmRepository {
+	return &sourcev1.HelmRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fluxResourceName(g.Cluster),
 			Namespace: g.Cluster.Namespace,
 		},
 	}
 }
 
+// getChartSourceOperation returns the operation reconciling the source of the configured chart type
+// and the source of the other chart type, which is no longer needed.
+func (g *Gateway) getChartSourceOperation() (applyOperation, client.Object) {
+	if g.EnvoyConfig.Chart.Type == v1alpha1.ChartTypeHTTP {
+		helmRepo := g.getHelmRepository()
+		return applyOperation{obj: helmRepo, f: g.reconcileHelmRepositoryFunc(helmRepo)}, g.getRepo()
+	}
+	repo := g.getRepo()
+	return applyOperation{obj: repo, f: g.reconcileOCIRepositoryFunc(repo)}, g.getHelmRepository()
+}
+
+func (g *Gateway) getChartName() string {
+	if g.EnvoyConfig.Chart.Name != "" {
+		return g.EnvoyConfig.Chart.Name
+	}
+	return defaultChartName
+}
+
+// getChartSecretRefs returns the references to the authentication secrets of the chart repository.
+func (g *Gateway) getChartSecretRefs() (secretRef, certSecretRef *fluxmeta.LocalObjectReference) {
+	return g.EnvoyConfig.Chart.SecretRef, g.EnvoyConfig.Chart.CertSecretRef
+}
+
 func (g *Gateway) getHelmRelease() *helmv2.HelmRelease {
 	return &helmv2.HelmRelease{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s.gateway", g.Cluster.Name),
+			Name:      fluxResourceName(g.Cluster),
 			Namespace: g.Cluster.Namespace,
 		},
 	}
 }
 
+// applyClusterLabels marks the given Flux resource as managed and links it to the Cluster,
+// so that it can be collected if the Cluster is gone without uninstalling the gateway.
+// The Cluster is identified by annotations, as its name may exceed the maximum length of a label value.
+func (g *Gateway) applyClusterLabels(obj client.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedByLabel] = managedByLabelValue
+	labels[v1alpha1.ClusterNameLabel] = v1alpha1.ClusterNameLabelValue(g.Cluster.Name)
+	labels[v1alpha1.ClusterNamespaceLabel] = g.Cluster.Namespace
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1alpha1.ClusterNameAnnotation] = g.Cluster.Name
+	annotations[v1alpha1.ClusterNamespaceAnnotation] = g.Cluster.Namespace
+	obj.SetAnnotations(annotations)
+}
+
 func (g *Gateway) reconcileOCIRepositoryFunc(obj *sourcev1.OCIRepository) func() error {
 	return func() error {
+		g.applyCommonMetadata(obj)
+		g.applyClusterLabels(obj)
 		obj.Spec.Interval = metav1.Duration{Duration: 10 * time.Hour}
-		obj.Spec.LayerSelector = &sourcev1.OCILayerSelector{
-			MediaType: "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
-			Operation: "copy",
-		}
+		g.applyReconcileRequest(obj)
+		obj.Spec.Suspend = g.Suspend
+		obj.Spec.LayerSelector = g.getLayerSelector(obj)
 		obj.Spec.URL = g.EnvoyConfig.Chart.URL
 		obj.Spec.Reference = &sourcev1.OCIRepositoryRef{
 			Tag: g.EnvoyConfig.Chart.Tag,
 		}
 
-		obj.Spec.SecretRef = g.EnvoyConfig.Chart.SecretRef
+		obj.Spec.SecretRef, obj.Spec.CertSecretRef = g.getChartSecretRefs()
+
+		return nil
+	}
+}
+
+func (g *Gateway) reconcileHelmRepositoryFunc(obj *sourcev1.HelmRepository) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+		g.applyClusterLabels(obj)
+		obj.Spec.Interval = metav1.Duration{Duration: 10 * time.Hour}
+		g.applyReconcileRequest(obj)
+		obj.Spec.Suspend = g.Suspend
+		obj.Spec.URL = g.EnvoyConfig.Chart.URL
+		obj.Spec.SecretRef, obj.Spec.CertSecretRef = g.getChartSecretRefs()
+
+		return nil
+	}
+}
+
+// applyReconcileRequest sets RequestedAt as reconcile request annotation on the given Flux resource,
+// which makes Flux re-pull the chart and re-deploy the release immediately. The annotation is left untouched if RequestedAt is empty.
+func (g *Gateway) applyReconcileRequest(obj client.Object) {
+	if g.RequestedAt == "" {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[fluxmeta.ReconcileRequestAnnotation] = g.RequestedAt
+	obj.SetAnnotations(annotations)
+}
+
+// getCRDsPolicy returns how the CRDs of the chart are handled on install and upgrade of the Helm release.
+func (g *Gateway) getCRDsPolicy() helmv2.CRDsPolicy {
+	if g.EnvoyConfig.InstallCRDs != nil && !*g.EnvoyConfig.InstallCRDs {
+		return helmv2.Skip
+	}
+	return helmv2.CreateReplace
+}
+
+// getLayerSelector returns the layer selector of the given OCIRepository, which is evaluated before it is updated.
+func (g *Gateway) getLayerSelector(obj *sourcev1.OCIRepository) *sourcev1.OCILayerSelector {
+	selector := &sourcev1.OCILayerSelector{
+		MediaType: helmChartMediaType,
+		Operation: sourcev1.OCILayerCopy,
+	}
+
+	cfg := g.EnvoyConfig.Chart.LayerSelector
+	if cfg != nil && cfg.Disabled {
+		return nil
+	}
+	if cfg != nil && cfg.MediaType != "" {
+		selector.MediaType = cfg.MediaType
+	}
+	if cfg != nil && cfg.Operation != "" {
+		selector.Operation = cfg.Operation
+	}
+	if mediaTypes := g.EnvoyConfig.Chart.LayerMediaTypes; len(mediaTypes) > 0 {
+		next := g.getNextLayerMediaType(obj)
+		if next == len(mediaTypes) {
+			// none of the media types matched, let Flux use the first layer
+			return nil
+		}
+		selector.MediaType = mediaTypes[next]
+	}
+	return selector
+}
+
+// getNextLayerMediaType returns the index of the LayerMediaTypes entry which the OCIRepository should select.
+// The media type of the existing OCIRepository is kept unless Flux failed to find its layer, in which case the
+// next one is returned. An index equal to the number of media types means that the selector is omitted.
+// The media types are tried from the start for a new OCIRepository or after the chart URL or tag changed.
+func (g *Gateway) getNextLayerMediaType(obj *sourcev1.OCIRepository) int {
+	mediaTypes := g.EnvoyConfig.Chart.LayerMediaTypes
+	if obj.Spec.URL != g.EnvoyConfig.Chart.URL || obj.Spec.Reference == nil || obj.Spec.Reference.Tag != g.EnvoyConfig.Chart.Tag {
+		return 0
+	}
+	current := len(mediaTypes)
+	if obj.Spec.LayerSelector != nil {
+		current = slices.Index(mediaTypes, obj.Spec.LayerSelector.MediaType)
+		if current < 0 {
+			return 0
+		}
+	}
+	if current < len(mediaTypes) && layerOperationFailed(obj) {
+		return current + 1
+	}
+	return current
+}
+
+// layerOperationFailed returns true if Flux failed to process the layer of the current generation of the OCIRepository.
+func layerOperationFailed(obj *sourcev1.OCIRepository) bool {
+	ready := meta.FindStatusCondition(obj.Status.Conditions, fluxmeta.ReadyCondition)
+	return ready != nil && ready.Status == metav1.ConditionFalse &&
+		ready.Reason == sourcev1.OCILayerOperationFailedReason && ready.ObservedGeneration == obj.Generation
+}
 
+// getDriftDetection returns the drift detection of the HelmRelease, nil if it is not configured.
+func (g *Gateway) getDriftDetection() *helmv2.DriftDetection {
+	cfg := g.EnvoyConfig.Chart.DriftDetection
+	if cfg == nil {
 		return nil
 	}
+
+	driftDetection := &helmv2.DriftDetection{
+		Mode: helmv2.DriftDetectionMode(cmp.Or(cfg.Mode, string(helmv2.DriftDetectionDisabled))),
+	}
+	if len(cfg.IgnorePaths) > 0 {
+		driftDetection.Ignore = []helmv2.IgnoreRule{{Paths: cfg.IgnorePaths}}
+	}
+	return driftDetection
 }
 
-func (g *Gateway) reconcileHelmReleaseFunc(repoName string, obj *helmv2.HelmRelease) func() error {
+func (g *Gateway) reconcileHelmReleaseFunc(source client.Object, obj *helmv2.HelmRelease) func() error {
 	return func() error {
 		values, err := g.generateHelmValuesJSON()
 		if err != nil {
 			return errors.Join(errFailedToGenerateHelmValuesJSON, err)
 		}
 
+		g.applyCommonMetadata(obj)
+		g.applyClusterLabels(obj)
+		g.applyReconcileRequest(obj)
+		// the uninstallation has been aborted, a later one has to wait for the full drain window again
+		delete(obj.Annotations, drainStartedAtAnnotation)
+
 		obj.Spec.Interval = metav1.Duration{Duration: 1 * time.Hour}
+		obj.Spec.Suspend = g.Suspend
+		crdsPolicy := g.getCRDsPolicy()
 		obj.Spec.Install = &helmv2.Install{
-			CRDs: helmv2.CreateReplace,
+			CRDs: crdsPolicy,
 			Remediation: &helmv2.InstallRemediation{
 				Retries: 3,
 			},
 		}
 		obj.Spec.Upgrade = &helmv2.Upgrade{
-			CRDs: helmv2.CreateReplace,
+			CRDs: crdsPolicy,
 			Remediation: &helmv2.UpgradeRemediation{
 				Retries: 3,
 			},
 		}
-		obj.Spec.ReleaseName = "eg"
-		obj.Spec.StorageNamespace = deploymentNamespace
-		obj.Spec.TargetNamespace = deploymentNamespace
-		obj.Spec.ChartRef = &helmv2.CrossNamespaceSourceReference{
-			Kind: "OCIRepository",
-			Name: repoName,
+		obj.Spec.DriftDetection = g.getDriftDetection()
+		obj.Spec.ReleaseName = g.getReleaseName()
+		obj.Spec.StorageNamespace = g.getStorageNamespace()
+		obj.Spec.TargetNamespace = g.getDeploymentNamespace()
+		if _, ok := source.(*sourcev1.HelmRepository); ok {
+			obj.Spec.ChartRef = nil
+			obj.Spec.Chart = &helmv2.HelmChartTemplate{
+				Spec: helmv2.HelmChartTemplateSpec{
+					Chart:   g.getChartName(),
+					Version: g.EnvoyConfig.Chart.Tag,
+					SourceRef: helmv2.CrossNamespaceObjectReference{
+						Kind: sourcev1.HelmRepositoryKind,
+						Name: source.GetName(),
+					},
+				},
+			}
+		} else {
+			obj.Spec.Chart = nil
+			obj.Spec.ChartRef = &helmv2.CrossNamespaceSourceReference{
+				Kind: sourcev1.OCIRepositoryKind,
+				Name: source.GetName(),
+			}
 		}
 		obj.Spec.Values = values
 		obj.Spec.KubeConfig = g.FluxKubeconfig
@@ -137,11 +535,16 @@ func (g *Gateway) reconcileHelmReleaseFunc(repoName string, obj *helmv2.HelmRele
 }
 
 func (g *Gateway) reconcileSecretFunc(ctx context.Context, obj *corev1.Secret) func() error {
+	return g.reconcileSecretFromFunc(ctx, obj.Name, obj)
+}
+
+// reconcileSecretFromFunc copies the Secret with the given name from the namespace of the Cluster into obj.
+func (g *Gateway) reconcileSecretFromFunc(ctx context.Context, sourceName string, obj *corev1.Secret) func() error {
 	return func() error {
 		sourceSecret := &corev1.Secret{}
 		sourceKey := client.ObjectKey{
 			Namespace: g.Cluster.Namespace,
-			Name:      obj.Name,
+			Name:      sourceName,
 		}
 		if err := g.PlatformClient.Get(ctx, sourceKey, sourceSecret); err != nil {
 			return fmt.Errorf("failed to get secret %s: %w", sourceKey, err)
@@ -176,10 +579,137 @@ func (g *Gateway) ensureSecrets(ctx context.Context, targetNamespace string) []a
 	return ops
 }
 
+// getProxyServiceAccount returns the configured ServiceAccount of the Envoy Proxy in the deployment namespace or nil if none is configured.
+func (g *Gateway) getProxyServiceAccount() *corev1.ServiceAccount {
+	if g.EnvoyConfig.Proxy == nil || g.EnvoyConfig.Proxy.ServiceAccount == nil {
+		return nil
+	}
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      g.EnvoyConfig.Proxy.ServiceAccount.Name,
+			Namespace: g.getDeploymentNamespace(),
+		},
+	}
+}
+
+// reconcileProxyServiceAccountFunc adds the configured annotations to the ServiceAccount.
+// Only a ServiceAccount created by this controller is labeled as managed, so that an existing one is kept during cleanup.
+func (g *Gateway) reconcileProxyServiceAccountFunc(obj *corev1.ServiceAccount) func() error {
+	return func() error {
+		if obj.ResourceVersion == "" {
+			g.applyCommonMetadata(obj)
+			metav1.SetMetaDataLabel(&obj.ObjectMeta, managedByLabel, managedByLabelValue)
+		}
+		for k, v := range g.EnvoyConfig.Proxy.ServiceAccount.Annotations {
+			metav1.SetMetaDataAnnotation(&obj.ObjectMeta, k, v)
+		}
+		return nil
+	}
+}
+
+// getManagedProxyServiceAccount returns the configured ServiceAccount of the Envoy Proxy if it has been created by this controller.
+func (g *Gateway) getManagedProxyServiceAccount(ctx context.Context) (*corev1.ServiceAccount, error) {
+	obj := g.getProxyServiceAccount()
+	if obj == nil {
+		return nil, nil
+	}
+	if err := g.ClusterClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if obj.Labels[managedByLabel] != managedByLabelValue {
+		return nil, nil
+	}
+	return obj, nil
+}
+
 func (g *Gateway) generateHelmValuesJSON() (*apiextensionsv1.JSON, error) {
-	values := g.generateHelmValues()
+	return marshalHelmValues(g.generateHelmValues())
+}
+
+// HelmValues returns the Helm values of the Envoy Gateway release as indented JSON, exactly as they are passed to Flux.
+// Only the config of the Gateway is used, no client is required.
+func (g *Gateway) HelmValues() ([]byte, error) {
+	values, err := g.generateHelmValuesJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, values.Raw, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// HelmValuesError occurs when the generated Helm values cannot be marshaled to JSON.
+type HelmValuesError struct {
+	// Path is the dot-separated key path of the value which cannot be marshaled, e.g. "global.images".
+	// It is empty if the offending value could not be determined.
+	Path string
+	// Value is the value at Path.
+	Value any
+	// Err is the error returned by the JSON encoder.
+	Err error
+}
+
+func (e *HelmValuesError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("failed to marshal Helm values: %s", e.Err)
+	}
+	return fmt.Sprintf("failed to marshal Helm value %q of type %T: %s", e.Path, e.Value, e.Err)
+}
+
+func (e *HelmValuesError) Unwrap() error {
+	return e.Err
+}
+
+// marshalHelmValues marshals the given values to JSON. On failure, a *HelmValuesError identifies the offending value.
+func marshalHelmValues(values map[string]any) (*apiextensionsv1.JSON, error) {
 	raw, err := json.Marshal(values)
-	return &apiextensionsv1.JSON{Raw: raw}, err
+	if err != nil {
+		path, value := findUnmarshalableValue(values, "")
+		return nil, &HelmValuesError{Path: path, Value: value, Err: err}
+	}
+	return &apiextensionsv1.JSON{Raw: raw}, nil
+}
+
+// findUnmarshalableValue returns the path and the value of the innermost value which cannot be marshaled to JSON.
+// Keys are checked in sorted order, so the result is deterministic.
+func findUnmarshalableValue(values map[string]any, prefix string) (string, any) {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		if _, err := json.Marshal(value); err == nil {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			if nestedPath, nestedValue := findUnmarshalableValue(nested, path); nestedPath != "" {
+				return nestedPath, nestedValue
+			}
+		}
+		return path, value
+	}
+	return "", nil
+}
+
+// generateRateLimitDeploymentValues returns the configured settings of the rate limit deployment.
+// Unset settings are omitted, so that the Envoy Gateway defaults apply.
+func generateRateLimitDeploymentValues(cfg *v1alpha1.RateLimitDeploymentConfig) map[string]any {
+	values := map[string]any{}
+	if cfg == nil {
+		return values
+	}
+	if cfg.Replicas != nil {
+		values["replicas"] = *cfg.Replicas
+	}
+	if cfg.Resources != nil {
+		values["container"] = map[string]any{
+			"resources": cfg.Resources,
+		}
+	}
+	return values
 }
 
 func (g *Gateway) generateHelmValues() map[string]any {
@@ -204,10 +734,71 @@ func (g *Gateway) generateHelmValues() map[string]any {
 		}
 	}
 
-	return map[string]any{
+	values := map[string]any{
 		"global": map[string]any{
 			"images":           images,
 			"imagePullSecrets": imagePullSecrets,
 		},
 	}
+
+	envoyGateway := map[string]any{}
+	kubernetesProvider := map[string]any{}
+	if rl := g.EnvoyConfig.RateLimit; rl != nil {
+		envoyGateway["rateLimit"] = map[string]any{
+			"backend": map[string]any{
+				"type": "Redis",
+				"redis": map[string]any{
+					"url": rl.RedisURL,
+				},
+			},
+		}
+		if deployment := generateRateLimitDeploymentValues(rl.Deployment); len(deployment) > 0 {
+			kubernetesProvider["rateLimitDeployment"] = deployment
+		}
+	}
+	if img := g.EnvoyConfig.Images; img != nil && img.ShutdownManager != "" {
+		// the shutdown-manager sidecar of the proxy pods is configured by Envoy Gateway, not by the EnvoyProxy
+		kubernetesProvider["shutdownManager"] = map[string]any{
+			"image": img.ShutdownManager,
+		}
+	}
+	if len(kubernetesProvider) > 0 {
+		envoyGateway["provider"] = map[string]any{
+			"kubernetes": kubernetesProvider,
+		}
+	}
+	if g.EnvoyConfig.LogLevel != "" {
+		envoyGateway["logging"] = map[string]any{
+			"level": map[string]any{
+				"default": g.EnvoyConfig.LogLevel,
+			},
+		}
+	}
+	if g.EnvoyConfig.ControllerName != "" {
+		envoyGateway["gateway"] = map[string]any{
+			"controllerName": g.EnvoyConfig.ControllerName,
+		}
+	}
+	if g.envoyPatchPolicyEnabled() {
+		envoyGateway["extensionApis"] = map[string]any{
+			"enableEnvoyPatchPolicy": true,
+		}
+	}
+	if len(envoyGateway) > 0 {
+		values["config"] = map[string]any{
+			"envoyGateway": envoyGateway,
+		}
+	}
+	if installCRDs := g.EnvoyConfig.InstallCRDs; installCRDs != nil {
+		values["crds"] = map[string]any{
+			"gatewayAPI": map[string]any{
+				"enabled": *installCRDs,
+			},
+			"envoyGateway": map[string]any{
+				"enabled": *installCRDs,
+			},
+		}
+	}
+
+	return values
 }
@@ -1,9 +1,14 @@
 package envoy
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/pkg/apis/meta"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
@@ -11,10 +16,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
 	"github.com/openmcp-project/platform-service-gateway/internal/schemes"
@@ -27,11 +36,22 @@ type testSetup struct {
 	platformInterceptorFuncs interceptor.Funcs
 	platformInitObjs         []client.Object
 	imagePullSecrets         []corev1.LocalObjectReference
+	namespaceLabels          map[string]string
+	commonMetadata           *v1alpha1.CommonMetadata
+	gatewayNamespace         string
+	deploymentNamespace      string
+	// gatewayClassNotAccepted disables the simulated acceptance of the GatewayClass by the Envoy Gateway controller.
+	gatewayClassNotAccepted bool
 }
 
 func (ts *testSetup) build() (clusterClient, platformClient client.WithWatch, g *Gateway) {
+	clusterInterceptorFuncs := ts.clusterInterceptorFuncs
+	if !ts.gatewayClassNotAccepted {
+		clusterInterceptorFuncs.Get = acceptGatewayClass(clusterInterceptorFuncs.Get)
+	}
+
 	clusterClient = fake.NewClientBuilder().
-		WithInterceptorFuncs(ts.clusterInterceptorFuncs).
+		WithInterceptorFuncs(clusterInterceptorFuncs).
 		WithObjects(ts.clusterInitObjs...).
 		WithScheme(schemes.Target).
 		Build()
@@ -43,9 +63,15 @@ func (ts *testSetup) build() (clusterClient, platformClient client.WithWatch, g
 		Build()
 
 	g = &Gateway{
-		PlatformClient: platformClient,
-		ClusterClient:  clusterClient,
-		Cluster:        testCluster,
+		PlatformClient:      platformClient,
+		ClusterClient:       clusterClient,
+		Cluster:             testCluster,
+		CommonMetadata:      ts.commonMetadata,
+		GatewayNamespace:    ts.gatewayNamespace,
+		DeploymentNamespace: ts.deploymentNamespace,
+		DNSConfig: v1alpha1.DNSConfig{
+			BaseDomain: "example.com",
+		},
 		FluxKubeconfig: &meta.KubeConfigReference{
 			SecretRef: &meta.SecretKeyReference{
 				Name: "secret",
@@ -63,11 +89,32 @@ func (ts *testSetup) build() (clusterClient, platformClient client.WithWatch, g
 				Ratelimit:        testRatelimitImg,
 				EnvoyProxy:       testEnvoyProxyImg,
 			},
+			DeploymentNamespaceLabels: ts.namespaceLabels,
 		},
 	}
 	return clusterClient, platformClient, g
 }
 
+// acceptGatewayClass wraps the given Get interceptor and marks fetched GatewayClasses as accepted.
+func acceptGatewayClass(get func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error) func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+		var err error
+		if get != nil {
+			err = get(ctx, c, key, obj, opts...)
+		} else {
+			err = c.Get(ctx, key, obj, opts...)
+		}
+		if gc, ok := obj.(*gatewayv1.GatewayClass); ok && err == nil {
+			apimeta.SetStatusCondition(&gc.Status.Conditions, metav1.Condition{
+				Type:   string(gatewayv1.GatewayClassConditionStatusAccepted),
+				Status: metav1.ConditionTrue,
+				Reason: string(gatewayv1.GatewayClassReasonAccepted),
+			})
+		}
+		return err
+	}
+}
+
 var (
 	testCluster = &clustersv1alpha1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -89,7 +136,8 @@ func Test_Gateway_InstallOrUpdate(t *testing.T) {
 	testCases := []struct {
 		desc string
 		testSetup
-		expectedErr error
+		expectedNamespaceLabels map[string]string
+		expectedErr             error
 	}{
 		{
 			desc: "should install without image pull secrets",
@@ -114,6 +162,70 @@ func Test_Gateway_InstallOrUpdate(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "should install with common metadata",
+			testSetup: testSetup{
+				commonMetadata: &v1alpha1.CommonMetadata{
+					Labels: map[string]string{
+						"cost-center": "1234",
+					},
+					Annotations: map[string]string{
+						"owner": "team-a",
+					},
+				},
+			},
+		},
+		{
+			desc: "should install with deployment namespace labels",
+			testSetup: testSetup{
+				namespaceLabels: map[string]string{
+					"monitoring": "true",
+				},
+			},
+		},
+		{
+			desc: "should add deployment namespace labels to existing namespace",
+			testSetup: testSetup{
+				namespaceLabels: map[string]string{
+					"monitoring": "true",
+				},
+				clusterInitObjs: []client.Object{
+					&corev1.Namespace{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: defaultDeploymentNamespace,
+							Labels: map[string]string{
+								"other": "label",
+							},
+						},
+					},
+				},
+			},
+			expectedNamespaceLabels: map[string]string{
+				"monitoring": "true",
+				"other":      "label",
+			},
+		},
+		{
+			desc: "should install into custom deployment namespace",
+			testSetup: testSetup{
+				deploymentNamespace: "custom-envoy-gateway",
+				imagePullSecrets: []corev1.LocalObjectReference{
+					{Name: "my-secret"},
+				},
+				platformInitObjs: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "my-secret",
+							Namespace: testCluster.Namespace,
+						},
+						Type: corev1.SecretTypeDockerConfigJson,
+						Data: map[string][]byte{
+							corev1.DockerConfigJsonKey: []byte(`{}`),
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -128,13 +240,35 @@ func Test_Gateway_InstallOrUpdate(t *testing.T) {
 
 			hr := g.getHelmRelease()
 			err = platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)
-			assert.NoError(t, err)
+			if assert.NoError(t, err) {
+				assert.Equal(t, defaultReleaseName, hr.Spec.ReleaseName)
+				assert.Equal(t, g.getDeploymentNamespace(), hr.Spec.TargetNamespace)
+				assert.Equal(t, g.getDeploymentNamespace(), hr.Spec.StorageNamespace)
+				if tC.commonMetadata != nil {
+					assert.Subset(t, hr.Labels, tC.commonMetadata.Labels)
+					assert.Subset(t, hr.Annotations, tC.commonMetadata.Annotations)
+				}
+			}
 
 			repo := g.getRepo()
 			err = platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)
 			if assert.NoError(t, err) {
 				assert.Equal(t, chartUrl, repo.Spec.URL)
 				assert.Equal(t, chartTag, repo.Spec.Reference.Tag)
+				if tC.commonMetadata != nil {
+					assert.Subset(t, repo.Labels, tC.commonMetadata.Labels)
+					assert.Subset(t, repo.Annotations, tC.commonMetadata.Annotations)
+				}
+			}
+
+			ns := &corev1.Namespace{}
+			err = clusterClient.Get(t.Context(), client.ObjectKey{Name: g.getDeploymentNamespace()}, ns)
+			if assert.NoError(t, err) {
+				expectedLabels := tC.expectedNamespaceLabels
+				if expectedLabels == nil {
+					expectedLabels = tC.namespaceLabels
+				}
+				assert.Equal(t, expectedLabels, ns.Labels)
 			}
 
 			if tC.imagePullSecrets != nil {
@@ -142,7 +276,7 @@ func Test_Gateway_InstallOrUpdate(t *testing.T) {
 					copied := &corev1.Secret{}
 					err := clusterClient.Get(t.Context(), client.ObjectKey{
 						Name:      ps.Name,
-						Namespace: deploymentNamespace,
+						Namespace: g.getDeploymentNamespace(),
 					}, copied)
 					assert.NoError(t, err)
 					assert.NotEmpty(t, copied.Data[corev1.DockerConfigJsonKey])
@@ -152,6 +286,196 @@ func Test_Gateway_InstallOrUpdate(t *testing.T) {
 	}
 }
 
+func Test_Gateway_generateHelmValues(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         v1alpha1.EnvoyGatewayConfig
+		expectedValues map[string]any
+	}{
+		{
+			desc: "should generate empty image map without images config",
+			expectedValues: map[string]any{
+				"global": map[string]any{
+					"images":           map[string]any{},
+					"imagePullSecrets": []corev1.LocalObjectReference(nil),
+				},
+			},
+		},
+		{
+			desc: "should propagate image pull secrets without images",
+			config: v1alpha1.EnvoyGatewayConfig{
+				Images: &v1alpha1.ImagesConfig{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "my-secret"}},
+				},
+			},
+			expectedValues: map[string]any{
+				"global": map[string]any{
+					"images":           map[string]any{},
+					"imagePullSecrets": []corev1.LocalObjectReference{{Name: "my-secret"}},
+				},
+			},
+		},
+		{
+			desc: "should merge all overrides into the values",
+			config: v1alpha1.EnvoyGatewayConfig{
+				Images: &v1alpha1.ImagesConfig{
+					EnvoyGateway:     testEnvoyGatewayImg,
+					Ratelimit:        testRatelimitImg,
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "my-secret"}},
+				},
+				RateLimit: &v1alpha1.RateLimitConfig{
+					RedisURL: "redis.example.svc:6379",
+				},
+				LogLevel:       egv1a1.LogLevelWarn,
+				ControllerName: "example.com/gatewayclass-controller",
+			},
+			expectedValues: map[string]any{
+				"global": map[string]any{
+					"images": map[string]any{
+						"envoyGateway": map[string]any{"image": testEnvoyGatewayImg},
+						"ratelimit":    map[string]any{"image": testRatelimitImg},
+					},
+					"imagePullSecrets": []corev1.LocalObjectReference{{Name: "my-secret"}},
+				},
+				"config": map[string]any{
+					"envoyGateway": map[string]any{
+						"rateLimit": map[string]any{
+							"backend": map[string]any{
+								"type": "Redis",
+								"redis": map[string]any{
+									"url": "redis.example.svc:6379",
+								},
+							},
+						},
+						"logging": map[string]any{
+							"level": map[string]any{
+								"default": egv1a1.LogLevelWarn,
+							},
+						},
+						"gateway": map[string]any{
+							"controllerName": "example.com/gatewayclass-controller",
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "should disable CRDs of the chart",
+			config: v1alpha1.EnvoyGatewayConfig{
+				InstallCRDs: ptr.To(false),
+			},
+			expectedValues: map[string]any{
+				"global": map[string]any{
+					"images":           map[string]any{},
+					"imagePullSecrets": []corev1.LocalObjectReference(nil),
+				},
+				"crds": map[string]any{
+					"gatewayAPI":   map[string]any{"enabled": false},
+					"envoyGateway": map[string]any{"enabled": false},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{EnvoyConfig: tC.config}
+			assert.Equal(t, tC.expectedValues, g.generateHelmValues())
+
+			// the JSON representation must be consistent with the generated values
+			valuesJSON, err := g.generateHelmValuesJSON()
+			if assert.NoError(t, err) {
+				expectedJSON, err := json.Marshal(tC.expectedValues)
+				if assert.NoError(t, err) {
+					assert.JSONEq(t, string(expectedJSON), string(valuesJSON.Raw))
+				}
+			}
+		})
+	}
+}
+
+func Test_Gateway_HelmValues(t *testing.T) {
+	g := &Gateway{
+		EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+			Images: &v1alpha1.ImagesConfig{
+				EnvoyGateway: testEnvoyGatewayImg,
+			},
+		},
+	}
+	values, err := g.HelmValues()
+	if assert.NoError(t, err) {
+		expected := `{
+  "global": {
+    "imagePullSecrets": null,
+    "images": {
+      "envoyGateway": {
+        "image": "` + testEnvoyGatewayImg + `"
+      }
+    }
+  }
+}`
+		assert.Equal(t, expected, string(values))
+	}
+}
+
+func Test_marshalHelmValues(t *testing.T) {
+	ch := make(chan int)
+	testCases := []struct {
+		desc          string
+		values        map[string]any
+		expectedPath  string
+		expectedValue any
+	}{
+		{
+			desc: "should marshal valid values",
+			values: map[string]any{
+				"global": map[string]any{"images": map[string]any{}},
+			},
+		},
+		{
+			desc: "should identify the path of a nested unmarshalable value",
+			values: map[string]any{
+				"global": map[string]any{"images": map[string]any{}},
+				"config": map[string]any{
+					"envoyGateway": map[string]any{
+						"logging": "info",
+						"invalid": ch,
+					},
+				},
+			},
+			expectedPath:  "config.envoyGateway.invalid",
+			expectedValue: ch,
+		},
+		{
+			desc: "should identify the path of a top-level unmarshalable value",
+			values: map[string]any{
+				"invalid": func() {},
+			},
+			expectedPath: "invalid",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			valuesJSON, err := marshalHelmValues(tC.values)
+			if tC.expectedPath == "" {
+				if assert.NoError(t, err) {
+					assert.NotEmpty(t, valuesJSON.Raw)
+				}
+				return
+			}
+
+			valuesErr := &HelmValuesError{}
+			if assert.ErrorAs(t, err, &valuesErr) {
+				assert.Equal(t, tC.expectedPath, valuesErr.Path)
+				if tC.expectedValue != nil {
+					assert.Equal(t, tC.expectedValue, valuesErr.Value)
+				}
+				assert.Contains(t, err.Error(), fmt.Sprintf("failed to marshal Helm value %q", tC.expectedPath))
+				assert.Contains(t, err.Error(), "unsupported type")
+			}
+		})
+	}
+}
+
 func Test_Gateway_generateHelmValues_images(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -216,6 +540,806 @@ func Test_Gateway_generateHelmValues_images(t *testing.T) {
 	}
 }
 
+func Test_Gateway_generateHelmValues_rateLimit(t *testing.T) {
+	g := &Gateway{}
+	values := g.generateHelmValues()
+	assert.NotContains(t, values, "config")
+
+	g.EnvoyConfig.RateLimit = &v1alpha1.RateLimitConfig{
+		RedisURL: "redis.example.svc:6379",
+	}
+	values = g.generateHelmValues()
+	assert.Equal(t, map[string]any{
+		"envoyGateway": map[string]any{
+			"rateLimit": map[string]any{
+				"backend": map[string]any{
+					"type": "Redis",
+					"redis": map[string]any{
+						"url": "redis.example.svc:6379",
+					},
+				},
+			},
+		},
+	}, values["config"])
+
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100m"),
+		},
+	}
+	g.EnvoyConfig.RateLimit.Deployment = &v1alpha1.RateLimitDeploymentConfig{
+		Replicas:  ptr.To[int32](2),
+		Resources: resources,
+	}
+	values = g.generateHelmValues()
+	envoyGateway := values["config"].(map[string]any)["envoyGateway"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"kubernetes": map[string]any{
+			"rateLimitDeployment": map[string]any{
+				"replicas": int32(2),
+				"container": map[string]any{
+					"resources": resources,
+				},
+			},
+		},
+	}, envoyGateway["provider"])
+
+	// empty deployment settings are omitted
+	g.EnvoyConfig.RateLimit.Deployment = &v1alpha1.RateLimitDeploymentConfig{}
+	values = g.generateHelmValues()
+	assert.NotContains(t, values["config"].(map[string]any)["envoyGateway"], "provider")
+}
+
+func Test_Gateway_generateHelmValues_shutdownManager(t *testing.T) {
+	g := &Gateway{}
+	g.EnvoyConfig.Images = &v1alpha1.ImagesConfig{
+		ShutdownManager: "example.com/envoyproxy/gateway:v1.5.1",
+	}
+	g.EnvoyConfig.RateLimit = &v1alpha1.RateLimitConfig{
+		RedisURL: "redis.example.svc:6379",
+		Deployment: &v1alpha1.RateLimitDeploymentConfig{
+			Replicas: ptr.To[int32](2),
+		},
+	}
+
+	// the sidecar image shares the provider settings with the rate limit deployment
+	values := g.generateHelmValues()
+	envoyGateway := values["config"].(map[string]any)["envoyGateway"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"kubernetes": map[string]any{
+			"rateLimitDeployment": map[string]any{
+				"replicas": int32(2),
+			},
+			"shutdownManager": map[string]any{
+				"image": "example.com/envoyproxy/gateway:v1.5.1",
+			},
+		},
+	}, envoyGateway["provider"])
+}
+
+func Test_Gateway_generateHelmValues_logLevel(t *testing.T) {
+	g := &Gateway{}
+	g.EnvoyConfig.LogLevel = egv1a1.LogLevelDebug
+	values := g.generateHelmValues()
+	assert.Equal(t, map[string]any{
+		"envoyGateway": map[string]any{
+			"logging": map[string]any{
+				"level": map[string]any{
+					"default": egv1a1.LogLevelDebug,
+				},
+			},
+		},
+	}, values["config"])
+}
+
+func Test_Gateway_reconcileOCIRepositoryFunc_secretRefs(t *testing.T) {
+	g := &Gateway{
+		Cluster: testCluster,
+		EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+			Chart: v1alpha1.EnvoyGatewayChart{
+				URL:           chartUrl,
+				Tag:           chartTag,
+				SecretRef:     &meta.LocalObjectReference{Name: "registry-credentials"},
+				CertSecretRef: &meta.LocalObjectReference{Name: "registry-tls"},
+			},
+		},
+	}
+
+	repo := g.getRepo()
+	err := g.reconcileOCIRepositoryFunc(repo)()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &meta.LocalObjectReference{Name: "registry-credentials"}, repo.Spec.SecretRef)
+		assert.Equal(t, &meta.LocalObjectReference{Name: "registry-tls"}, repo.Spec.CertSecretRef)
+	}
+}
+
+func Test_Gateway_InstallOrUpdate_layerMediaTypeFallback(t *testing.T) {
+	const legacyType = "application/tar+gzip"
+
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+	g.EnvoyConfig.Chart.LayerMediaTypes = []string{helmChartMediaType, legacyType}
+
+	assertMediaType := func(expected string) {
+		t.Helper()
+		repo := g.getRepo()
+		if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)) {
+			if expected == "" {
+				assert.Nil(t, repo.Spec.LayerSelector)
+			} else if assert.NotNil(t, repo.Spec.LayerSelector) {
+				assert.Equal(t, expected, repo.Spec.LayerSelector.MediaType)
+			}
+		}
+	}
+	failLayerOperation := func() {
+		t.Helper()
+		repo := g.getRepo()
+		if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)) {
+			repo.Status.Conditions = []metav1.Condition{{
+				Type:               meta.ReadyCondition,
+				Status:             metav1.ConditionFalse,
+				Reason:             sourcev1.OCILayerOperationFailedReason,
+				ObservedGeneration: repo.Generation,
+				LastTransitionTime: metav1.Now(),
+			}}
+			// the fake client has no status subresource for OCIRepositories, so the status is updated with the object
+			assert.NoError(t, platformClient.Update(t.Context(), repo))
+		}
+	}
+
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	assertMediaType(helmChartMediaType)
+
+	// without a failure, the selected media type is kept
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	assertMediaType(helmChartMediaType)
+
+	failLayerOperation()
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	assertMediaType(legacyType)
+
+	failLayerOperation()
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	assertMediaType("")
+}
+
+func Test_HasFluxResources(t *testing.T) {
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+
+	found, err := HasFluxResources(t.Context(), platformClient, testCluster)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	err = g.InstallOrUpdate(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	found, err = HasFluxResources(t.Context(), platformClient, testCluster)
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func Test_Gateway_InstallOrUpdate_release(t *testing.T) {
+	ts := testSetup{}
+	clusterClient, platformClient, g := ts.build()
+	g.EnvoyConfig.ReleaseName = "envoy-gateway"
+	g.EnvoyConfig.StorageNamespace = "helm-releases"
+
+	err := g.InstallOrUpdate(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	hr := g.getHelmRelease()
+	err = platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "envoy-gateway", hr.Spec.ReleaseName)
+		assert.Equal(t, "helm-releases", hr.Spec.StorageNamespace)
+		assert.Equal(t, defaultDeploymentNamespace, hr.Spec.TargetNamespace)
+	}
+
+	// the storage namespace must exist before the release can be stored
+	ns := &corev1.Namespace{}
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "helm-releases"}, ns)
+	assert.NoError(t, err)
+}
+
+func Test_Gateway_InstallOrUpdate_httpChart(t *testing.T) {
+	const helmRepoURL = "https://charts.example.com"
+
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+
+	// install from the OCI registry first, then switch to the HTTP Helm repository
+	err := g.InstallOrUpdate(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+	g.EnvoyConfig.Chart.Type = v1alpha1.ChartTypeHTTP
+	g.EnvoyConfig.Chart.URL = helmRepoURL
+	g.EnvoyConfig.Chart.SecretRef = &meta.LocalObjectReference{Name: "registry-credentials"}
+	err = g.InstallOrUpdate(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	helmRepo := g.getHelmRepository()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(helmRepo), helmRepo)) {
+		assert.Equal(t, helmRepoURL, helmRepo.Spec.URL)
+		assert.Equal(t, &meta.LocalObjectReference{Name: "registry-credentials"}, helmRepo.Spec.SecretRef)
+	}
+
+	hr := g.getHelmRelease()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		assert.Nil(t, hr.Spec.ChartRef)
+		if assert.NotNil(t, hr.Spec.Chart) {
+			assert.Equal(t, helmv2.HelmChartTemplateSpec{
+				Chart:   defaultChartName,
+				Version: chartTag,
+				SourceRef: helmv2.CrossNamespaceObjectReference{
+					Kind: sourcev1.HelmRepositoryKind,
+					Name: helmRepo.Name,
+				},
+			}, hr.Spec.Chart.Spec)
+		}
+	}
+
+	repo := g.getRepo()
+	err = platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)
+	assert.True(t, apierrors.IsNotFound(err), "OCIRepository should be removed after switching to the http chart type")
+}
+
+func Test_Gateway_InstallOrUpdate_suspend(t *testing.T) {
+	for _, suspend := range []bool{true, false} {
+		t.Run(fmt.Sprintf("suspend=%t", suspend), func(t *testing.T) {
+			ts := testSetup{}
+			_, platformClient, g := ts.build()
+			g.Suspend = suspend
+
+			err := g.InstallOrUpdate(t.Context())
+			assert.NoError(t, err)
+
+			repo := g.getRepo()
+			if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)) {
+				assert.Equal(t, suspend, repo.Spec.Suspend)
+			}
+			hr := g.getHelmRelease()
+			if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+				assert.Equal(t, suspend, hr.Spec.Suspend)
+			}
+		})
+	}
+}
+
+func Test_Gateway_ReleaseInProgress(t *testing.T) {
+	readyCondition := func(status metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{Type: meta.ReadyCondition, Status: status, Reason: "Test"}
+	}
+	testCases := []struct {
+		desc          string
+		release       *helmv2.HelmRelease
+		expected      bool
+		expectFailure bool
+	}{
+		{
+			desc:     "should be in progress when the release does not exist",
+			expected: true,
+		},
+		{
+			desc:     "should be in progress without ready condition",
+			release:  &helmv2.HelmRelease{},
+			expected: true,
+		},
+		{
+			desc: "should be in progress while reconciling",
+			release: &helmv2.HelmRelease{
+				Status: helmv2.HelmReleaseStatus{
+					Conditions: []metav1.Condition{
+						readyCondition(metav1.ConditionTrue),
+						{Type: meta.ReconcilingCondition, Status: metav1.ConditionTrue, Reason: "Progressing"},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should be in progress while not ready",
+			release: &helmv2.HelmRelease{
+				Status: helmv2.HelmReleaseStatus{
+					Conditions: []metav1.Condition{readyCondition(metav1.ConditionFalse)},
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should be in progress while the current generation is not observed",
+			release: &helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: helmv2.HelmReleaseStatus{
+					ObservedGeneration: 1,
+					Conditions:         []metav1.Condition{readyCondition(metav1.ConditionTrue)},
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should not be in progress when ready",
+			release: &helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: helmv2.HelmReleaseStatus{
+					ObservedGeneration: 2,
+					Conditions:         []metav1.Condition{readyCondition(metav1.ConditionTrue)},
+				},
+			},
+		},
+		{
+			desc: "should be in progress while a failed action is retried",
+			release: &helmv2.HelmRelease{
+				Status: helmv2.HelmReleaseStatus{
+					Conditions: []metav1.Condition{
+						{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: helmv2.InstallFailedReason},
+						{Type: meta.ReconcilingCondition, Status: metav1.ConditionTrue, Reason: meta.ProgressingWithRetryReason},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should be in progress while a failed action of a previous generation is reported",
+			release: &helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: helmv2.HelmReleaseStatus{
+					ObservedGeneration: 1,
+					Conditions: []metav1.Condition{
+						{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: helmv2.UpgradeFailedReason},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			desc: "should fail when stalled",
+			release: &helmv2.HelmRelease{
+				Status: helmv2.HelmReleaseStatus{
+					Conditions: []metav1.Condition{
+						readyCondition(metav1.ConditionFalse),
+						{Type: meta.StalledCondition, Status: metav1.ConditionTrue, Reason: "RetriesExceeded", Message: "retries exhausted"},
+					},
+				},
+			},
+			expectFailure: true,
+		},
+		{
+			desc: "should fail when not ready with a final reason",
+			release: &helmv2.HelmRelease{
+				Status: helmv2.HelmReleaseStatus{
+					Conditions: []metav1.Condition{
+						{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: helmv2.UpgradeFailedReason, Message: "timed out"},
+					},
+				},
+			},
+			expectFailure: true,
+		},
+		{
+			desc: "should not be in progress when suspended",
+			release: &helmv2.HelmRelease{
+				Spec: helmv2.HelmReleaseSpec{Suspend: true},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			if tC.release != nil {
+				tC.release.Name = fmt.Sprintf("%s.gateway", testCluster.Name)
+				tC.release.Namespace = testCluster.Namespace
+				ts.platformInitObjs = []client.Object{tC.release}
+			}
+			_, _, g := ts.build()
+
+			inProgress, err := g.ReleaseInProgress(t.Context())
+			if tC.expectFailure {
+				assert.ErrorIs(t, err, ErrReleaseFailed)
+				assert.False(t, inProgress)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, inProgress)
+			}
+		})
+	}
+}
+
+func Test_Gateway_InstallOrUpdate_installCRDs(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		installCRDs    *bool
+		expectedPolicy helmv2.CRDsPolicy
+	}{
+		{
+			desc:           "should create and replace CRDs by default",
+			expectedPolicy: helmv2.CreateReplace,
+		},
+		{
+			desc:           "should create and replace CRDs if enabled",
+			installCRDs:    ptr.To(true),
+			expectedPolicy: helmv2.CreateReplace,
+		},
+		{
+			desc:           "should skip CRDs if disabled",
+			installCRDs:    ptr.To(false),
+			expectedPolicy: helmv2.Skip,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			_, platformClient, g := ts.build()
+			g.EnvoyConfig.InstallCRDs = tC.installCRDs
+
+			err := g.InstallOrUpdate(t.Context())
+			assert.NoError(t, err)
+
+			hr := g.getHelmRelease()
+			if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+				assert.Equal(t, tC.expectedPolicy, hr.Spec.Install.CRDs)
+				assert.Equal(t, tC.expectedPolicy, hr.Spec.Upgrade.CRDs)
+			}
+		})
+	}
+}
+
+func Test_Gateway_createNamespacesDisabled(t *testing.T) {
+	ts := testSetup{}
+	clusterClient, platformClient, g := ts.build()
+	g.CreateNamespaces = ptr.To(false)
+
+	err := g.InstallOrUpdate(t.Context())
+	assert.ErrorIs(t, err, errNamespaceNotFound)
+	assert.ErrorIs(t, err, &utils.RetryableError{})
+	hr := g.getHelmRelease()
+	assert.True(t, apierrors.IsNotFound(platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)))
+
+	err = g.Configure(t.Context())
+	assert.ErrorIs(t, err, errNamespaceNotFound)
+
+	// once the namespaces are provided, they are used as they are
+	for _, namespace := range []string{g.getDeploymentNamespace(), g.getGatewayNamespace()} {
+		assert.NoError(t, clusterClient.Create(t.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}))
+	}
+	assert.NoError(t, g.InstallOrUpdate(t.Context()))
+	assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr))
+	assert.NoError(t, g.Configure(t.Context()))
+
+	ns := &corev1.Namespace{}
+	if assert.NoError(t, clusterClient.Get(t.Context(), client.ObjectKey{Name: g.getDeploymentNamespace()}, ns)) {
+		assert.Empty(t, ns.Annotations)
+	}
+}
+
+func Test_Gateway_InstallOrUpdate_requestedAt(t *testing.T) {
+	for _, requestedAt := range []string{"", "2026-01-02T03:04:05Z"} {
+		t.Run(fmt.Sprintf("requestedAt=%q", requestedAt), func(t *testing.T) {
+			ts := testSetup{}
+			_, platformClient, g := ts.build()
+			g.RequestedAt = requestedAt
+
+			err := g.InstallOrUpdate(t.Context())
+			assert.NoError(t, err)
+
+			objs := []client.Object{g.getRepo(), g.getHelmRelease()}
+			for _, obj := range objs {
+				if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(obj), obj)) {
+					if requestedAt == "" {
+						assert.NotContains(t, obj.GetAnnotations(), meta.ReconcileRequestAnnotation)
+					} else {
+						assert.Equal(t, requestedAt, obj.GetAnnotations()[meta.ReconcileRequestAnnotation])
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_Gateway_Uninstall_resumesSuspendedHelmRelease(t *testing.T) {
+	ts := testSetup{
+		platformInitObjs: []client.Object{
+			&helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s.gateway", testCluster.Name),
+					Namespace: testCluster.Namespace,
+					// keep the HelmRelease around after deletion, like Flux does until the release is uninstalled
+					Finalizers: []string{"finalizers.fluxcd.io"},
+				},
+				Spec: helmv2.HelmReleaseSpec{
+					Suspend: true,
+				},
+			},
+		},
+	}
+	_, platformClient, g := ts.build()
+
+	err := g.Uninstall(t.Context())
+	assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+
+	hr := g.getHelmRelease()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		assert.False(t, hr.Spec.Suspend)
+		assert.False(t, hr.DeletionTimestamp.IsZero())
+	}
+}
+
+func Test_Gateway_Uninstall_deletesHelmReleaseBeforeSource(t *testing.T) {
+	ts := testSetup{
+		platformInitObjs: []client.Object{
+			&sourcev1.OCIRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s.gateway", testCluster.Name),
+					Namespace: testCluster.Namespace,
+				},
+			},
+			&helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s.gateway", testCluster.Name),
+					Namespace: testCluster.Namespace,
+					// keep the HelmRelease around after deletion, like Flux does until the release is uninstalled
+					Finalizers: []string{"finalizers.fluxcd.io"},
+				},
+			},
+		},
+	}
+	_, platformClient, g := ts.build()
+
+	err := g.Uninstall(t.Context())
+	rr := &utils.RemainingResourcesError{}
+	if assert.ErrorAs(t, err, &rr) && assert.Len(t, rr.Objects, 1) {
+		assert.IsType(t, &helmv2.HelmRelease{}, rr.Objects[0])
+	}
+
+	// the OCIRepository is kept as long as the HelmRelease exists
+	repo := g.getRepo()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)) {
+		assert.True(t, repo.DeletionTimestamp.IsZero())
+	}
+
+	// once the HelmRelease is gone, the OCIRepository is deleted
+	hr := g.getHelmRelease()
+	if !assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		return
+	}
+	hr.Finalizers = nil
+	if !assert.NoError(t, platformClient.Update(t.Context(), hr)) {
+		return
+	}
+	err = g.Uninstall(t.Context())
+	assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+	assert.NoError(t, g.Uninstall(t.Context()))
+	assert.True(t, apierrors.IsNotFound(platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)))
+}
+
+func Test_Gateway_getDeletionOptions(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		timings              Timings
+		cleanup              *v1alpha1.CleanupConfig
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			desc:                 "should retry after 10s by default",
+			expectedRequeueAfter: 10 * time.Second,
+		},
+		{
+			desc:                 "should use the deletion retry interval of the timings",
+			timings:              Timings{DeletionRetryInterval: time.Second},
+			expectedRequeueAfter: time.Second,
+		},
+		{
+			desc:    "should prefer the retry interval of the cleanup config",
+			timings: Timings{DeletionRetryInterval: time.Second},
+			cleanup: &v1alpha1.CleanupConfig{
+				RetryInterval: &metav1.Duration{Duration: 2 * time.Minute},
+			},
+			expectedRequeueAfter: 2 * time.Minute,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{Timings: tC.timings, CleanupConfig: tC.cleanup}
+			assert.Equal(t, tC.expectedRequeueAfter, g.getDeletionOptions().requeueAfter)
+		})
+	}
+}
+
+func Test_Gateway_getLayerSelector(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *v1alpha1.LayerSelectorConfig
+		expected *sourcev1.OCILayerSelector
+	}{
+		{
+			desc: "should default to helm chart layer",
+			expected: &sourcev1.OCILayerSelector{
+				MediaType: helmChartMediaType,
+				Operation: sourcev1.OCILayerCopy,
+			},
+		},
+		{
+			desc: "should omit layer selector when disabled",
+			config: &v1alpha1.LayerSelectorConfig{
+				Disabled: true,
+			},
+		},
+		{
+			desc: "should override media type and operation",
+			config: &v1alpha1.LayerSelectorConfig{
+				MediaType: "application/vnd.example.chart.v1.tar+gzip",
+				Operation: sourcev1.OCILayerExtract,
+			},
+			expected: &sourcev1.OCILayerSelector{
+				MediaType: "application/vnd.example.chart.v1.tar+gzip",
+				Operation: sourcev1.OCILayerExtract,
+			},
+		},
+		{
+			desc: "should default operation when only media type is set",
+			config: &v1alpha1.LayerSelectorConfig{
+				MediaType: "application/vnd.example.chart.v1.tar+gzip",
+			},
+			expected: &sourcev1.OCILayerSelector{
+				MediaType: "application/vnd.example.chart.v1.tar+gzip",
+				Operation: sourcev1.OCILayerCopy,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{}
+			g.EnvoyConfig.Chart.LayerSelector = tC.config
+			assert.Equal(t, tC.expected, g.getLayerSelector(&sourcev1.OCIRepository{}))
+		})
+	}
+}
+
+func Test_Gateway_getLayerSelector_mediaTypes(t *testing.T) {
+	const (
+		url        = "oci://registry.example.com/charts/gateway-helm"
+		tag        = "1.5.4"
+		legacyType = "application/tar+gzip"
+	)
+	failed := []metav1.Condition{{
+		Type:               meta.ReadyCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             sourcev1.OCILayerOperationFailedReason,
+		ObservedGeneration: 2,
+	}}
+	existing := func(mediaType string, conditions []metav1.Condition) *sourcev1.OCIRepository {
+		obj := &sourcev1.OCIRepository{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec: sourcev1.OCIRepositorySpec{
+				URL:       url,
+				Reference: &sourcev1.OCIRepositoryRef{Tag: tag},
+			},
+			Status: sourcev1.OCIRepositoryStatus{Conditions: conditions},
+		}
+		if mediaType != "" {
+			obj.Spec.LayerSelector = &sourcev1.OCILayerSelector{MediaType: mediaType, Operation: sourcev1.OCILayerCopy}
+		}
+		return obj
+	}
+	testCases := []struct {
+		desc              string
+		obj               *sourcev1.OCIRepository
+		tag               string
+		expectedMediaType string
+	}{
+		{
+			desc:              "should select the first media type for a new repository",
+			obj:               &sourcev1.OCIRepository{},
+			expectedMediaType: helmChartMediaType,
+		},
+		{
+			desc:              "should keep the selected media type",
+			obj:               existing(legacyType, nil),
+			expectedMediaType: legacyType,
+		},
+		{
+			desc:              "should select the next media type after the layer was not found",
+			obj:               existing(helmChartMediaType, failed),
+			expectedMediaType: legacyType,
+		},
+		{
+			desc: "should keep the media type while the failure refers to a previous generation",
+			obj: existing(helmChartMediaType, []metav1.Condition{{
+				Type:               meta.ReadyCondition,
+				Status:             metav1.ConditionFalse,
+				Reason:             sourcev1.OCILayerOperationFailedReason,
+				ObservedGeneration: 1,
+			}}),
+			expectedMediaType: helmChartMediaType,
+		},
+		{
+			desc: "should omit the selector after the last media type",
+			obj:  existing(legacyType, failed),
+		},
+		{
+			desc: "should keep the selector omitted",
+			obj:  existing("", failed),
+		},
+		{
+			desc:              "should start over when the tag changes",
+			obj:               existing("", failed),
+			tag:               "1.6.0",
+			expectedMediaType: helmChartMediaType,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{}
+			g.EnvoyConfig.Chart.URL = url
+			g.EnvoyConfig.Chart.Tag = cmp.Or(tC.tag, tag)
+			g.EnvoyConfig.Chart.LayerMediaTypes = []string{helmChartMediaType, legacyType}
+			selector := g.getLayerSelector(tC.obj)
+			if tC.expectedMediaType == "" {
+				assert.Nil(t, selector)
+				return
+			}
+			if assert.NotNil(t, selector) {
+				assert.Equal(t, tC.expectedMediaType, selector.MediaType)
+				assert.Equal(t, sourcev1.OCILayerCopy, selector.Operation)
+			}
+		})
+	}
+}
+
+func Test_Gateway_getDriftDetection(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *v1alpha1.DriftDetectionConfig
+		expected *helmv2.DriftDetection
+	}{
+		{
+			desc: "should omit drift detection by default",
+		},
+		{
+			desc:   "should default mode to disabled",
+			config: &v1alpha1.DriftDetectionConfig{},
+			expected: &helmv2.DriftDetection{
+				Mode: helmv2.DriftDetectionDisabled,
+			},
+		},
+		{
+			desc: "should set mode and ignore paths",
+			config: &v1alpha1.DriftDetectionConfig{
+				Mode:        "enabled",
+				IgnorePaths: []string{"/spec/replicas"},
+			},
+			expected: &helmv2.DriftDetection{
+				Mode: helmv2.DriftDetectionEnabled,
+				Ignore: []helmv2.IgnoreRule{
+					{Paths: []string{"/spec/replicas"}},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{}
+			g.EnvoyConfig.Chart.DriftDetection = tC.config
+			assert.Equal(t, tC.expected, g.getDriftDetection())
+		})
+	}
+}
+
 func Test_Gateway_Uninstall(t *testing.T) {
 	testCases := []struct {
 		desc string
@@ -228,7 +1352,7 @@ func Test_Gateway_Uninstall(t *testing.T) {
 		},
 		{
 			desc:    "should uninstall when objects are present",
-			retries: 1,
+			retries: 2,
 			testSetup: testSetup{
 				platformInitObjs: []client.Object{
 					&sourcev1.OCIRepository{
@@ -276,3 +1400,188 @@ func Test_Gateway_Uninstall(t *testing.T) {
 		})
 	}
 }
+
+func Test_Gateway_Cleanup_drainTimeout(t *testing.T) {
+	newHelmRelease := func(drainStartedAt string) *helmv2.HelmRelease {
+		hr := &helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s.gateway", testCluster.Name),
+				Namespace: testCluster.Namespace,
+			},
+		}
+		if drainStartedAt != "" {
+			metav1.SetMetaDataAnnotation(&hr.ObjectMeta, drainStartedAtAnnotation, drainStartedAt)
+		}
+		return hr
+	}
+
+	testCases := []struct {
+		desc          string
+		helmRelease   *helmv2.HelmRelease
+		expectRetry   bool
+		expectGateway bool
+	}{
+		{
+			desc:          "should start drain window when cleaning up",
+			helmRelease:   newHelmRelease(""),
+			expectRetry:   true,
+			expectGateway: true,
+		},
+		{
+			desc:          "should wait while drain window has not elapsed",
+			helmRelease:   newHelmRelease(time.Now().UTC().Format(time.RFC3339)),
+			expectRetry:   true,
+			expectGateway: true,
+		},
+		{
+			desc:        "should delete the gateway when drain window has elapsed",
+			helmRelease: newHelmRelease(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)),
+		},
+		{
+			desc: "should delete the gateway when HelmRelease is already gone",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			if tC.helmRelease != nil {
+				ts.platformInitObjs = []client.Object{tC.helmRelease}
+			}
+			clusterClient, platformClient, g := ts.build()
+			if !assert.NoError(t, g.Configure(t.Context())) {
+				return
+			}
+			g.CleanupConfig = &v1alpha1.CleanupConfig{
+				DrainTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			}
+
+			err := g.Cleanup(t.Context())
+			if tC.expectRetry {
+				assert.ErrorIs(t, err, ErrDrainInProgress)
+				assert.ErrorIs(t, err, &utils.RetryableError{})
+				assert.False(t, utils.IsRemainingResourcesError(err))
+			} else if err != nil {
+				// deletion of the remaining objects is still pending
+				assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+			}
+
+			// the listeners are kept while draining
+			gateway := g.getGateway()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(gateway), gateway)
+			if !tC.expectGateway {
+				assert.True(t, apierrors.IsNotFound(err), "Gateway still exists")
+				return
+			}
+			assert.NoError(t, err)
+			hr := g.getHelmRelease()
+			if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+				assert.NotEmpty(t, hr.Annotations[drainStartedAtAnnotation])
+			}
+		})
+	}
+}
+
+func Test_Gateway_Uninstall_ignoresDrainTimeout(t *testing.T) {
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	// the drain window has been waited for by Cleanup already
+	g.CleanupConfig = &v1alpha1.CleanupConfig{
+		DrainTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	err := g.Uninstall(t.Context())
+	assert.NotErrorIs(t, err, ErrDrainInProgress)
+	hr := g.getHelmRelease()
+	assert.True(t, apierrors.IsNotFound(platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)), "HelmRelease still exists")
+}
+
+func Test_Gateway_InstallOrUpdate_resetsDrainWindow(t *testing.T) {
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+
+	// simulate an uninstallation which was aborted long ago
+	hr := g.getHelmRelease()
+	if !assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		return
+	}
+	metav1.SetMetaDataAnnotation(&hr.ObjectMeta, drainStartedAtAnnotation, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	if !assert.NoError(t, platformClient.Update(t.Context(), hr)) {
+		return
+	}
+
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	hr = g.getHelmRelease()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		assert.NotContains(t, hr.Annotations, drainStartedAtAnnotation)
+	}
+
+	// a new uninstallation has to wait for the full drain window
+	g.CleanupConfig = &v1alpha1.CleanupConfig{
+		DrainTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	assert.ErrorIs(t, g.Cleanup(t.Context()), ErrDrainInProgress)
+}
+
+func Test_Gateway_proxyServiceAccount(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		clusterInitObjs []client.Object
+		expectedDeleted bool
+	}{
+		{
+			desc:            "should create and delete the service account",
+			expectedDeleted: true,
+		},
+		{
+			desc: "should adopt, but not delete an existing service account",
+			clusterInitObjs: []client.Object{
+				&corev1.ServiceAccount{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "envoy-proxy",
+						Namespace: defaultDeploymentNamespace,
+					},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{clusterInitObjs: tC.clusterInitObjs}
+			clusterClient, _, g := ts.build()
+			g.EnvoyConfig.Proxy = &v1alpha1.ProxyConfig{
+				ServiceAccount: &v1alpha1.ProxyServiceAccountConfig{
+					Name:        "envoy-proxy",
+					Annotations: map[string]string{"iam.gke.io/gcp-service-account": "envoy@example.iam.gserviceaccount.com"},
+				},
+			}
+
+			assert.NoError(t, g.InstallOrUpdate(t.Context()))
+			sa := g.getProxyServiceAccount()
+			if assert.NoError(t, clusterClient.Get(t.Context(), client.ObjectKeyFromObject(sa), sa)) {
+				assert.Equal(t, "envoy@example.iam.gserviceaccount.com", sa.Annotations["iam.gke.io/gcp-service-account"])
+			}
+
+			envoyProxy := g.getEnvoyProxy()
+			assert.NoError(t, g.reconcileEnvoyProxyFunc(envoyProxy)())
+			assert.Equal(t, &egv1a1.KubernetesServiceAccountSpec{Name: ptr.To("envoy-proxy")}, envoyProxy.Spec.Provider.Kubernetes.EnvoyServiceAccount)
+
+			err := g.Cleanup(t.Context())
+			for i := 0; i < 3 && err != nil; i++ {
+				assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+				err = g.Cleanup(t.Context())
+			}
+			assert.NoError(t, err)
+
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(sa), &corev1.ServiceAccount{})
+			assert.Equal(t, tC.expectedDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package envoy
+
+import (
+	"context"
+	"maps"
+
+	"github.com/openmcp-project/controller-utils/pkg/logging"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+const (
+	envoyGatewayMonitorName = "envoy-gateway"
+	envoyProxyMonitorName   = "envoy-proxy"
+	metricsPortName         = "metrics"
+)
+
+var (
+	serviceMonitorGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "ServiceMonitor",
+	}
+	podMonitorGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PodMonitor",
+	}
+)
+
+// reconcileMonitoring creates or deletes the prometheus-operator resources which are used to scrape
+// the Envoy Gateway controller and the Envoy Proxy data plane.
+// The Envoy Proxy service only exposes the listener ports, therefore the data plane is scraped using a PodMonitor.
+// If the prometheus-operator CRDs are not installed in the cluster, the resources are skipped.
+func (g *Gateway) reconcileMonitoring(ctx context.Context) error {
+	log := logging.FromContextOrDiscard(ctx)
+
+	if !g.monitoringEnabled() {
+		return deleteObjects(ctx, g.ClusterClient, g.getMonitors()...)
+	}
+
+	serviceMonitor := g.getServiceMonitor()
+	podMonitor := g.getPodMonitor()
+	err := createOrUpdate(ctx, g.ClusterClient,
+		applyOperation{
+			obj: serviceMonitor,
+			f:   g.reconcileServiceMonitorFunc(serviceMonitor),
+		},
+		applyOperation{
+			obj: podMonitor,
+			f:   g.reconcilePodMonitorFunc(podMonitor),
+		},
+	)
+	if utils.IsCRDNotFoundError(err) {
+		log.Info("Skipping monitoring resources because the prometheus-operator CRDs are not installed")
+		return nil
+	}
+	return err
+}
+
+func (g *Gateway) monitoringEnabled() bool {
+	m := g.EnvoyConfig.Monitoring
+	return m != nil && m.ServiceMonitor != nil && m.ServiceMonitor.Enabled
+}
+
+func (g *Gateway) getMonitors() []client.Object {
+	return []client.Object{
+		g.getServiceMonitor(),
+		g.getPodMonitor(),
+	}
+}
+
+func newUnstructured(gvk schema.GroupVersionKind, name, namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+// ----- ServiceMonitor -----
+
+func (g *Gateway) getServiceMonitor() *unstructured.Unstructured {
+	return newUnstructured(serviceMonitorGVK, envoyGatewayMonitorName, g.getGatewayNamespace())
+}
+
+func (g *Gateway) reconcileServiceMonitorFunc(obj *unstructured.Unstructured) func() error {
+	return func() error {
+		g.applyMonitorMetadata(obj)
+		return unstructured.SetNestedMap(obj.Object, map[string]any{
+			"namespaceSelector": map[string]any{
+				"matchNames": []any{g.getDeploymentNamespace()},
+			},
+			"selector": map[string]any{
+				"matchLabels": map[string]any{
+					"control-plane": "envoy-gateway",
+				},
+			},
+			"endpoints": []any{
+				map[string]any{
+					"port": metricsPortName,
+					"path": "/metrics",
+				},
+			},
+		}, "spec")
+	}
+}
+
+// ----- PodMonitor -----
+
+func (g *Gateway) getPodMonitor() *unstructured.Unstructured {
+	return newUnstructured(podMonitorGVK, envoyProxyMonitorName, g.getGatewayNamespace())
+}
+
+func (g *Gateway) reconcilePodMonitorFunc(obj *unstructured.Unstructured) func() error {
+	return func() error {
+		g.applyMonitorMetadata(obj)
+		return unstructured.SetNestedMap(obj.Object, map[string]any{
+			"namespaceSelector": map[string]any{
+				"matchNames": []any{g.getDeploymentNamespace()},
+			},
+			"selector": map[string]any{
+				"matchLabels": map[string]any{
+					"app.kubernetes.io/component":  "proxy",
+					"app.kubernetes.io/managed-by": "envoy-gateway",
+				},
+			},
+			"podMetricsEndpoints": []any{
+				map[string]any{
+					"port": metricsPortName,
+					"path": "/stats/prometheus",
+				},
+			},
+		}, "spec")
+	}
+}
+
+func (g *Gateway) applyMonitorMetadata(obj client.Object) {
+	g.applyCommonMetadata(obj)
+	if monitorLabels := g.EnvoyConfig.Monitoring.ServiceMonitor.Labels; len(monitorLabels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, monitorLabels)
+		obj.SetLabels(labels)
+	}
+}
@@ -0,0 +1,104 @@
+package envoy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+func Test_Gateway_reconcileMonitoring(t *testing.T) {
+	monitoringCRDNotFound := interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if u, ok := obj.(*unstructured.Unstructured); ok && u.GroupVersionKind().Group == serviceMonitorGVK.Group {
+				return &meta.NoKindMatchError{GroupKind: u.GroupVersionKind().GroupKind()}
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}
+
+	testCases := []struct {
+		desc string
+		testSetup
+		monitoring       *v1alpha1.MonitoringConfig
+		existingMonitors bool
+		expectMonitors   bool
+	}{
+		{
+			desc: "should not create monitors when monitoring is not configured",
+		},
+		{
+			desc: "should not create monitors when disabled",
+			monitoring: &v1alpha1.MonitoringConfig{
+				ServiceMonitor: &v1alpha1.ServiceMonitorConfig{
+					Enabled: false,
+				},
+			},
+		},
+		{
+			desc: "should create monitors when enabled and CRDs are present",
+			monitoring: &v1alpha1.MonitoringConfig{
+				ServiceMonitor: &v1alpha1.ServiceMonitorConfig{
+					Enabled: true,
+					Labels: map[string]string{
+						"release": "prometheus",
+					},
+				},
+			},
+			expectMonitors: true,
+		},
+		{
+			desc: "should skip monitors when enabled but CRDs are missing",
+			testSetup: testSetup{
+				clusterInterceptorFuncs: monitoringCRDNotFound,
+			},
+			monitoring: &v1alpha1.MonitoringConfig{
+				ServiceMonitor: &v1alpha1.ServiceMonitorConfig{
+					Enabled: true,
+				},
+			},
+		},
+		{
+			desc: "should delete existing monitors when disabled",
+			monitoring: &v1alpha1.MonitoringConfig{
+				ServiceMonitor: &v1alpha1.ServiceMonitorConfig{
+					Enabled: false,
+				},
+			},
+			existingMonitors: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.existingMonitors {
+				tC.clusterInitObjs = append(tC.clusterInitObjs, (&Gateway{}).getMonitors()...)
+			}
+			clusterClient, _, g := tC.build()
+			g.EnvoyConfig.Monitoring = tC.monitoring
+
+			err := g.reconcileMonitoring(t.Context())
+			assert.NoError(t, err)
+
+			for _, obj := range g.getMonitors() {
+				err := clusterClient.Get(t.Context(), client.ObjectKeyFromObject(obj), obj)
+				if !tC.expectMonitors {
+					assert.True(t, apierrors.IsNotFound(err) || meta.IsNoMatchError(err), "monitor %s should not exist", obj.GetName())
+					continue
+				}
+				if assert.NoError(t, err) {
+					u := obj.(*unstructured.Unstructured)
+					assert.Equal(t, "prometheus", u.GetLabels()["release"])
+					matchNames, _, _ := unstructured.NestedStringSlice(u.Object, "spec", "namespaceSelector", "matchNames")
+					assert.Equal(t, []string{defaultDeploymentNamespace}, matchNames)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package envoy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+// CollectOrphanedFluxResources deletes the Flux resources of gateways whose Cluster no longer exists on the platform cluster,
+// e.g. because the Cluster was force-deleted without running the finalizer. Only resources linked to a Cluster are considered.
+// As in Uninstall, the chart sources are only deleted once the HelmRelease is gone, so the function has to be called repeatedly.
+// It returns the Clusters whose resources are being deleted, sorted by their identity.
+func CollectOrphanedFluxResources(ctx context.Context, platformClient client.Client) ([]types.NamespacedName, error) {
+	clusterKeys := map[types.NamespacedName]bool{}
+	for _, list := range []client.ObjectList{&helmv2.HelmReleaseList{}, &sourcev1.OCIRepositoryList{}, &sourcev1.HelmRepositoryList{}} {
+		if err := platformClient.List(ctx, list, client.MatchingLabels{managedByLabel: managedByLabelValue}); err != nil {
+			return nil, fmt.Errorf("failed to list Flux resources: %w", err)
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+			if name := clusterNameOf(accessor); name != "" {
+				clusterKeys[types.NamespacedName{Namespace: accessor.GetNamespace(), Name: name}] = true
+			}
+		}
+	}
+
+	orphans := []types.NamespacedName{}
+	for key := range clusterKeys {
+		err := platformClient.Get(ctx, key, &clustersv1alpha1.Cluster{})
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get Cluster '%s': %w", key, err)
+		}
+		orphans = append(orphans, key)
+	}
+	slices.SortFunc(orphans, func(a, b types.NamespacedName) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, key := range orphans {
+		g := &Gateway{
+			Cluster:        &clustersv1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}},
+			PlatformClient: platformClient,
+		}
+		if err := g.deleteOrphanedFluxResources(ctx); err != nil {
+			return nil, fmt.Errorf("failed to delete Flux resources of Cluster '%s': %w", key, err)
+		}
+	}
+	return orphans, nil
+}
+
+// clusterNameOf returns the name of the Cluster the given Flux resource belongs to, or an empty string if it isn't linked to a Cluster.
+// Resources created by older versions of this controller only carry the label, which contained the full name.
+func clusterNameOf(obj metav1.Object) string {
+	if name := obj.GetAnnotations()[v1alpha1.ClusterNameAnnotation]; name != "" {
+		return name
+	}
+	return obj.GetLabels()[v1alpha1.ClusterNameLabel]
+}
+
+// deleteOrphanedFluxResources deletes the HelmRelease of the Gateway and, once it is gone, the chart sources.
+func (g *Gateway) deleteOrphanedFluxResources(ctx context.Context) error {
+	helmRelease := g.getHelmRelease()
+	if err := g.resumeHelmRelease(ctx, helmRelease); err != nil {
+		return err
+	}
+	if err := deleteObjects(ctx, g.PlatformClient, helmRelease); err != nil {
+		return err
+	}
+	if err := g.PlatformClient.Get(ctx, client.ObjectKeyFromObject(helmRelease), helmRelease); !apierrors.IsNotFound(err) {
+		// the HelmRelease is still being deleted or cannot be checked
+		return client.IgnoreNotFound(err)
+	}
+	return deleteObjects(ctx, g.PlatformClient, g.getRepo(), g.getHelmRepository())
+}
@@ -0,0 +1,119 @@
+package envoy
+
+import (
+	"strings"
+	"testing"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+func Test_CollectOrphanedFluxResources(t *testing.T) {
+	existingCluster := &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "existing",
+			Namespace: testCluster.Namespace,
+		},
+	}
+	ts := testSetup{platformInitObjs: []client.Object{existingCluster}}
+	_, platformClient, g := ts.build()
+	// testCluster doesn't exist on the platform cluster, like a force-deleted Cluster
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	existing := &Gateway{Cluster: existingCluster, PlatformClient: platformClient, ClusterClient: g.ClusterClient}
+	if !assert.NoError(t, existing.InstallOrUpdate(t.Context())) {
+		return
+	}
+
+	hr := g.getHelmRelease()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		assert.Equal(t, testCluster.Name, hr.Labels[v1alpha1.ClusterNameLabel])
+		assert.Equal(t, testCluster.Namespace, hr.Labels[v1alpha1.ClusterNamespaceLabel])
+		assert.Equal(t, testCluster.Name, hr.Annotations[v1alpha1.ClusterNameAnnotation])
+		assert.Equal(t, testCluster.Namespace, hr.Annotations[v1alpha1.ClusterNamespaceAnnotation])
+		assert.Equal(t, managedByLabelValue, hr.Labels[managedByLabel])
+		// simulate Flux uninstalling the release
+		controllerutil.AddFinalizer(hr, "finalizers.fluxcd.io")
+		assert.NoError(t, platformClient.Update(t.Context(), hr))
+	}
+
+	orphans, err := CollectOrphanedFluxResources(t.Context(), platformClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.NamespacedName{client.ObjectKeyFromObject(testCluster)}, orphans)
+
+	// the source is kept until the HelmRelease is gone
+	repo := g.getRepo()
+	assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo))
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		assert.False(t, hr.DeletionTimestamp.IsZero())
+		controllerutil.RemoveFinalizer(hr, "finalizers.fluxcd.io")
+		assert.NoError(t, platformClient.Update(t.Context(), hr))
+	}
+
+	_, err = CollectOrphanedFluxResources(t.Context(), platformClient)
+	assert.NoError(t, err)
+	assert.True(t, apierrors.IsNotFound(platformClient.Get(t.Context(), client.ObjectKeyFromObject(repo), repo)))
+
+	// the resources of existing clusters are kept
+	found, err := HasFluxResources(t.Context(), platformClient, existingCluster)
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	orphans, err = CollectOrphanedFluxResources(t.Context(), platformClient)
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+}
+
+func Test_CollectOrphanedFluxResources_longClusterName(t *testing.T) {
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+	g.Cluster = &clustersv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.Repeat("a", 100),
+			Namespace: testCluster.Namespace,
+		},
+	}
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+
+	hr := g.getHelmRelease()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		assert.Empty(t, validation.IsValidLabelValue(hr.Labels[v1alpha1.ClusterNameLabel]))
+		assert.Equal(t, v1alpha1.ClusterNameLabelValue(g.Cluster.Name), hr.Labels[v1alpha1.ClusterNameLabel])
+		assert.Equal(t, g.Cluster.Name, hr.Annotations[v1alpha1.ClusterNameAnnotation])
+	}
+
+	orphans, err := CollectOrphanedFluxResources(t.Context(), platformClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.NamespacedName{client.ObjectKeyFromObject(g.Cluster)}, orphans)
+	assert.True(t, apierrors.IsNotFound(platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)))
+}
+
+func Test_CollectOrphanedFluxResources_labelOnly(t *testing.T) {
+	ts := testSetup{}
+	_, platformClient, g := ts.build()
+	if !assert.NoError(t, g.InstallOrUpdate(t.Context())) {
+		return
+	}
+	// resources of older versions only carry the label
+	hr := g.getHelmRelease()
+	if assert.NoError(t, platformClient.Get(t.Context(), client.ObjectKeyFromObject(hr), hr)) {
+		delete(hr.Annotations, v1alpha1.ClusterNameAnnotation)
+		delete(hr.Annotations, v1alpha1.ClusterNamespaceAnnotation)
+		assert.NoError(t, platformClient.Update(t.Context(), hr))
+	}
+
+	orphans, err := CollectOrphanedFluxResources(t.Context(), platformClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.NamespacedName{client.ObjectKeyFromObject(testCluster)}, orphans)
+}
@@ -0,0 +1,433 @@
+package envoy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+const oidcClientSecretName = gatewayName + "-oidc-client-secret"
+
+var (
+	envoyPatchPolicyGVK = schema.GroupVersionKind{
+		Group:   egv1a1.GroupName,
+		Version: egv1a1.GroupVersion.Version,
+		Kind:    egv1a1.KindEnvoyPatchPolicy,
+	}
+	securityPolicyGVK = schema.GroupVersionKind{
+		Group:   egv1a1.GroupName,
+		Version: egv1a1.GroupVersion.Version,
+		Kind:    egv1a1.KindSecurityPolicy,
+	}
+)
+
+// reconcilePolicies creates or updates the configured Envoy Gateway policies which are attached to the gateway.
+// Policies which are not configured (anymore) are deleted.
+func (g *Gateway) reconcilePolicies(ctx context.Context) error {
+	ops := []applyOperation{}
+	obsolete := []client.Object{}
+
+	btp := g.getBackendTrafficPolicy()
+	if g.backendTrafficPolicyEnabled() {
+		ops = append(ops, applyOperation{
+			obj: btp,
+			f:   g.reconcileBackendTrafficPolicyFunc(btp),
+		})
+	} else {
+		obsolete = append(obsolete, btp)
+	}
+
+	ctp := g.getClientTrafficPolicy()
+	if g.clientTrafficPolicyEnabled() {
+		ops = append(ops, applyOperation{
+			obj: ctp,
+			f:   g.reconcileClientTrafficPolicyFunc(ctp),
+		})
+	} else {
+		obsolete = append(obsolete, ctp)
+	}
+
+	sp := g.getSecurityPolicy()
+	if g.securityPolicyEnabled() {
+		ops = append(ops, applyOperation{
+			obj: sp,
+			f:   g.reconcileSecurityPolicyFunc(sp),
+		})
+	} else {
+		obsolete = append(obsolete, sp)
+	}
+
+	oidcSecret := g.getOIDCClientSecret()
+	if g.oidcEnabled() {
+		// the client secret must exist before the SecurityPolicy references it
+		ops = slices.Insert(ops, 0, applyOperation{
+			obj: oidcSecret,
+			f:   g.reconcileSecretFromFunc(ctx, g.GatewayConfig.SecurityPolicy.OIDC.ClientSecretRef.Name, oidcSecret),
+		})
+	} else {
+		obsolete = append(obsolete, oidcSecret)
+	}
+
+	epp := g.getEnvoyPatchPolicy()
+	if g.envoyPatchPolicyEnabled() {
+		ops = append(ops, applyOperation{
+			obj: epp,
+			f:   g.reconcileEnvoyPatchPolicyFunc(epp),
+		})
+	} else {
+		obsolete = append(obsolete, epp)
+	}
+
+	if err := createOrUpdate(ctx, g.ClusterClient, ops...); err != nil {
+		return err
+	}
+	return deleteObjects(ctx, g.ClusterClient, obsolete...)
+}
+
+func (g *Gateway) getPolicies() []client.Object {
+	return []client.Object{
+		g.getBackendTrafficPolicy(),
+		g.getClientTrafficPolicy(),
+		g.getSecurityPolicy(),
+		g.getOIDCClientSecret(),
+		g.getEnvoyPatchPolicy(),
+	}
+}
+
+// getGatewayTargetRefs returns the target references which attach a policy to the gateway.
+func getGatewayTargetRefs() []gatewayv1.LocalPolicyTargetReferenceWithSectionName {
+	return []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+		{
+			LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+				Group: gatewayv1.GroupName,
+				Kind:  "Gateway",
+				Name:  gatewayName,
+			},
+		},
+	}
+}
+
+// ----- BackendTrafficPolicy -----
+
+func (g *Gateway) getBackendTrafficPolicy() *egv1a1.BackendTrafficPolicy {
+	return &egv1a1.BackendTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayName,
+			Namespace: g.getGatewayNamespace(),
+		},
+	}
+}
+
+// backendTrafficPolicyEnabled returns true if the BackendTrafficPolicy is configured or rate limiting is enabled.
+// Both share the same BackendTrafficPolicy, because Envoy Gateway only applies one BackendTrafficPolicy per target.
+func (g *Gateway) backendTrafficPolicyEnabled() bool {
+	return (g.GatewayConfig != nil && g.GatewayConfig.BackendTrafficPolicy != nil) || g.EnvoyConfig.RateLimit != nil
+}
+
+func (g *Gateway) reconcileBackendTrafficPolicyFunc(obj *egv1a1.BackendTrafficPolicy) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+		obj.Spec.TargetRefs = getGatewayTargetRefs()
+		obj.Spec.RateLimit = g.getRateLimitSpec()
+
+		cfg := &v1alpha1.BackendTrafficPolicyConfig{}
+		if g.GatewayConfig != nil && g.GatewayConfig.BackendTrafficPolicy != nil {
+			cfg = g.GatewayConfig.BackendTrafficPolicy
+		}
+		obj.Spec.Timeout = nil
+		if cfg.ConnectTimeout != nil {
+			obj.Spec.Timeout = &egv1a1.Timeout{
+				TCP: &egv1a1.TCPTimeout{
+					ConnectTimeout: ptr.To(formatDuration(cfg.ConnectTimeout.Duration)),
+				},
+			}
+		}
+
+		obj.Spec.CircuitBreaker = nil
+		if cfg.MaxConnections != nil {
+			obj.Spec.CircuitBreaker = &egv1a1.CircuitBreaker{
+				MaxConnections: cfg.MaxConnections,
+			}
+		}
+
+		obj.Spec.HealthCheck = nil
+		if cfg.HealthCheck != nil {
+			obj.Spec.HealthCheck = &egv1a1.HealthCheck{
+				Active: &egv1a1.ActiveHealthCheck{
+					Type: egv1a1.ActiveHealthCheckerTypeHTTP,
+					HTTP: &egv1a1.HTTPActiveHealthChecker{
+						Path: cfg.HealthCheck.Path,
+					},
+				},
+			}
+		}
+		return nil
+	}
+}
+
+func (g *Gateway) getRateLimitSpec() *egv1a1.RateLimitSpec {
+	if g.EnvoyConfig.RateLimit == nil {
+		return nil
+	}
+
+	rules := make([]egv1a1.RateLimitRule, len(g.EnvoyConfig.RateLimit.Rules))
+	for i, rule := range g.EnvoyConfig.RateLimit.Rules {
+		rules[i] = egv1a1.RateLimitRule{
+			Limit: egv1a1.RateLimitValue{
+				Requests: rule.Requests,
+				Unit:     rule.Unit,
+			},
+		}
+		if len(rule.Headers) == 0 && rule.SourceCIDR == "" {
+			// rule applies to all requests
+			continue
+		}
+
+		selector := egv1a1.RateLimitSelectCondition{}
+		for _, header := range rule.Headers {
+			match := egv1a1.HeaderMatch{
+				Name:  header.Name,
+				Type:  ptr.To(egv1a1.HeaderMatchDistinct),
+				Value: header.Value,
+			}
+			if header.Value != nil {
+				match.Type = ptr.To(egv1a1.HeaderMatchExact)
+			}
+			selector.Headers = append(selector.Headers, match)
+		}
+		if rule.SourceCIDR != "" {
+			selector.SourceCIDR = &egv1a1.SourceMatch{
+				Type:  ptr.To(egv1a1.SourceMatchDistinct),
+				Value: rule.SourceCIDR,
+			}
+		}
+		rules[i].ClientSelectors = []egv1a1.RateLimitSelectCondition{selector}
+	}
+
+	return &egv1a1.RateLimitSpec{
+		Type: ptr.To(egv1a1.GlobalRateLimitType),
+		Global: &egv1a1.GlobalRateLimit{
+			Rules: rules,
+		},
+	}
+}
+
+// ----- ClientTrafficPolicy -----
+
+func (g *Gateway) getClientTrafficPolicy() *egv1a1.ClientTrafficPolicy {
+	return &egv1a1.ClientTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayName,
+			Namespace: g.getGatewayNamespace(),
+		},
+	}
+}
+
+func (g *Gateway) clientTrafficPolicyEnabled() bool {
+	return g.GatewayConfig != nil && g.GatewayConfig.ClientTrafficPolicy != nil
+}
+
+func (g *Gateway) reconcileClientTrafficPolicyFunc(obj *egv1a1.ClientTrafficPolicy) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+		obj.Spec.TargetRefs = getGatewayTargetRefs()
+
+		cfg := g.GatewayConfig.ClientTrafficPolicy
+		obj.Spec.TLS = nil
+		if cfg.TLSMinVersion != nil {
+			obj.Spec.TLS = &egv1a1.ClientTLSSettings{
+				TLSSettings: egv1a1.TLSSettings{
+					MinVersion: cfg.TLSMinVersion,
+				},
+			}
+		}
+
+		obj.Spec.TCPKeepalive = nil
+		if ka := cfg.TCPKeepalive; ka != nil {
+			obj.Spec.TCPKeepalive = &egv1a1.TCPKeepalive{
+				Probes: ka.Probes,
+			}
+			if ka.IdleTime != nil {
+				obj.Spec.TCPKeepalive.IdleTime = ptr.To(formatDuration(ka.IdleTime.Duration))
+			}
+			if ka.Interval != nil {
+				obj.Spec.TCPKeepalive.Interval = ptr.To(formatDuration(ka.Interval.Duration))
+			}
+		}
+
+		obj.Spec.Connection = nil
+		if conn := cfg.Connection; conn != nil {
+			obj.Spec.Connection = &egv1a1.ClientConnection{
+				BufferLimit: conn.BufferLimit,
+			}
+			if conn.ConnectionLimit != nil {
+				obj.Spec.Connection.ConnectionLimit = &egv1a1.ConnectionLimit{
+					Value: conn.ConnectionLimit,
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// getGatewayTargetRefsUnstructured returns the target references which attach an unstructured policy to the gateway.
+func getGatewayTargetRefsUnstructured() []any {
+	refs := getGatewayTargetRefs()
+	targetRefs := make([]any, 0, len(refs))
+	for _, ref := range refs {
+		targetRefs = append(targetRefs, map[string]any{
+			"group": string(ref.Group),
+			"kind":  string(ref.Kind),
+			"name":  string(ref.Name),
+		})
+	}
+	return targetRefs
+}
+
+// ----- SecurityPolicy -----
+
+func (g *Gateway) getSecurityPolicy() *unstructured.Unstructured {
+	return newUnstructured(securityPolicyGVK, gatewayName, g.getGatewayNamespace())
+}
+
+func (g *Gateway) securityPolicyEnabled() bool {
+	return g.GatewayConfig != nil && g.GatewayConfig.SecurityPolicy != nil
+}
+
+func (g *Gateway) reconcileSecurityPolicyFunc(obj *unstructured.Unstructured) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+
+		cfg := g.GatewayConfig.SecurityPolicy
+		spec := map[string]any{
+			"targetRefs": getGatewayTargetRefsUnstructured(),
+		}
+		if cfg.JWT != nil {
+			provider := map[string]any{
+				"name":   "default",
+				"issuer": cfg.JWT.Issuer,
+				"remoteJWKS": map[string]any{
+					"uri": cfg.JWT.JWKSURI,
+				},
+			}
+			if len(cfg.JWT.Audiences) > 0 {
+				provider["audiences"] = toAnySlice(cfg.JWT.Audiences)
+			}
+			spec["jwt"] = map[string]any{
+				"providers": []any{provider},
+			}
+		}
+		if cfg.OIDC != nil {
+			oidc := map[string]any{
+				"provider": map[string]any{
+					"issuer": cfg.OIDC.Issuer,
+				},
+				"clientID": cfg.OIDC.ClientID,
+				"clientSecret": map[string]any{
+					"name": oidcClientSecretName,
+				},
+			}
+			if cfg.OIDC.RedirectURL != "" {
+				oidc["redirectURL"] = cfg.OIDC.RedirectURL
+			}
+			if len(cfg.OIDC.Scopes) > 0 {
+				oidc["scopes"] = toAnySlice(cfg.OIDC.Scopes)
+			}
+			spec["oidc"] = oidc
+		}
+		if cfg.CORS != nil {
+			cors := map[string]any{}
+			origins := make([]any, 0, len(cfg.CORS.AllowOrigins))
+			for _, origin := range cfg.CORS.AllowOrigins {
+				origins = append(origins, string(origin))
+			}
+			cors["allowOrigins"] = origins
+			if len(cfg.CORS.AllowMethods) > 0 {
+				cors["allowMethods"] = toAnySlice(cfg.CORS.AllowMethods)
+			}
+			if len(cfg.CORS.AllowHeaders) > 0 {
+				cors["allowHeaders"] = toAnySlice(cfg.CORS.AllowHeaders)
+			}
+			if cfg.CORS.MaxAge != nil {
+				cors["maxAge"] = string(formatDuration(cfg.CORS.MaxAge.Duration))
+			}
+			spec["cors"] = cors
+		}
+		return unstructured.SetNestedMap(obj.Object, spec, "spec")
+	}
+}
+
+// toAnySlice converts the given strings into a slice which can be used in unstructured objects.
+func toAnySlice(values []string) []any {
+	result := make([]any, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+	}
+	return result
+}
+
+func (g *Gateway) oidcEnabled() bool {
+	return g.securityPolicyEnabled() && g.GatewayConfig.SecurityPolicy.OIDC != nil
+}
+
+// getOIDCClientSecret returns the copy of the OIDC client secret in the gateway namespace.
+// It has a fixed name, so that it can be cleaned up after the OIDC configuration has been removed.
+func (g *Gateway) getOIDCClientSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      oidcClientSecretName,
+			Namespace: g.getGatewayNamespace(),
+		},
+	}
+}
+
+// ----- EnvoyPatchPolicy -----
+
+// getEnvoyPatchPolicy returns the EnvoyPatchPolicy as unstructured object,
+// so that the configured JSON patches are passed through without being decoded into typed patches.
+func (g *Gateway) getEnvoyPatchPolicy() *unstructured.Unstructured {
+	return newUnstructured(envoyPatchPolicyGVK, gatewayName, g.getGatewayNamespace())
+}
+
+// envoyPatchPolicyEnabled returns true if the EnvoyPatchPolicy API is explicitly enabled.
+// The same flag enables the API in the Envoy Gateway deployment.
+func (g *Gateway) envoyPatchPolicyEnabled() bool {
+	return g.GatewayConfig != nil && g.GatewayConfig.EnvoyPatchPolicy != nil && g.GatewayConfig.EnvoyPatchPolicy.Enabled
+}
+
+func (g *Gateway) reconcileEnvoyPatchPolicyFunc(obj *unstructured.Unstructured) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+
+		patches := make([]any, 0, len(g.GatewayConfig.EnvoyPatchPolicy.EnvoyPatches))
+		for i, patch := range g.GatewayConfig.EnvoyPatchPolicy.EnvoyPatches {
+			var p map[string]any
+			if err := json.Unmarshal(patch.Raw, &p); err != nil {
+				return fmt.Errorf("failed to decode envoy patch %d: %w", i, err)
+			}
+			patches = append(patches, p)
+		}
+
+		return unstructured.SetNestedMap(obj.Object, map[string]any{
+			"targetRef": map[string]any{
+				"group": gatewayv1.GroupName,
+				"kind":  "Gateway",
+				"name":  gatewayName,
+			},
+			"type":        string(egv1a1.JSONPatchEnvoyPatchType),
+			"jsonPatches": patches,
+		}, "spec")
+	}
+}
@@ -0,0 +1,485 @@
+package envoy
+
+import (
+	"testing"
+	"time"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+func Test_Gateway_reconcilePolicies_backendTrafficPolicy(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		config       *v1alpha1.BackendTrafficPolicyConfig
+		existing     bool
+		expectedSpec *egv1a1.BackendTrafficPolicySpec
+	}{
+		{
+			desc: "should not create policy when not configured",
+		},
+		{
+			desc:     "should delete policy when not configured anymore",
+			existing: true,
+		},
+		{
+			desc: "should render configured settings",
+			config: &v1alpha1.BackendTrafficPolicyConfig{
+				ConnectTimeout: &metav1.Duration{Duration: 5 * time.Second},
+				MaxConnections: ptr.To[int64](1024),
+				HealthCheck: &v1alpha1.HealthCheckConfig{
+					Path: "/healthz",
+				},
+			},
+			expectedSpec: &egv1a1.BackendTrafficPolicySpec{
+				PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+					TargetRefs: getGatewayTargetRefs(),
+				},
+				ClusterSettings: egv1a1.ClusterSettings{
+					Timeout: &egv1a1.Timeout{
+						TCP: &egv1a1.TCPTimeout{
+							ConnectTimeout: ptr.To(gatewayv1.Duration("5s")),
+						},
+					},
+					CircuitBreaker: &egv1a1.CircuitBreaker{
+						MaxConnections: ptr.To[int64](1024),
+					},
+					HealthCheck: &egv1a1.HealthCheck{
+						Active: &egv1a1.ActiveHealthCheck{
+							Type: egv1a1.ActiveHealthCheckerTypeHTTP,
+							HTTP: &egv1a1.HTTPActiveHealthChecker{
+								Path: "/healthz",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:     "should remove settings which are not configured anymore",
+			existing: true,
+			config: &v1alpha1.BackendTrafficPolicyConfig{
+				MaxConnections: ptr.To[int64](10),
+			},
+			expectedSpec: &egv1a1.BackendTrafficPolicySpec{
+				PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+					TargetRefs: getGatewayTargetRefs(),
+				},
+				ClusterSettings: egv1a1.ClusterSettings{
+					CircuitBreaker: &egv1a1.CircuitBreaker{
+						MaxConnections: ptr.To[int64](10),
+					},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			if tC.existing {
+				ts.clusterInitObjs = []client.Object{
+					&egv1a1.BackendTrafficPolicy{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      gatewayName,
+							Namespace: defaultGatewayNamespace,
+						},
+						Spec: egv1a1.BackendTrafficPolicySpec{
+							ClusterSettings: egv1a1.ClusterSettings{
+								Timeout: &egv1a1.Timeout{
+									TCP: &egv1a1.TCPTimeout{
+										ConnectTimeout: ptr.To(gatewayv1.Duration("1s")),
+									},
+								},
+							},
+						},
+					},
+				}
+			}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = &v1alpha1.GatewayConfig{
+				BackendTrafficPolicy: tC.config,
+			}
+
+			err := g.reconcilePolicies(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			btp := g.getBackendTrafficPolicy()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(btp), btp)
+			if tC.expectedSpec == nil {
+				assert.True(t, apierrors.IsNotFound(err), "BackendTrafficPolicy should not exist")
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, *tC.expectedSpec, btp.Spec)
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcilePolicies_clientTrafficPolicy(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		config       *v1alpha1.ClientTrafficPolicyConfig
+		expectedSpec *egv1a1.ClientTrafficPolicySpec
+	}{
+		{
+			desc: "should not create policy when not configured",
+		},
+		{
+			desc: "should render configured settings",
+			config: &v1alpha1.ClientTrafficPolicyConfig{
+				TLSMinVersion: ptr.To(egv1a1.TLSv12),
+				TCPKeepalive: &v1alpha1.TCPKeepaliveConfig{
+					Probes:   ptr.To[uint32](3),
+					IdleTime: &metav1.Duration{Duration: time.Minute},
+					Interval: &metav1.Duration{Duration: 10 * time.Second},
+				},
+				Connection: &v1alpha1.ClientConnectionConfig{
+					BufferLimit:     ptr.To(resource.MustParse("32Ki")),
+					ConnectionLimit: ptr.To[int64](1000),
+				},
+			},
+			expectedSpec: &egv1a1.ClientTrafficPolicySpec{
+				PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+					TargetRefs: getGatewayTargetRefs(),
+				},
+				TLS: &egv1a1.ClientTLSSettings{
+					TLSSettings: egv1a1.TLSSettings{
+						MinVersion: ptr.To(egv1a1.TLSv12),
+					},
+				},
+				TCPKeepalive: &egv1a1.TCPKeepalive{
+					Probes:   ptr.To[uint32](3),
+					IdleTime: ptr.To(gatewayv1.Duration("1m")),
+					Interval: ptr.To(gatewayv1.Duration("10s")),
+				},
+				Connection: &egv1a1.ClientConnection{
+					BufferLimit: ptr.To(resource.MustParse("32Ki")),
+					ConnectionLimit: &egv1a1.ConnectionLimit{
+						Value: ptr.To[int64](1000),
+					},
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = &v1alpha1.GatewayConfig{
+				ClientTrafficPolicy: tC.config,
+			}
+
+			err := g.reconcilePolicies(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			ctp := g.getClientTrafficPolicy()
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(ctp), ctp)
+			if tC.expectedSpec == nil {
+				assert.True(t, apierrors.IsNotFound(err), "ClientTrafficPolicy should not exist")
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedSpec.TargetRefs, ctp.Spec.TargetRefs)
+				assert.Equal(t, tC.expectedSpec.TLS, ctp.Spec.TLS)
+				assert.Equal(t, tC.expectedSpec.TCPKeepalive, ctp.Spec.TCPKeepalive)
+				if assert.NotNil(t, ctp.Spec.Connection) {
+					assert.True(t, tC.expectedSpec.Connection.BufferLimit.Equal(*ctp.Spec.Connection.BufferLimit))
+					assert.Equal(t, tC.expectedSpec.Connection.ConnectionLimit, ctp.Spec.Connection.ConnectionLimit)
+				}
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcilePolicies_securityPolicy(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		config       *v1alpha1.SecurityPolicyConfig
+		expectedSpec *egv1a1.SecurityPolicySpec
+	}{
+		{
+			desc: "should not create policy when not configured",
+		},
+		{
+			desc: "should render JWT authentication",
+			config: &v1alpha1.SecurityPolicyConfig{
+				JWT: &v1alpha1.JWTConfig{
+					Issuer:    "https://issuer.example.com",
+					JWKSURI:   "https://issuer.example.com/jwks.json",
+					Audiences: []string{"gateway"},
+				},
+			},
+			expectedSpec: &egv1a1.SecurityPolicySpec{
+				PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+					TargetRefs: getGatewayTargetRefs(),
+				},
+				JWT: &egv1a1.JWT{
+					Providers: []egv1a1.JWTProvider{
+						{
+							Name:      "default",
+							Issuer:    "https://issuer.example.com",
+							Audiences: []string{"gateway"},
+							RemoteJWKS: &egv1a1.RemoteJWKS{
+								URI: "https://issuer.example.com/jwks.json",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "should render CORS settings",
+			config: &v1alpha1.SecurityPolicyConfig{
+				CORS: &v1alpha1.CORSConfig{
+					AllowOrigins: []egv1a1.Origin{"https://*.example.com"},
+					AllowMethods: []string{"GET", "POST"},
+					AllowHeaders: []string{"x-user-id"},
+					MaxAge:       &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+			expectedSpec: &egv1a1.SecurityPolicySpec{
+				PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+					TargetRefs: getGatewayTargetRefs(),
+				},
+				CORS: &egv1a1.CORS{
+					AllowOrigins: []egv1a1.Origin{"https://*.example.com"},
+					AllowMethods: []string{"GET", "POST"},
+					AllowHeaders: []string{"x-user-id"},
+					MaxAge:       ptr.To(gatewayv1.Duration("10m")),
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = &v1alpha1.GatewayConfig{
+				SecurityPolicy: tC.config,
+			}
+
+			err := g.reconcilePolicies(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			sp := &egv1a1.SecurityPolicy{}
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(g.getSecurityPolicy()), sp)
+			if tC.expectedSpec == nil {
+				assert.True(t, apierrors.IsNotFound(err), "SecurityPolicy should not exist")
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expectedSpec.TargetRefs, sp.Spec.TargetRefs)
+				assert.Equal(t, tC.expectedSpec.JWT, sp.Spec.JWT)
+				assert.Equal(t, tC.expectedSpec.CORS, sp.Spec.CORS)
+			}
+
+			// the SecurityPolicy is removed once it is no longer configured
+			g.GatewayConfig.SecurityPolicy = nil
+			err = g.reconcilePolicies(t.Context())
+			if assert.NoError(t, err) {
+				err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(g.getSecurityPolicy()), sp)
+				assert.True(t, apierrors.IsNotFound(err), "SecurityPolicy should have been deleted")
+			}
+		})
+	}
+}
+
+func Test_Gateway_reconcilePolicies_securityPolicyOIDC(t *testing.T) {
+	ts := testSetup{
+		platformInitObjs: []client.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "oidc-client",
+					Namespace: testCluster.Namespace,
+				},
+				Data: map[string][]byte{
+					"client-secret": []byte("s3cr3t"),
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+	g.GatewayConfig = &v1alpha1.GatewayConfig{
+		SecurityPolicy: &v1alpha1.SecurityPolicyConfig{
+			OIDC: &v1alpha1.OIDCConfig{
+				Issuer:          "https://issuer.example.com",
+				ClientID:        "dashboard",
+				ClientSecretRef: corev1.LocalObjectReference{Name: "oidc-client"},
+				RedirectURL:     "https://dashboard.example.com/oauth2/callback",
+				Scopes:          []string{"email"},
+			},
+		},
+	}
+
+	err := g.reconcilePolicies(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sp := &egv1a1.SecurityPolicy{}
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(g.getSecurityPolicy()), sp)
+	if assert.NoError(t, err) && assert.NotNil(t, sp.Spec.OIDC) {
+		assert.Equal(t, "https://issuer.example.com", sp.Spec.OIDC.Provider.Issuer)
+		assert.Equal(t, ptr.To("dashboard"), sp.Spec.OIDC.ClientID)
+		assert.EqualValues(t, oidcClientSecretName, sp.Spec.OIDC.ClientSecret.Name)
+		assert.Equal(t, ptr.To("https://dashboard.example.com/oauth2/callback"), sp.Spec.OIDC.RedirectURL)
+		assert.Equal(t, []string{"email"}, sp.Spec.OIDC.Scopes)
+	}
+
+	// the client secret is copied instead of being inlined into the SecurityPolicy
+	secret := g.getOIDCClientSecret()
+	err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(secret), secret)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte("s3cr3t"), secret.Data["client-secret"])
+	}
+
+	err = g.Cleanup(t.Context())
+	assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+	for _, obj := range []client.Object{g.getSecurityPolicy(), g.getOIDCClientSecret()} {
+		err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(obj), obj)
+		assert.True(t, apierrors.IsNotFound(err), "%T should have been deleted", obj)
+	}
+}
+
+func Test_Gateway_reconcilePolicies_envoyPatchPolicy(t *testing.T) {
+	patch := `{"type":"type.googleapis.com/envoy.config.listener.v3.Listener","name":"default/default/tls","operation":{"op":"add","path":"/per_connection_buffer_limit_bytes","value":32768}}`
+	testCases := []struct {
+		desc           string
+		config         *v1alpha1.EnvoyPatchPolicyConfig
+		expectedPolicy bool
+	}{
+		{
+			desc: "should not create policy when not configured",
+		},
+		{
+			desc: "should not create policy when not enabled",
+			config: &v1alpha1.EnvoyPatchPolicyConfig{
+				EnvoyPatches: []apiextensionsv1.JSON{{Raw: []byte(patch)}},
+			},
+		},
+		{
+			desc: "should pass through patches when enabled",
+			config: &v1alpha1.EnvoyPatchPolicyConfig{
+				Enabled:      true,
+				EnvoyPatches: []apiextensionsv1.JSON{{Raw: []byte(patch)}},
+			},
+			expectedPolicy: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ts := testSetup{}
+			clusterClient, _, g := ts.build()
+			g.GatewayConfig = &v1alpha1.GatewayConfig{
+				EnvoyPatchPolicy: tC.config,
+			}
+
+			err := g.reconcilePolicies(t.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			epp := &egv1a1.EnvoyPatchPolicy{}
+			err = clusterClient.Get(t.Context(), client.ObjectKeyFromObject(g.getEnvoyPatchPolicy()), epp)
+			if !tC.expectedPolicy {
+				assert.True(t, apierrors.IsNotFound(err), "EnvoyPatchPolicy should not exist")
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, egv1a1.JSONPatchEnvoyPatchType, epp.Spec.Type)
+				assert.EqualValues(t, gatewayName, epp.Spec.TargetRef.Name)
+				assert.EqualValues(t, "Gateway", epp.Spec.TargetRef.Kind)
+				if assert.Len(t, epp.Spec.JSONPatches, 1) {
+					assert.Equal(t, "default/default/tls", epp.Spec.JSONPatches[0].Name)
+					assert.Equal(t, ptr.To("/per_connection_buffer_limit_bytes"), epp.Spec.JSONPatches[0].Operation.Path)
+				}
+			}
+
+			// the EnvoyPatchPolicy API must be enabled in the Envoy Gateway deployment
+			assert.Equal(t, map[string]any{
+				"envoyGateway": map[string]any{
+					"extensionApis": map[string]any{
+						"enableEnvoyPatchPolicy": true,
+					},
+				},
+			}, g.generateHelmValues()["config"])
+		})
+	}
+}
+
+func Test_Gateway_getRateLimitSpec(t *testing.T) {
+	g := &Gateway{
+		EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+			RateLimit: &v1alpha1.RateLimitConfig{
+				RedisURL: "redis.example.svc:6379",
+				Rules: []v1alpha1.RateLimitRule{
+					{
+						Requests: 100,
+						Unit:     egv1a1.RateLimitUnitSecond,
+					},
+					{
+						Headers: []v1alpha1.RateLimitHeaderMatch{
+							{Name: "x-user-id"},
+							{Name: "x-tier", Value: ptr.To("free")},
+						},
+						SourceCIDR: "0.0.0.0/0",
+						Requests:   10,
+						Unit:       egv1a1.RateLimitUnitMinute,
+					},
+				},
+			},
+		},
+	}
+
+	expected := &egv1a1.RateLimitSpec{
+		Type: ptr.To(egv1a1.GlobalRateLimitType),
+		Global: &egv1a1.GlobalRateLimit{
+			Rules: []egv1a1.RateLimitRule{
+				{
+					Limit: egv1a1.RateLimitValue{
+						Requests: 100,
+						Unit:     egv1a1.RateLimitUnitSecond,
+					},
+				},
+				{
+					ClientSelectors: []egv1a1.RateLimitSelectCondition{
+						{
+							Headers: []egv1a1.HeaderMatch{
+								{Name: "x-user-id", Type: ptr.To(egv1a1.HeaderMatchDistinct)},
+								{Name: "x-tier", Type: ptr.To(egv1a1.HeaderMatchExact), Value: ptr.To("free")},
+							},
+							SourceCIDR: &egv1a1.SourceMatch{
+								Type:  ptr.To(egv1a1.SourceMatchDistinct),
+								Value: "0.0.0.0/0",
+							},
+						},
+					},
+					Limit: egv1a1.RateLimitValue{
+						Requests: 10,
+						Unit:     egv1a1.RateLimitUnitMinute,
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, g.getRateLimitSpec())
+	assert.True(t, g.backendTrafficPolicyEnabled(), "rate limiting requires a BackendTrafficPolicy")
+}
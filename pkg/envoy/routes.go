@@ -0,0 +1,196 @@
+package envoy
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+const (
+	managedByLabel      = "gateway.openmcp.cloud/managed-by"
+	managedByLabelValue = "platform-service-gateway"
+)
+
+// reconcileRoutes creates or updates a TLSRoute for each configured route.
+// If a route references a backend in another namespace, a ReferenceGrant is created in the namespace of the backend.
+// Managed TLSRoutes and ReferenceGrants which are no longer configured are deleted.
+func (g *Gateway) reconcileRoutes(ctx context.Context) error {
+	desired := map[string]bool{}
+	ops := []applyOperation{}
+	for _, route := range g.getRouteConfigs() {
+		obj := g.getTLSRoute(route)
+		desired[utils.ObjectIdentifier(obj)] = true
+		ops = append(ops, applyOperation{
+			obj: obj,
+			f:   g.reconcileTLSRouteFunc(obj, route),
+		})
+
+		if g.getRouteBackendNamespace(route) == g.getRouteNamespace(route) {
+			continue
+		}
+		grant := g.getReferenceGrant(route)
+		desired[utils.ObjectIdentifier(grant)] = true
+		ops = append(ops, applyOperation{
+			obj: grant,
+			f:   g.reconcileReferenceGrantFunc(grant, route),
+		})
+	}
+
+	// routes are independent of each other, so all failures are reported at once
+	if err := createOrUpdateAll(ctx, g.ClusterClient, ops...); err != nil {
+		return err
+	}
+
+	existing, err := g.listManagedRouteObjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, obj := range existing {
+		if desired[utils.ObjectIdentifier(obj)] {
+			continue
+		}
+		if err := g.ClusterClient.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listManagedRouteObjects returns all TLSRoutes and ReferenceGrants on the target cluster which are managed by this controller.
+// Kinds whose CRD is not installed are skipped.
+func (g *Gateway) listManagedRouteObjects(ctx context.Context) ([]client.Object, error) {
+	objs := []client.Object{}
+	for _, list := range []client.ObjectList{&gatewayv1.TLSRouteList{}, &gatewayv1beta1.ReferenceGrantList{}} {
+		err := g.ClusterClient.List(ctx, list, client.MatchingLabels{managedByLabel: managedByLabelValue})
+		if utils.IsCRDNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if obj, ok := item.(client.Object); ok {
+				objs = append(objs, obj)
+			}
+		}
+	}
+	return objs, nil
+}
+
+func (g *Gateway) getRouteConfigs() []v1alpha1.RouteConfig {
+	if g.GatewayConfig == nil {
+		return nil
+	}
+	return g.GatewayConfig.Routes
+}
+
+func (g *Gateway) getRouteNamespace(route v1alpha1.RouteConfig) string {
+	if route.Namespace != "" {
+		return route.Namespace
+	}
+	return g.getGatewayNamespace()
+}
+
+func (g *Gateway) getRouteBackendNamespace(route v1alpha1.RouteConfig) string {
+	if route.BackendRef.Namespace != "" {
+		return route.BackendRef.Namespace
+	}
+	return g.getRouteNamespace(route)
+}
+
+// ----- TLSRoute -----
+
+func (g *Gateway) getTLSRoute(route v1alpha1.RouteConfig) *gatewayv1.TLSRoute {
+	return &gatewayv1.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      route.Name,
+			Namespace: g.getRouteNamespace(route),
+		},
+	}
+}
+
+func (g *Gateway) reconcileTLSRouteFunc(obj *gatewayv1.TLSRoute, route v1alpha1.RouteConfig) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+		metav1.SetMetaDataLabel(&obj.ObjectMeta, managedByLabel, managedByLabelValue)
+
+		obj.Spec.ParentRefs = []gatewayv1.ParentReference{
+			{
+				Name:      gatewayName,
+				Namespace: ptr.To(gatewayv1.Namespace(g.getGatewayNamespace())),
+			},
+		}
+		baseDomains, err := g.generateBaseDomains()
+		if err != nil {
+			return err
+		}
+		obj.Spec.Hostnames = nil
+		for _, baseDomain := range baseDomains {
+			obj.Spec.Hostnames = append(obj.Spec.Hostnames, gatewayv1.Hostname(fmt.Sprintf("%s.%s", route.Subdomain, baseDomain)))
+		}
+		obj.Spec.Rules = []gatewayv1.TLSRouteRule{
+			{
+				BackendRefs: []gatewayv1.BackendRef{
+					{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Name:      gatewayv1.ObjectName(route.BackendRef.Name),
+							Namespace: ptr.To(gatewayv1.Namespace(g.getRouteBackendNamespace(route))),
+							Port:      ptr.To(route.BackendRef.Port),
+						},
+					},
+				},
+			},
+		}
+		return nil
+	}
+}
+
+// ----- ReferenceGrant -----
+
+// getReferenceGrant returns the ReferenceGrant which allows the TLSRoute of the given route to reference its backend.
+// The ReferenceGrant is named after the namespace and name of the route to avoid conflicts between routes from different namespaces.
+func (g *Gateway) getReferenceGrant(route v1alpha1.RouteConfig) *gatewayv1beta1.ReferenceGrant {
+	return &gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%s", g.getRouteNamespace(route), route.Name),
+			Namespace: g.getRouteBackendNamespace(route),
+		},
+	}
+}
+
+func (g *Gateway) reconcileReferenceGrantFunc(obj *gatewayv1beta1.ReferenceGrant, route v1alpha1.RouteConfig) func() error {
+	return func() error {
+		g.applyCommonMetadata(obj)
+		metav1.SetMetaDataLabel(&obj.ObjectMeta, managedByLabel, managedByLabelValue)
+
+		obj.Spec.From = []gatewayv1beta1.ReferenceGrantFrom{
+			{
+				Group:     gatewayv1.GroupName,
+				Kind:      "TLSRoute",
+				Namespace: gatewayv1.Namespace(g.getRouteNamespace(route)),
+			},
+		}
+		obj.Spec.To = []gatewayv1beta1.ReferenceGrantTo{
+			{
+				Group: "",
+				Kind:  "Service",
+				Name:  ptr.To(gatewayv1.ObjectName(route.BackendRef.Name)),
+			},
+		}
+		return nil
+	}
+}
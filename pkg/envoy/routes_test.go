@@ -0,0 +1,188 @@
+package envoy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+func Test_Gateway_reconcileRoutes(t *testing.T) {
+	ts := testSetup{
+		clusterInitObjs: []client.Object{
+			&gatewayv1.TLSRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "outdated",
+					Namespace: defaultGatewayNamespace,
+					Labels: map[string]string{
+						managedByLabel: managedByLabelValue,
+					},
+				},
+			},
+			&gatewayv1.TLSRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unmanaged",
+					Namespace: defaultGatewayNamespace,
+				},
+			},
+			&gatewayv1beta1.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "outdated",
+					Namespace: "kube-system",
+					Labels: map[string]string{
+						managedByLabel: managedByLabelValue,
+					},
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+	g.DNSConfig.BaseDomain = "example.com"
+	g.GatewayConfig = &v1alpha1.GatewayConfig{
+		Routes: []v1alpha1.RouteConfig{
+			{
+				Name:      "api",
+				Subdomain: "api",
+				BackendRef: v1alpha1.RouteBackendRef{
+					Name:      "kube-apiserver",
+					Namespace: "kube-system",
+					Port:      443,
+				},
+			},
+			{
+				Name:      "same-namespace",
+				Subdomain: "local",
+				BackendRef: v1alpha1.RouteBackendRef{
+					Name: "local",
+					Port: 8443,
+				},
+			},
+		},
+	}
+
+	err := g.reconcileRoutes(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	route := &gatewayv1.TLSRoute{}
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "api", Namespace: defaultGatewayNamespace}, route)
+	if assert.NoError(t, err) {
+		assert.Equal(t, managedByLabelValue, route.Labels[managedByLabel])
+		assert.Equal(t, []gatewayv1.ParentReference{
+			{
+				Name:      gatewayName,
+				Namespace: ptr.To(gatewayv1.Namespace(defaultGatewayNamespace)),
+			},
+		}, route.Spec.ParentRefs)
+		assert.Equal(t, []gatewayv1.Hostname{gatewayv1.Hostname(fmt.Sprintf("api.%s.%s.example.com", g.Cluster.Name, g.Cluster.Namespace))}, route.Spec.Hostnames)
+		if assert.Len(t, route.Spec.Rules, 1) && assert.Len(t, route.Spec.Rules[0].BackendRefs, 1) {
+			ref := route.Spec.Rules[0].BackendRefs[0]
+			assert.EqualValues(t, "kube-apiserver", ref.Name)
+			assert.Equal(t, ptr.To(gatewayv1.Namespace("kube-system")), ref.Namespace)
+			assert.Equal(t, ptr.To[gatewayv1.PortNumber](443), ref.Port)
+		}
+	}
+
+	grant := &gatewayv1beta1.ReferenceGrant{}
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: defaultGatewayNamespace + ".api", Namespace: "kube-system"}, grant)
+	if assert.NoError(t, err) {
+		assert.Equal(t, managedByLabelValue, grant.Labels[managedByLabel])
+		assert.Equal(t, []gatewayv1beta1.ReferenceGrantFrom{
+			{
+				Group:     gatewayv1.GroupName,
+				Kind:      "TLSRoute",
+				Namespace: defaultGatewayNamespace,
+			},
+		}, grant.Spec.From)
+		assert.Equal(t, []gatewayv1beta1.ReferenceGrantTo{
+			{
+				Kind: "Service",
+				Name: ptr.To(gatewayv1.ObjectName("kube-apiserver")),
+			},
+		}, grant.Spec.To)
+	}
+
+	grants := &gatewayv1beta1.ReferenceGrantList{}
+	err = clusterClient.List(t.Context(), grants)
+	if assert.NoError(t, err) {
+		// no grant is required for backends in the namespace of the route, the outdated grant is deleted
+		assert.Len(t, grants.Items, 1)
+	}
+
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "outdated", Namespace: defaultGatewayNamespace}, &gatewayv1.TLSRoute{})
+	assert.True(t, apierrors.IsNotFound(err), "outdated route still exists")
+
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "unmanaged", Namespace: defaultGatewayNamespace}, &gatewayv1.TLSRoute{})
+	assert.NoError(t, err, "unmanaged route has been deleted")
+}
+
+func Test_Gateway_reconcileRoutes_additionalBaseDomains(t *testing.T) {
+	ts := testSetup{}
+	clusterClient, _, g := ts.build()
+	g.DNSConfig = v1alpha1.DNSConfig{
+		BaseDomain:            "example.com",
+		AdditionalBaseDomains: []string{"legacy.example.com"},
+	}
+	g.GatewayConfig = &v1alpha1.GatewayConfig{
+		Routes: []v1alpha1.RouteConfig{
+			{
+				Name:      "api",
+				Subdomain: "api",
+				BackendRef: v1alpha1.RouteBackendRef{
+					Name: "kube-apiserver",
+					Port: 443,
+				},
+			},
+		},
+	}
+
+	err := g.reconcileRoutes(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	route := &gatewayv1.TLSRoute{}
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "api", Namespace: defaultGatewayNamespace}, route)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []gatewayv1.Hostname{
+			gatewayv1.Hostname(fmt.Sprintf("api.%s.%s.example.com", g.Cluster.Name, g.Cluster.Namespace)),
+			gatewayv1.Hostname(fmt.Sprintf("api.%s.%s.legacy.example.com", g.Cluster.Name, g.Cluster.Namespace)),
+		}, route.Spec.Hostnames)
+	}
+}
+
+func Test_Gateway_Cleanup_routes(t *testing.T) {
+	ts := testSetup{
+		clusterInitObjs: []client.Object{
+			&gatewayv1.TLSRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "api",
+					Namespace: "default",
+					Labels: map[string]string{
+						managedByLabel: managedByLabelValue,
+					},
+				},
+			},
+		},
+	}
+	clusterClient, _, g := ts.build()
+
+	err := g.Cleanup(t.Context())
+	assert.ErrorIs(t, err, &utils.RemainingResourcesError{})
+
+	err = g.Cleanup(t.Context())
+	assert.NoError(t, err)
+
+	err = clusterClient.Get(t.Context(), client.ObjectKey{Name: "api", Namespace: "default"}, &gatewayv1.TLSRoute{})
+	assert.True(t, apierrors.IsNotFound(err), "route still exists")
+}
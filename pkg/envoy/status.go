@@ -0,0 +1,92 @@
+package envoy
+
+import (
+	"context"
+	"fmt"
+
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/openmcp-project/platform-service-gateway/pkg/utils"
+)
+
+// Status describes the health of the gateway on a cluster.
+type Status struct {
+	// Installed is true if the Helm release of Envoy Gateway is ready.
+	Installed bool
+	// Configured is true if the GatewayClass is accepted and the EnvoyProxy and the Gateway exist.
+	Configured bool
+	// Ready is true if the gateway is installed, configured and programmed.
+	Ready bool
+	// Reasons explain why the gateway is not installed, configured or ready.
+	Reasons []string
+}
+
+// Status checks the HelmRelease on the platform cluster and the GatewayClass, EnvoyProxy and Gateway on the target cluster.
+// Missing or unready resources are reported as reasons, only unexpected errors are returned.
+func (g *Gateway) Status(ctx context.Context) (*Status, error) {
+	status := &Status{}
+
+	hr := g.getHelmRelease()
+	found, err := status.get(ctx, g.PlatformClient, hr)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		status.Installed = status.conditionTrue(hr, hr.Status.Conditions, fluxmeta.ReadyCondition)
+	}
+
+	gatewayClass := getGatewayClass()
+	found, err = status.get(ctx, g.ClusterClient, gatewayClass)
+	if err != nil {
+		return nil, err
+	}
+	accepted := found && status.conditionTrue(gatewayClass, gatewayClass.Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted))
+
+	envoyProxyFound, err := status.get(ctx, g.ClusterClient, g.getEnvoyProxy())
+	if err != nil {
+		return nil, err
+	}
+
+	gateway := g.getGateway()
+	gatewayFound, err := status.get(ctx, g.ClusterClient, gateway)
+	if err != nil {
+		return nil, err
+	}
+	programmed := gatewayFound && status.conditionTrue(gateway, gateway.Status.Conditions, string(gatewayv1.GatewayConditionProgrammed))
+
+	status.Configured = accepted && envoyProxyFound && gatewayFound
+	status.Ready = status.Installed && status.Configured && programmed
+	return status, nil
+}
+
+// get fetches the given object. A missing object or CRD is added as reason, other errors are returned.
+func (s *Status) get(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	id := utils.ObjectIdentifier(obj)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if apierrors.IsNotFound(err) || utils.IsCRDNotFoundError(err) {
+			s.Reasons = append(s.Reasons, fmt.Sprintf("%s not found", id))
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// conditionTrue checks whether the given condition of the object is true and adds a reason otherwise.
+func (s *Status) conditionTrue(obj client.Object, conditions []metav1.Condition, conditionType string) bool {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%s has no %s condition", utils.ObjectIdentifier(obj), conditionType))
+		return false
+	}
+	if condition.Status != metav1.ConditionTrue {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%s is not %s: %s", utils.ObjectIdentifier(obj), conditionType, condition.Message))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package envoy
+
+import (
+	"fmt"
+	"testing"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_Gateway_Status(t *testing.T) {
+	helmRelease := func(ready metav1.ConditionStatus) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s.gateway", testCluster.Name),
+				Namespace: testCluster.Namespace,
+			},
+			Status: helmv2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{Type: meta.ReadyCondition, Status: ready, Reason: "Test", Message: "install in progress"},
+				},
+			},
+		}
+	}
+	gateway := func(programmed metav1.ConditionStatus) *gatewayv1.Gateway {
+		return &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      gatewayName,
+				Namespace: defaultGatewayNamespace,
+			},
+			Status: gatewayv1.GatewayStatus{
+				Conditions: []metav1.Condition{
+					{Type: string(gatewayv1.GatewayConditionProgrammed), Status: programmed, Reason: "Test", Message: "no addresses"},
+				},
+			},
+		}
+	}
+	configObjs := func(programmed metav1.ConditionStatus) []client.Object {
+		return []client.Object{
+			&gatewayv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: gatewayClassName,
+				},
+			},
+			&egv1a1.EnvoyProxy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      gatewayName,
+					Namespace: defaultGatewayNamespace,
+				},
+			},
+			gateway(programmed),
+		}
+	}
+
+	testCases := []struct {
+		desc     string
+		ts       testSetup
+		expected *Status
+	}{
+		{
+			desc: "should report missing resources",
+			expected: &Status{
+				Reasons: []string{
+					"HelmRelease/bar/foo.gateway not found",
+					"GatewayClass/envoy-gateway not found",
+					"EnvoyProxy/openmcp-system/default not found",
+					"Gateway/openmcp-system/default not found",
+				},
+			},
+		},
+		{
+			desc: "should report unready resources",
+			ts: testSetup{
+				platformInitObjs: []client.Object{helmRelease(metav1.ConditionFalse)},
+				clusterInitObjs:  configObjs(metav1.ConditionFalse),
+			},
+			expected: &Status{
+				Configured: true,
+				Reasons: []string{
+					"HelmRelease/bar/foo.gateway is not Ready: install in progress",
+					"Gateway/openmcp-system/default is not Programmed: no addresses",
+				},
+			},
+		},
+		{
+			desc: "should report ready gateway",
+			ts: testSetup{
+				platformInitObjs: []client.Object{helmRelease(metav1.ConditionTrue)},
+				clusterInitObjs:  configObjs(metav1.ConditionTrue),
+			},
+			expected: &Status{
+				Installed:  true,
+				Configured: true,
+				Ready:      true,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, _, g := tC.ts.build()
+
+			status, err := g.Status(t.Context())
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, status)
+			}
+		})
+	}
+}
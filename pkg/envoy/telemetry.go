@@ -0,0 +1,149 @@
+package envoy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"k8s.io/utils/ptr"
+)
+
+// defaultAccessLogJSONFields are logged if the JSON access log format is used without explicit fields.
+// The gateway passes TLS through, therefore only connection-level command operators are used.
+var defaultAccessLogJSONFields = map[string]string{
+	"start_time":                "%START_TIME%",
+	"downstream_remote_address": "%DOWNSTREAM_REMOTE_ADDRESS%",
+	"requested_server_name":     "%REQUESTED_SERVER_NAME%",
+	"upstream_host":             "%UPSTREAM_HOST%",
+	"bytes_received":            "%BYTES_RECEIVED%",
+	"bytes_sent":                "%BYTES_SENT%",
+	"duration":                  "%DURATION%",
+	"response_flags":            "%RESPONSE_FLAGS%",
+}
+
+// getProxyTelemetry returns the telemetry configuration of the EnvoyProxy.
+// It returns nil if no telemetry is configured, so that the Envoy Gateway defaults apply.
+func (g *Gateway) getProxyTelemetry() (*egv1a1.ProxyTelemetry, error) {
+	proxy := g.EnvoyConfig.Proxy
+	if proxy == nil || (proxy.AccessLog == nil && proxy.Metrics == nil) {
+		return nil, nil
+	}
+
+	telemetry := &egv1a1.ProxyTelemetry{}
+	if proxy.AccessLog != nil {
+		accessLog, err := g.getProxyAccessLog()
+		if err != nil {
+			return nil, err
+		}
+		telemetry.AccessLog = accessLog
+	}
+	if proxy.Metrics != nil {
+		metrics, err := g.getProxyMetrics()
+		if err != nil {
+			return nil, err
+		}
+		telemetry.Metrics = metrics
+	}
+	return telemetry, nil
+}
+
+func (g *Gateway) getProxyMetrics() (*egv1a1.ProxyMetrics, error) {
+	cfg := g.EnvoyConfig.Proxy.Metrics
+	metrics := &egv1a1.ProxyMetrics{}
+
+	if prom := cfg.Prometheus; prom != nil {
+		metrics.Prometheus = &egv1a1.ProxyPrometheusProvider{
+			Disable: prom.Disabled,
+		}
+		if prom.Compression != "" {
+			metrics.Prometheus.Compression = &egv1a1.Compression{
+				Type: prom.Compression,
+			}
+		}
+	}
+
+	if cfg.OpenTelemetryEndpoint != "" {
+		host, port, err := splitEndpoint(cfg.OpenTelemetryEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metrics endpoint %q: %w", cfg.OpenTelemetryEndpoint, err)
+		}
+		metrics.Sinks = []egv1a1.ProxyMetricSink{
+			{
+				Type: egv1a1.MetricSinkTypeOpenTelemetry,
+				OpenTelemetry: &egv1a1.ProxyOpenTelemetrySink{
+					Host: &host,
+					Port: port,
+				},
+			},
+		}
+	}
+
+	return metrics, nil
+}
+
+func (g *Gateway) getProxyAccessLog() (*egv1a1.ProxyAccessLog, error) {
+	cfg := g.EnvoyConfig.Proxy.AccessLog
+
+	format := &egv1a1.ProxyAccessLogFormat{
+		Type: ptr.To(egv1a1.ProxyAccessLogFormatTypeText),
+	}
+	if cfg.Format == egv1a1.ProxyAccessLogFormatTypeJSON {
+		format.Type = ptr.To(egv1a1.ProxyAccessLogFormatTypeJSON)
+		format.JSON = cfg.JSONFields
+		if len(format.JSON) == 0 {
+			format.JSON = defaultAccessLogJSONFields
+		}
+	}
+
+	sinks := make([]egv1a1.ProxyAccessLogSink, len(cfg.Sinks))
+	for i, sink := range cfg.Sinks {
+		switch sink.Type {
+		case egv1a1.ProxyAccessLogSinkTypeOpenTelemetry:
+			host, port, err := splitEndpoint(sink.Endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid access log endpoint %q: %w", sink.Endpoint, err)
+			}
+			sinks[i] = egv1a1.ProxyAccessLogSink{
+				Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry,
+				OpenTelemetry: &egv1a1.OpenTelemetryEnvoyProxyAccessLog{
+					Host: &host,
+					Port: port,
+				},
+			}
+		default:
+			path := sink.Path
+			if path == "" {
+				path = "/dev/stdout"
+			}
+			sinks[i] = egv1a1.ProxyAccessLogSink{
+				Type: egv1a1.ProxyAccessLogSinkTypeFile,
+				File: &egv1a1.FileEnvoyProxyAccessLog{
+					Path: path,
+				},
+			}
+		}
+	}
+
+	return &egv1a1.ProxyAccessLog{
+		Settings: []egv1a1.ProxyAccessLogSetting{
+			{
+				Format: format,
+				Sinks:  sinks,
+			},
+		},
+	}, nil
+}
+
+// splitEndpoint splits an endpoint in the form "host:port" into host and port.
+func splitEndpoint(endpoint string) (string, int32, error) {
+	host, portStr, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, int32(port), nil
+}
@@ -0,0 +1,180 @@
+package envoy
+
+import (
+	"testing"
+
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	"github.com/openmcp-project/platform-service-gateway/api/gateway/v1alpha1"
+)
+
+func Test_Gateway_getProxyTelemetry_accessLog(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		accessLog   *v1alpha1.AccessLogConfig
+		expected    *egv1a1.ProxyTelemetry
+		expectedErr bool
+	}{
+		{
+			desc: "should not render telemetry when access log is unset",
+		},
+		{
+			desc: "should render text access log to stdout",
+			accessLog: &v1alpha1.AccessLogConfig{
+				Sinks: []v1alpha1.AccessLogSink{
+					{Type: egv1a1.ProxyAccessLogSinkTypeFile},
+				},
+			},
+			expected: &egv1a1.ProxyTelemetry{
+				AccessLog: &egv1a1.ProxyAccessLog{
+					Settings: []egv1a1.ProxyAccessLogSetting{
+						{
+							Format: &egv1a1.ProxyAccessLogFormat{
+								Type: ptr.To(egv1a1.ProxyAccessLogFormatTypeText),
+							},
+							Sinks: []egv1a1.ProxyAccessLogSink{
+								{
+									Type: egv1a1.ProxyAccessLogSinkTypeFile,
+									File: &egv1a1.FileEnvoyProxyAccessLog{Path: "/dev/stdout"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "should render JSON access log with default fields to OTLP",
+			accessLog: &v1alpha1.AccessLogConfig{
+				Format: egv1a1.ProxyAccessLogFormatTypeJSON,
+				Sinks: []v1alpha1.AccessLogSink{
+					{Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry, Endpoint: "otel-collector.monitoring:4317"},
+				},
+			},
+			expected: &egv1a1.ProxyTelemetry{
+				AccessLog: &egv1a1.ProxyAccessLog{
+					Settings: []egv1a1.ProxyAccessLogSetting{
+						{
+							Format: &egv1a1.ProxyAccessLogFormat{
+								Type: ptr.To(egv1a1.ProxyAccessLogFormatTypeJSON),
+								JSON: defaultAccessLogJSONFields,
+							},
+							Sinks: []egv1a1.ProxyAccessLogSink{
+								{
+									Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry,
+									OpenTelemetry: &egv1a1.OpenTelemetryEnvoyProxyAccessLog{
+										Host: ptr.To("otel-collector.monitoring"),
+										Port: 4317,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "should fail on invalid OTLP endpoint",
+			accessLog: &v1alpha1.AccessLogConfig{
+				Sinks: []v1alpha1.AccessLogSink{
+					{Type: egv1a1.ProxyAccessLogSinkTypeOpenTelemetry, Endpoint: "otel-collector"},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: &v1alpha1.ProxyConfig{
+						AccessLog: tC.accessLog,
+					},
+				},
+			}
+			telemetry, err := g.getProxyTelemetry()
+			if tC.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, telemetry)
+			}
+		})
+	}
+}
+
+func Test_Gateway_getProxyTelemetry_metrics(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		metrics     *v1alpha1.ProxyMetricsConfig
+		expected    *egv1a1.ProxyTelemetry
+		expectedErr bool
+	}{
+		{
+			desc: "should not render telemetry when metrics are unset",
+		},
+		{
+			desc: "should render disabled Prometheus endpoint",
+			metrics: &v1alpha1.ProxyMetricsConfig{
+				Prometheus: &v1alpha1.PrometheusMetricsConfig{Disabled: true},
+			},
+			expected: &egv1a1.ProxyTelemetry{
+				Metrics: &egv1a1.ProxyMetrics{
+					Prometheus: &egv1a1.ProxyPrometheusProvider{Disable: true},
+				},
+			},
+		},
+		{
+			desc: "should render Prometheus compression and OTLP sink",
+			metrics: &v1alpha1.ProxyMetricsConfig{
+				Prometheus:            &v1alpha1.PrometheusMetricsConfig{Compression: egv1a1.GzipCompressorType},
+				OpenTelemetryEndpoint: "otel-collector.monitoring:4317",
+			},
+			expected: &egv1a1.ProxyTelemetry{
+				Metrics: &egv1a1.ProxyMetrics{
+					Prometheus: &egv1a1.ProxyPrometheusProvider{
+						Compression: &egv1a1.Compression{Type: egv1a1.GzipCompressorType},
+					},
+					Sinks: []egv1a1.ProxyMetricSink{
+						{
+							Type: egv1a1.MetricSinkTypeOpenTelemetry,
+							OpenTelemetry: &egv1a1.ProxyOpenTelemetrySink{
+								Host: ptr.To("otel-collector.monitoring"),
+								Port: 4317,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "should fail on invalid OTLP endpoint",
+			metrics: &v1alpha1.ProxyMetricsConfig{
+				OpenTelemetryEndpoint: "otel-collector",
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			g := &Gateway{
+				EnvoyConfig: v1alpha1.EnvoyGatewayConfig{
+					Proxy: &v1alpha1.ProxyConfig{
+						Metrics: tC.metrics,
+					},
+				},
+			}
+			telemetry, err := g.getProxyTelemetry()
+			if tC.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tC.expected, telemetry)
+			}
+		})
+	}
+}
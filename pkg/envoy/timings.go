@@ -0,0 +1,52 @@
+package envoy
+
+import (
+	"cmp"
+	"time"
+)
+
+const (
+	// defaultCRDRetryInterval is the interval in which Configure is retried while CRDs are missing.
+	defaultCRDRetryInterval = 10 * time.Second
+	// defaultGatewayClassRetryInterval is the interval in which Configure is retried while the GatewayClass is not accepted.
+	defaultGatewayClassRetryInterval = 5 * time.Second
+	// defaultCertificateRetryInterval is the interval in which Configure is retried while referenced certificates are missing.
+	defaultCertificateRetryInterval = 10 * time.Second
+	// defaultNamespaceRetryInterval is the interval in which the gateway is reconciled again while namespaces which are not created are missing.
+	defaultNamespaceRetryInterval = 30 * time.Second
+)
+
+// Timings are the intervals after which the Gateway asks to be reconciled again while waiting for resources.
+// Zero values fall back to the defaults, so tests can shorten individual intervals.
+type Timings struct {
+	// CRDRetryInterval is used while the Gateway API or Envoy Gateway CRDs are missing.
+	CRDRetryInterval time.Duration
+	// GatewayClassRetryInterval is used while the GatewayClass is not accepted or being recreated.
+	GatewayClassRetryInterval time.Duration
+	// CertificateRetryInterval is used while Secrets referenced by the hostname certificates of a listener are missing.
+	CertificateRetryInterval time.Duration
+	// NamespaceRetryInterval is used while namespaces are missing which are not created by the controller.
+	NamespaceRetryInterval time.Duration
+	// DeletionRetryInterval is used while objects are being deleted, unless the cleanup config sets a retry interval.
+	DeletionRetryInterval time.Duration
+}
+
+func (t Timings) getCRDRetryInterval() time.Duration {
+	return cmp.Or(t.CRDRetryInterval, defaultCRDRetryInterval)
+}
+
+func (t Timings) getGatewayClassRetryInterval() time.Duration {
+	return cmp.Or(t.GatewayClassRetryInterval, defaultGatewayClassRetryInterval)
+}
+
+func (t Timings) getDeletionRetryInterval() time.Duration {
+	return cmp.Or(t.DeletionRetryInterval, defaultDeletionRetryInterval)
+}
+
+func (t Timings) getCertificateRetryInterval() time.Duration {
+	return cmp.Or(t.CertificateRetryInterval, defaultCertificateRetryInterval)
+}
+
+func (t Timings) getNamespaceRetryInterval() time.Duration {
+	return cmp.Or(t.NamespaceRetryInterval, defaultNamespaceRetryInterval)
+}
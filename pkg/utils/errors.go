@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -45,6 +46,36 @@ func (*RetryableError) Is(target error) bool {
 	return ok
 }
 
+// ----- Error classification -----
+
+// transientErrorRequeueAfter is the delay after which a transient API error is retried.
+const transientErrorRequeueAfter = 5 * time.Second
+
+// ClassifyError wraps known-transient API errors (conflicts, timeouts, throttling) into a RetryableError.
+// If the API server suggests a delay, it is used as RequeueAfter.
+// Errors which are already retryable, nil errors and all other errors are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil || errors.Is(err, &RetryableError{}) {
+		return err
+	}
+	if !IsTransientError(err) {
+		return err
+	}
+	requeueAfter := transientErrorRequeueAfter
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok && seconds > 0 {
+		requeueAfter = time.Duration(seconds) * time.Second
+	}
+	return NewRetryableError(err, requeueAfter)
+}
+
+// IsTransientError checks if the given error is an API error which is expected to resolve itself when retried.
+func IsTransientError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err)
+}
+
 // ----- RemainingResourcesError -----
 
 // NewRemainingResourcesError creates a new RemainingResourcesError wrapped in a RetryableError.
@@ -66,14 +97,29 @@ type RemainingResourcesError struct {
 }
 
 // RemainingResourcesError implements error.
+// Objects which are already marked for deletion are listed with the time since their deletion timestamp.
 func (r *RemainingResourcesError) Error() string {
 	ids := make([]string, len(r.Objects))
 	for i, obj := range r.Objects {
 		ids[i] = ObjectIdentifier(obj)
+		if dt := obj.GetDeletionTimestamp(); dt != nil {
+			ids[i] = fmt.Sprintf("%s (deleting for %s)", ids[i], time.Since(dt.Time).Round(time.Second))
+		}
 	}
 	return fmt.Sprintf("deletion of the following resources is still pending: [%s]", strings.Join(ids, ", "))
 }
 
+// ObjectIdentifiers returns the sorted identifiers of the remaining objects.
+// In contrast to Error, the result doesn't change while the same objects remain.
+func (r *RemainingResourcesError) ObjectIdentifiers() []string {
+	ids := make([]string, len(r.Objects))
+	for i, obj := range r.Objects {
+		ids[i] = ObjectIdentifier(obj)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
 func (*RemainingResourcesError) Is(target error) bool {
 	_, ok := target.(*RemainingResourcesError)
 	return ok
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -33,6 +35,85 @@ func TestRetryableError(t *testing.T) {
 	}
 }
 
+func TestClassifyError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "gateways"}
+	testCases := []struct {
+		desc                 string
+		err                  error
+		expectedRetryable    bool
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			desc: "nil error",
+			err:  nil,
+		},
+		{
+			desc: "generic error",
+			err:  errors.New("example error"),
+		},
+		{
+			desc: "not found error",
+			err:  apierrors.NewNotFound(gr, "foo"),
+		},
+		{
+			desc: "forbidden error",
+			err:  apierrors.NewForbidden(gr, "foo", errors.New("denied")),
+		},
+		{
+			desc:                 "conflict error",
+			err:                  apierrors.NewConflict(gr, "foo", errors.New("object has been modified")),
+			expectedRetryable:    true,
+			expectedRequeueAfter: transientErrorRequeueAfter,
+		},
+		{
+			desc:                 "wrapped conflict error",
+			err:                  fmt.Errorf("error updating gateway: %w", apierrors.NewConflict(gr, "foo", errors.New("object has been modified"))),
+			expectedRetryable:    true,
+			expectedRequeueAfter: transientErrorRequeueAfter,
+		},
+		{
+			desc:                 "server timeout error",
+			err:                  apierrors.NewServerTimeout(gr, "get", 7),
+			expectedRetryable:    true,
+			expectedRequeueAfter: 7 * time.Second,
+		},
+		{
+			desc:                 "timeout error",
+			err:                  apierrors.NewTimeoutError("request timed out", 0),
+			expectedRetryable:    true,
+			expectedRequeueAfter: transientErrorRequeueAfter,
+		},
+		{
+			desc:                 "too many requests error",
+			err:                  apierrors.NewTooManyRequests("slow down", 3),
+			expectedRetryable:    true,
+			expectedRequeueAfter: 3 * time.Second,
+		},
+		{
+			desc:                 "retryable error is kept",
+			err:                  NewRetryableError(apierrors.NewConflict(gr, "foo", errors.New("object has been modified")), time.Minute),
+			expectedRetryable:    true,
+			expectedRequeueAfter: time.Minute,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := ClassifyError(tC.err)
+
+			re := &RetryableError{}
+			if !tC.expectedRetryable {
+				assert.Equal(t, tC.err, err)
+				assert.False(t, errors.As(err, &re), "error must not be retryable")
+				return
+			}
+			if assert.True(t, errors.As(err, &re), "error must be retryable") {
+				assert.Equal(t, tC.expectedRequeueAfter, re.RequeueAfter)
+				assert.True(t, errors.Is(err, tC.err) || errors.Is(tC.err, &RetryableError{}), "original error must be wrapped")
+			}
+		})
+	}
+}
+
 func TestRemainingResourcesError(t *testing.T) {
 	objs := []client.Object{
 		&corev1.Namespace{
@@ -65,6 +146,32 @@ func TestRemainingResourcesError(t *testing.T) {
 	}
 }
 
+func TestRemainingResourcesError_deletionAge(t *testing.T) {
+	objs := []client.Object{
+		&corev1.Namespace{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "example",
+				DeletionTimestamp: ptr.To(v1.NewTime(time.Now().Add(-time.Hour))),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "example",
+			},
+		},
+	}
+	err := NewRemainingResourcesError(time.Minute, objs...)
+
+	assert.EqualError(t, err, "deletion of the following resources is still pending: [Namespace/example (deleting for 1h0m0s), Secret/example/foo]")
+
+	rr := &RemainingResourcesError{}
+	if assert.True(t, errors.As(err, &rr)) {
+		// the identifiers don't contain the deletion age
+		assert.Equal(t, []string{"Namespace/example", "Secret/example/foo"}, rr.ObjectIdentifiers())
+	}
+}
+
 func TestIsCRDNotFoundError(t *testing.T) {
 	testCases := []struct {
 		desc     string